@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	util "chast.io/core/pkg/util/fs/file"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// applyCmd represents the apply command.
+var applyCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "apply <planFile>",
+	Short: "Run a plan created by \"chast plan\" and apply its changes",
+	Long: `Run a plan created by "chast plan" and apply its changes after confirmation.
+The plan is refused if the recipe or the input changed since it was created.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file, newFileError := util.NewFile(args[0])
+		if newFileError != nil || !file.Exists() {
+			log.Fatalf("Plan file \"%v\" does not exist.", file.AbsolutePath)
+		}
+
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+
+		refactoring.Apply(file, assumeYes, runOptions(cmd))
+	},
+}
+
+func init() { //nolint:gochecknoinits // This is the way cobra wants it.
+	rootCmd.AddCommand(applyCmd)
+
+	addRunFlags(applyCmd)
+	addRunSelectionFlags(applyCmd)
+	applyCmd.Flags().BoolP("yes", "y", false, "Apply the changes without asking for confirmation")
+}
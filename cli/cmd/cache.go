@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command.
+var cacheCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "cache",
+	Short: "Manage the cache of step changes",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.HelpFunc()(cmd, args)
+	},
+}
+
+// cacheStatsCmd represents the cache stats command.
+var cacheStatsCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "stats",
+	Short: "Show the number and size of the cached steps",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		refactoring.CacheStats(workspaceOptions(cmd))
+	},
+}
+
+// cachePruneCmd represents the cache prune command.
+var cachePruneCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "prune",
+	Short: "Remove the least recently used steps from the cache",
+	Long: `Remove the least recently used steps until the cache is at most --max-size MiB large,
+and all steps which were not used for longer than --max-age.
+Without flags, the whole cache is removed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		maxSize, _ := cmd.Flags().GetUint64("max-size")
+		maxAge, _ := cmd.Flags().GetDuration("max-age")
+
+		refactoring.PruneCache(workspaceOptions(cmd), maxSize, maxAge)
+	},
+}
+
+func init() { //nolint:gochecknoinits // This is the way cobra wants it.
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	cachePruneCmd.Flags().Uint64("max-size", 0, "Maximum size of the cache in MiB")
+	cachePruneCmd.Flags().Duration("max-age", 0, "Maximum time since a cached step was used, e.g. 720h")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	"github.com/spf13/cobra"
+)
+
+// logsCmd represents the logs command.
+var logsCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "logs <pipelineUuid> [step]",
+	Short: "Show the output of the steps of a pipeline",
+	Long: `Show the output the commands of the steps of a pipeline wrote, with timestamps and the boundaries of the
+commands. The step is selected by its uuid or the id of its run, all steps are shown if no step is given.
+Pipelines left in the workspace are listed with "chast pipelines list".`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		step := ""
+		if len(args) > 1 {
+			step = args[1]
+		}
+
+		refactoring.Logs(args[0], step, workspaceOptions(cmd))
+	},
+}
+
+func init() { //nolint:gochecknoinits // This is the way cobra wants it.
+	rootCmd.AddCommand(logsCmd)
+}
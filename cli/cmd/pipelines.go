@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	"github.com/spf13/cobra"
+)
+
+// pipelinesCmd represents the pipelines command.
+var pipelinesCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "pipelines",
+	Short: "Manage the pipelines left in the workspace",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.HelpFunc()(cmd, args)
+	},
+}
+
+// pipelinesListCmd represents the pipelines list command.
+var pipelinesListCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "list",
+	Short: "List the pipelines in the workspace with their status",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		refactoring.ListPipelines(workspaceOptions(cmd))
+	},
+}
+
+// pipelinesRmCmd represents the pipelines rm command.
+var pipelinesRmCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "rm [pipelineUuid...]",
+	Short: "Remove pipelines and their changes from the workspace",
+	Long: `Remove pipelines and their changes from the workspace.
+Pipelines in use by another invocation are never removed.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			return cobra.NoArgs(cmd, args)
+		}
+
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+
+		refactoring.RemovePipelines(args, all, workspaceOptions(cmd))
+	},
+}
+
+func init() { //nolint:gochecknoinits // This is the way cobra wants it.
+	rootCmd.AddCommand(pipelinesCmd)
+	pipelinesCmd.AddCommand(pipelinesListCmd)
+	pipelinesCmd.AddCommand(pipelinesRmCmd)
+
+	pipelinesRmCmd.Flags().Bool("all", false, "Remove all pipelines which are not in use")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	util "chast.io/core/pkg/util/fs/file"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command.
+var planCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "plan <chastConfigFile>",
+	Short: "Resolve a refactoring recipe into a plan without running it",
+	Long: `Resolve a refactoring recipe and its arguments into a plan containing the commands of each step.
+The plan can be run later or on another machine with "chast apply <planFile>".
+It refuses to run if the recipe or the input changed in the meantime.`,
+	Args: cobra.MatchAll(cobra.MinimumNArgs(1)),
+	Run: func(cmd *cobra.Command, args []string) {
+		recipeFileArg := args[0]
+
+		file, newFileError := util.NewFile(recipeFileArg)
+		if newFileError != nil || !file.Exists() {
+			log.Fatalf("Recipe file \"%v\" does not exist.", file.AbsolutePath)
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+
+		refactoring.Plan(file, output, runSelection(cmd), args[1:]...)
+	},
+}
+
+func init() { //nolint:gochecknoinits // This is the way cobra wants it.
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringP("output", "o", "",
+		"File to write the plan to, required to apply it in another checkout (default is stdout)")
+	addRunSelectionFlags(planCmd)
+}
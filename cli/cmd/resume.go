@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	"github.com/spf13/cobra"
+)
+
+// resumeCmd represents the resume command.
+var resumeCmd = &cobra.Command{ //nolint:exhaustruct // Only defining required fields
+	Use:   "resume <pipelineUuid>",
+	Short: "Resume a failed pipeline from its last completed steps",
+	Long: `Resume a failed pipeline. Only the failed steps, the steps depending on them and the steps which did not run
+yet are run again, the changes of all completed steps are reused.
+The pipeline is refused if the recipe or the input changed since it was started.
+Pipelines left in the workspace are listed with "chast pipelines list".`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+
+		refactoring.Resume(args[0], assumeYes, runOptions(cmd))
+	},
+}
+
+func init() { //nolint:gochecknoinits // This is the way cobra wants it.
+	rootCmd.AddCommand(resumeCmd)
+
+	addRunFlags(resumeCmd)
+	resumeCmd.Flags().BoolP("yes", "y", false, "Apply the changes without asking for confirmation")
+}
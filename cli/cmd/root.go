@@ -29,7 +29,8 @@ Run refactorings and other commands through an unified system no matter which op
 Required tools: 
 - unionfs-fuse (Linux only, for Apple see MacOS support section in their README)
 - user namespace support required
-- (For OverlayFs-MergerFs-Isolation-Strategy: OverlayFs, Fuse, MergerFs required)`,
+- (For OverlayFs-MergerFs-Isolation-Strategy: OverlayFs, Fuse, MergerFs required)
+- (Without these, --no-isolation runs the steps in a scratch copy of the project instead)`,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -49,6 +50,7 @@ func init() { //nolint:gochecknoinits // This is the way cobra wants it.
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.cmd.yaml)")
+	addWorkspaceFlags(rootCmd)
 
 	// Cobra also supports local flags, which will only refactoring
 	// when this action is called directly.
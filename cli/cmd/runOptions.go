@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	"github.com/spf13/cobra"
+)
+
+func addRunFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-cache", false, "Run every step, even if its changes are cached from a run with the same inputs")
+	cmd.Flags().Uint("event-fd", 0, "Write the events of the pipeline as newline delimited JSON to this open file descriptor")
+	cmd.Flags().Bool("progress", false, "Render the progress of the steps on stderr")
+	cmd.Flags().Bool("timings", false, "Print the time spent in each phase and the resources used by each step")
+	cmd.Flags().UintP("jobs", "j", 1, "Maximum number of independent steps running at the same time")
+	cmd.Flags().Bool("no-isolation", false,
+		"Run the commands in a scratch copy of the project instead of an isolated environment")
+}
+
+func runOptions(cmd *cobra.Command) refactoring.RunOptions {
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	eventFileDescriptor, _ := cmd.Flags().GetUint("event-fd")
+	progress, _ := cmd.Flags().GetBool("progress")
+	timings, _ := cmd.Flags().GetBool("timings")
+	jobs, _ := cmd.Flags().GetUint("jobs")
+	noIsolation, _ := cmd.Flags().GetBool("no-isolation")
+
+	return refactoring.RunOptions{
+		Workspace:           workspaceOptions(cmd),
+		Selection:           runSelection(cmd),
+		NoCache:             noCache,
+		EventFileDescriptor: uintptr(eventFileDescriptor),
+		Progress:            progress,
+		Timings:             timings,
+		Jobs:                jobs,
+		NoIsolation:         noIsolation,
+	}
+}
@@ -21,12 +21,14 @@ The available flags and parameters are available by calling it with the --help f
 		if newFileError != nil || !file.Exists() {
 			log.Fatalf("Recipe file \"%v\" does not exist.", file.AbsolutePath)
 		}
-		refactoring.Run(file, args[1:]...)
+		refactoring.Run(file, runOptions(cmd), args[1:]...)
 	},
 }
 
 func init() { //nolint:gochecknoinits // This is the way cobra wants it.
 	runCmd.AddCommand(runRefactoringCmd)
+	addRunFlags(runRefactoringCmd)
+	addRunSelectionFlags(runRefactoringCmd)
 
 	defaultHelpFunction := runRefactoringCmd.HelpFunc()
 	runRefactoringCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) { runRefactoringHelpFunction(cmd, args, defaultHelpFunction) })
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	"github.com/spf13/cobra"
+)
+
+func addRunSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("only", nil, "Run only the runs with these ids and the runs they depend on")
+	cmd.Flags().String("until", "", "Stop after the run with this id, the changes of all steps are kept for inspection")
+	cmd.Flags().StringSlice("skip", nil, "Skip the runs with these ids")
+}
+
+func runSelection(cmd *cobra.Command) refactoring.RunSelection {
+	only, _ := cmd.Flags().GetStringSlice("only")
+	until, _ := cmd.Flags().GetString("until")
+	skip, _ := cmd.Flags().GetStringSlice("skip")
+
+	return refactoring.RunSelection{
+		Only:  only,
+		Until: until,
+		Skip:  skip,
+	}
+}
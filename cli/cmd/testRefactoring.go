@@ -14,7 +14,9 @@ var testRefactoringCmd = &cobra.Command{
 	Short: "Test a refactoring recipe",
 	Long: `This command tests a refactoring recipe based on the test section in the recipe itself.
 The parameters and "input" files are passed as arguments and flags respectively.
-The output is then compared against the expected output files in the "expected" folder.`,
+The output is then compared against the expected output files in the "expected" folder.
+With --update, the "expected" folder of each test is rewritten with the actual output instead.
+Tests can be selected by their id with --run and run concurrently with --parallel.`,
 	Args: cobra.MatchAll(cobra.MinimumNArgs(1)),
 	Run: func(cmd *cobra.Command, args []string) {
 		recipeFileArg := args[0]
@@ -23,13 +25,31 @@ The output is then compared against the expected output files in the "expected"
 		if newFileError != nil || !file.Exists() {
 			log.Fatalf("Recipe file \"%v\" does not exist.", file.AbsolutePath)
 		}
-		refactoring.Test(file, args[1:]...)
+
+		updateSnapshots, _ := cmd.Flags().GetBool("update")
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		runPattern, _ := cmd.Flags().GetString("run")
+		failFast, _ := cmd.Flags().GetBool("fail-fast")
+
+		refactoring.Test(file, refactoring.TestOptions{
+			UpdateSnapshots: updateSnapshots,
+			Parallel:        parallel,
+			Run:             runPattern,
+			FailFast:        failFast,
+			Workspace:       workspaceOptions(cmd),
+		})
 	},
 }
 
 func init() { //nolint:gochecknoinits // This is the way cobra wants it.
 	testCmd.AddCommand(testRefactoringCmd)
 
+	testRefactoringCmd.Flags().BoolP("update", "u", false,
+		"Rewrite the expected output of each test with the actual output instead of comparing them")
+	testRefactoringCmd.Flags().IntP("parallel", "p", 1, "Maximum number of tests running at the same time")
+	testRefactoringCmd.Flags().String("run", "", "Only run tests whose id matches the regular expression")
+	testRefactoringCmd.Flags().Bool("fail-fast", false, "Do not start further tests after the first failed test")
+
 	defaultHelpFunction := testRefactoringCmd.HelpFunc()
 	testRefactoringCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) { testRefactoringHelpFunction(cmd, args, defaultHelpFunction) })
 }
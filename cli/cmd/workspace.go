@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"chast.io/core/pkg/api/refactoring"
+	"github.com/spf13/cobra"
+)
+
+func addWorkspaceFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("workspace", "",
+		"Base folder of the pipeline locations (default is chast in $XDG_CACHE_HOME or ~/.cache)")
+	cmd.PersistentFlags().String("operation-dir", "", "Folder the steps operate in (default is <workspace>/operation)")
+	cmd.PersistentFlags().String("changes-dir", "", "Folder the changes are captured in (default is <workspace>/changes)")
+	cmd.PersistentFlags().String("root-dir", "", "Root file system the steps are isolated from (default is /)")
+	cmd.PersistentFlags().String("cache-dir", "", "Folder the changes of steps are cached in (default is <workspace>/cache)")
+	cmd.PersistentFlags().Uint64("min-free-space", 0,
+		"MiB required to be free in the workspace before a pipeline starts (default is 256)")
+}
+
+func workspaceOptions(cmd *cobra.Command) refactoring.WorkspaceOptions {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	operationLocation, _ := cmd.Flags().GetString("operation-dir")
+	changeCaptureLocation, _ := cmd.Flags().GetString("changes-dir")
+	rootFileSystemLocation, _ := cmd.Flags().GetString("root-dir")
+	cacheLocation, _ := cmd.Flags().GetString("cache-dir")
+	minimumFreeSpace, _ := cmd.Flags().GetUint64("min-free-space")
+
+	return refactoring.WorkspaceOptions{
+		Workspace:              workspace,
+		OperationLocation:      operationLocation,
+		ChangeCaptureLocation:  changeCaptureLocation,
+		RootFileSystemLocation: rootFileSystemLocation,
+		CacheLocation:          cacheLocation,
+		MinimumFreeSpace:       minimumFreeSpace,
+	}
+}
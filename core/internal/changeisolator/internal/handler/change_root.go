@@ -45,6 +45,16 @@ func (crh *ChangeRootHandler) Open() error {
 	chastlog.Log.Tracef("Successfully changed root to %s", crh.RootFsPath)
 	chastlog.Log.Tracef("Trying to change working directory to %s", crh.WorkingDirectory)
 
+	workingDirectoryInfo, statError := os.Stat(crh.WorkingDirectory)
+	if statError != nil {
+		return errorx.DataUnavailable.Wrap(statError,
+			"Working directory %s does not exist in the isolated environment", crh.WorkingDirectory)
+	}
+
+	if !workingDirectoryInfo.IsDir() {
+		return errorx.IllegalArgument.New("Working directory %s is not a directory", crh.WorkingDirectory)
+	}
+
 	if err := unix.Chdir(crh.WorkingDirectory); err != nil {
 		return errorx.ExternalError.Wrap(err, "Failed to change working directory")
 	}
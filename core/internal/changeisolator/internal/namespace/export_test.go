@@ -0,0 +1,40 @@
+package namespace
+
+import "io"
+
+// NewPrefixWriter returns a writer prefixing every line, which has to be flushed with FlushOutput.
+func NewPrefixWriter(writer io.Writer, prefix string) io.Writer {
+	return newPrefixWriter(writer, prefix)
+}
+
+// FlushOutput writes the last lines of the outputs which are prefixed.
+func FlushOutput(outputs ...io.Writer) {
+	flushOutput(outputs...)
+}
+
+// ScratchTree exposes the copy of the scratch runner.
+type ScratchTree struct {
+	tree *scratchTree
+}
+
+func NewScratchTree(source string, location string) *ScratchTree {
+	return &ScratchTree{tree: newScratchTree(source, location)}
+}
+
+func (tree *ScratchTree) Prepare(mergeFolders []string) error {
+	return tree.tree.prepare(mergeFolders)
+}
+
+// CopyPath returns the path within the copy.
+func (tree *ScratchTree) CopyPath(path string) string {
+	return tree.tree.copyPath(path)
+}
+
+func (tree *ScratchTree) CaptureChanges(changeCaptureFolder string) error {
+	return tree.tree.captureChanges(changeCaptureFolder)
+}
+
+// RedirectPaths replaces the source folder with the target folder in the paths within the value.
+func RedirectPaths(source string, target string, value string) string {
+	return newPathRedirect(source, target).apply(value)
+}
@@ -2,15 +2,20 @@ package namespace
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"syscall"
+	"time"
 
 	"chast.io/core/internal/changeisolator/internal/strategie"
 	"chast.io/core/internal/changeisolator/pkg/namespace"
+	chastlog "chast.io/core/internal/logger"
 	"chast.io/core/pkg/util/fs/folder"
 	"github.com/containers/storage/pkg/reexec"
 	"github.com/joomcode/errorx"
@@ -19,12 +24,14 @@ import (
 type UserNamespaceRunnerContext struct {
 	nsContext *namespace.Context
 	isolator  strategie.Isolator
+	metrics   namespace.RunMetrics
 }
 
 func New(nsContext *namespace.Context) *UserNamespaceRunnerContext {
 	return &UserNamespaceRunnerContext{
 		nsContext: nsContext,
 		isolator:  nil,
+		metrics:   namespace.RunMetrics{}, //nolint:exhaustruct // recorded while running
 	}
 }
 
@@ -43,46 +50,227 @@ func (nsrc *UserNamespaceRunnerContext) Initialize() error {
 	return nil
 }
 
-func (nsrc *UserNamespaceRunnerContext) Run() error {
+// Run runs the commands in the namespace until they are done or the context is done and returns their results.
+// It fails if a command failed, unless the run continues on errors. The work outside the namespace is always cleaned
+// up once it was prepared, even if the process failed or was stopped.
+// The metrics of the run are available afterwards, even if it failed.
+func (nsrc *UserNamespaceRunnerContext) Run(ctx context.Context) ([]namespace.CommandResult, error) {
 	isolator := nsrc.isolator
+	startTime := time.Now()
 
 	if err := isolator.Initialize(); err != nil {
-		return errorx.InternalError.Wrap(err, "Error initializing isolator")
+		return nil, errorx.InternalError.Wrap(err, "Error initializing isolator")
 	}
 
 	if err := isolator.PrepareOutsideNS(); err != nil {
-		return errorx.InternalError.Wrap(err, "Error running preparation work outside namespace")
+		return nil, errorx.InternalError.Wrap(err, "Error running preparation work outside namespace")
 	}
 
-	if err := nsrc.launchProcess(); err != nil {
-		return errorx.InternalError.Wrap(err, "Error launching process")
+	nsrc.metrics.IsolationSetup = time.Since(startTime)
+
+	report, launchError := nsrc.launchProcess(ctx)
+	results := report.Results
+
+	cleanupError := isolator.CleanupOutsideNS()
+	nsrc.recordReport(report, time.Since(startTime))
+
+	if cleanupError != nil {
+		if launchError != nil {
+			chastlog.Log.Errorf("Error running cleanup work outside namespace: %v", cleanupError)
+		} else {
+			return results, errorx.InternalError.Wrap(cleanupError, "Error running cleanup work outside namespace")
+		}
 	}
 
-	if err := isolator.CleanupOutsideNS(); err != nil {
-		return errorx.InternalError.Wrap(err, "Error running cleanup work outside namespace")
+	if launchError != nil {
+		return results, errorx.InternalError.Wrap(launchError, "Error launching process")
 	}
 
-	return nil
+	if failure := namespace.FirstFailure(results); failure != nil && !nsrc.nsContext.ContinueOnError {
+		return results, errorx.ExternalError.Wrap(&namespace.CommandError{Result: *failure}, "Command failed")
+	}
+
+	return results, nil
 }
 
-func (nsrc *UserNamespaceRunnerContext) launchProcess() error {
-	cmd, setupCommandErr := nsrc.setupCommand()
+// Metrics returns the metrics of the last run.
+func (nsrc *UserNamespaceRunnerContext) Metrics() namespace.RunMetrics {
+	return nsrc.metrics
+}
+
+// recordReport splits the duration of the run into the setup of the isolation, the commands and the remaining time,
+// which is mostly spent cleaning up the isolation.
+func (nsrc *UserNamespaceRunnerContext) recordReport(report runReport, runDuration time.Duration) {
+	nsrc.metrics.IsolationSetup += report.IsolationSetup
+
+	for _, result := range report.Results {
+		nsrc.metrics.Commands += result.Duration
+	}
+
+	if cleanup := runDuration - nsrc.metrics.IsolationSetup - nsrc.metrics.Commands; cleanup > 0 {
+		nsrc.metrics.IsolationCleanup = cleanup
+	}
+}
+
+// terminationGracePeriod is the time the processes of the namespace get to clean up before they are killed.
+const terminationGracePeriod = 10 * time.Second
+
+// outputDrainPeriod is the time pipes of a process are still read after it exited. Processes it left running may keep
+// the pipes open, so reading stops after this period instead of waiting for the end of the pipes.
+const outputDrainPeriod = 500 * time.Millisecond
+
+const logFilePermission = 0o644
+
+func (nsrc *UserNamespaceRunnerContext) launchProcess(ctx context.Context) (runReport, error) {
+	cmd, resultReader, setupCommandErr := nsrc.setupCommand()
 	if setupCommandErr != nil {
-		return setupCommandErr
+		return emptyRunReport(), setupCommandErr
+	}
+
+	defer func() { _ = resultReader.Close() }()
+
+	stdout, stderr := cmd.Stdout, cmd.Stderr
+
+	outputs, pipeError := pipeOutput(cmd)
+	if pipeError != nil {
+		return emptyRunReport(), pipeError
 	}
 
-	if err := cmd.Start(); err != nil {
-		return errorx.ExternalError.New("error starting the reexec.Command - %s", err)
+	startError := cmd.Start()
+	outputs.started()
+
+	if startError != nil {
+		outputs.finish()
+
+		return emptyRunReport(), errorx.ExternalError.New("error starting the reexec.Command - %s", startError)
 	}
 
-	if err := cmd.Wait(); err != nil {
-		return errorx.ExternalError.New("error waiting for the reexec.Command - %s", err)
+	// only the process in the namespace writes results, so reading ends once it exits
+	for _, extraFile := range cmd.ExtraFiles {
+		_ = extraFile.Close()
 	}
 
-	return nil
+	reportRead := make(chan runReportRead, 1)
+
+	go func() {
+		report, readError := readRunReport(resultReader)
+		reportRead <- runReportRead{report: report, err: readError}
+	}()
+
+	processDone := make(chan struct{})
+	stopperDone := make(chan struct{})
+
+	go func() {
+		defer close(stopperDone)
+
+		select {
+		case <-ctx.Done():
+			stopProcessGroup(cmd.Process.Pid, processDone)
+		case <-processDone:
+		}
+	}()
+
+	waitError := cmd.Wait()
+
+	close(processDone)
+	<-stopperDone
+
+	outputs.finish()
+	flushOutput(stdout, stderr)
+
+	if usage, isRusage := cmd.ProcessState.SysUsage().(*syscall.Rusage); isRusage {
+		nsrc.metrics.AddResourceUsage(usage)
+	}
+
+	// the report is complete once the process exited, processes it left running must not block reading it
+	_ = resultReader.SetReadDeadline(time.Now().Add(outputDrainPeriod))
+	read := <-reportRead
+
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return read.report, errorx.TimeoutElapsed.Wrap(ctx.Err(), "Process in namespace timed out")
+		}
+
+		return read.report, errorx.Interrupted.Wrap(ctx.Err(), "Process in namespace was cancelled")
+	}
+
+	if waitError != nil {
+		return read.report, errorx.ExternalError.Wrap(waitError, "error waiting for the reexec.Command")
+	}
+
+	if read.err != nil {
+		return emptyRunReport(), read.err
+	}
+
+	return read.report, nil
+}
+
+// runReport is sent by the process in the namespace to the process outside of it.
+type runReport struct {
+	Results []namespace.CommandResult `json:"results"`
+	// IsolationSetup is the time spent preparing the isolation inside the namespace.
+	IsolationSetup time.Duration `json:"isolationSetup"`
+}
+
+func emptyRunReport() runReport {
+	return runReport{Results: make([]namespace.CommandResult, 0), IsolationSetup: 0}
+}
+
+// flushOutput writes the last lines of the outputs which are prefixed.
+func flushOutput(outputs ...io.Writer) {
+	for _, output := range outputs {
+		if writer, isPrefixWriter := output.(*prefixWriter); isPrefixWriter {
+			writer.flush()
+		}
+	}
+}
+
+type runReportRead struct {
+	report runReport
+	err    error
+}
+
+func readRunReport(resultReader io.Reader) (runReport, error) {
+	report := emptyRunReport()
+
+	data, readError := io.ReadAll(resultReader)
+	if readError != nil && !errors.Is(readError, os.ErrDeadlineExceeded) {
+		return report, errorx.ExternalError.Wrap(readError, "Error reading command results")
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return report, nil
+	}
+
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, errorx.IllegalFormat.Wrap(err, "Error decoding command results")
+	}
+
+	return report, nil
+}
+
+// stopProcessGroup asks all processes of the group to terminate, so the namespace can clean up, and kills them if
+// they are not done after the grace period.
+func stopProcessGroup(processGroupID int, processDone <-chan struct{}) {
+	chastlog.Log.Printf("Stopping processes in namespace")
+
+	if err := syscall.Kill(-processGroupID, syscall.SIGTERM); err != nil {
+		chastlog.Log.Debugf("Failed to terminate process group %d: %v", processGroupID, err)
+	}
+
+	select {
+	case <-processDone:
+	case <-time.After(terminationGracePeriod):
+		chastlog.Log.Warnf("Processes in namespace did not terminate within %s, killing them", terminationGracePeriod)
+
+		if err := syscall.Kill(-processGroupID, syscall.SIGKILL); err != nil {
+			chastlog.Log.Debugf("Failed to kill process group %d: %v", processGroupID, err)
+		}
+	}
 }
 
-func (nsrc *UserNamespaceRunnerContext) setupCommand() (*exec.Cmd, error) {
+// setupCommand prepares the command running in the namespace and returns the pipe it sends the command results to.
+func (nsrc *UserNamespaceRunnerContext) setupCommand() (*exec.Cmd, *os.File, error) {
 	nsContext := nsrc.nsContext
 
 	cmd := reexec.Command(processExecutionFunction)
@@ -90,9 +278,15 @@ func (nsrc *UserNamespaceRunnerContext) setupCommand() (*exec.Cmd, error) {
 	// https://github.com/containers/buildah/blob/main/run_common.go#L1097
 	// setPdeathsig(cmd)
 
-	cmd.Stdin = os.Stdin
+	// the namespace runs in its own process group, which can not read from the terminal
+	cmd.Stdin = nil
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+
+	if nsContext.OutputPrefix != "" {
+		cmd.Stdout = newPrefixWriter(os.Stdout, nsContext.OutputPrefix)
+		cmd.Stderr = newPrefixWriter(os.Stderr, nsContext.OutputPrefix)
+	}
 	cmd.SysProcAttr = buildSysProcAttr(false)
 
 	// https://github.com/containers/buildah/blob/main/run_common.go#L1126
@@ -100,12 +294,47 @@ func (nsrc *UserNamespaceRunnerContext) setupCommand() (*exec.Cmd, error) {
 
 	namespaceContextFile, err := buildNamespaceContextFile(nsContext)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	resultReader, resultWriter, pipeError := os.Pipe()
+	if pipeError != nil {
+		_ = namespaceContextFile.Close()
+
+		return nil, nil, errorx.ExternalError.Wrap(pipeError, "Error creating command result pipe")
 	}
 
-	cmd.ExtraFiles = append([]*os.File{namespaceContextFile}, cmd.ExtraFiles...)
+	// the order determines the file descriptors in the namespace
+	cmd.ExtraFiles = append([]*os.File{namespaceContextFile, resultWriter}, cmd.ExtraFiles...)
+
+	if nsContext.LogFile != "" {
+		logFile, logFileError := openLogFile(nsContext.LogFile)
+		if logFileError != nil {
+			_ = namespaceContextFile.Close()
+			_ = resultReader.Close()
+			_ = resultWriter.Close()
+
+			return nil, nil, logFileError
+		}
+
+		cmd.ExtraFiles = append(cmd.ExtraFiles, logFile)
+	}
+
+	return cmd, resultReader, nil
+}
+
+// openLogFile opens the log file outside the namespace, as its location may not be accessible inside of it.
+func openLogFile(location string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(location), os.ModePerm); err != nil {
+		return nil, errorx.ExternalError.Wrap(err, "Error creating folder of log file %s", location)
+	}
+
+	logFile, openError := os.OpenFile(location, os.O_CREATE|os.O_APPEND|os.O_WRONLY, logFilePermission)
+	if openError != nil {
+		return nil, errorx.ExternalError.Wrap(openError, "Error opening log file %s", location)
+	}
 
-	return cmd, nil
+	return logFile, nil
 }
 
 func buildSysProcAttr(networkCapabilitiesRequired bool) *syscall.SysProcAttr {
@@ -135,13 +364,15 @@ func buildSysProcAttr(networkCapabilitiesRequired bool) *syscall.SysProcAttr {
 		UidMappings: userToRootUIDMappings,
 		GidMappings: userGroupToRootGroupMappings,
 		Cloneflags:  cloneFlags,
+		// all processes of the namespace can be stopped together
+		Setpgid: true,
 	}
 }
 
 func buildNamespaceContextFile(nsContext *namespace.Context) (*os.File, error) {
 	encodedNsContext, marshalingErr := json.Marshal(nsContext)
 	if marshalingErr != nil {
-		return nil, fmt.Errorf("encoding configuration for %q: %w", nsContext, marshalingErr)
+		return nil, fmt.Errorf("encoding configuration for %v: %w", nsContext, marshalingErr)
 	}
 
 	pipeReader, pipeWriter, pipeError := os.Pipe()
@@ -0,0 +1,118 @@
+package namespace
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/joomcode/errorx"
+)
+
+// outputPipes connects the outputs of a command which are not files through pipes of its own. Unlike the pipes of
+// os/exec, which are read until all processes holding them closed them, they are only read until shortly after the
+// command exited, so processes it left running do not block.
+type outputPipes struct {
+	readers []*os.File
+	writers []*os.File
+	copied  sync.WaitGroup
+}
+
+// pipeOutput replaces the outputs of the command which are not files with pipes copying to them.
+func pipeOutput(cmd *exec.Cmd) (*outputPipes, error) {
+	pipes := &outputPipes{
+		readers: make([]*os.File, 0),
+		writers: make([]*os.File, 0),
+		copied:  sync.WaitGroup{},
+	}
+
+	stdout, stdoutError := pipes.connect(cmd.Stdout)
+	if stdoutError != nil {
+		return nil, stdoutError
+	}
+
+	stderr, stderrError := pipes.connect(cmd.Stderr)
+	if stderrError != nil {
+		pipes.started()
+		pipes.finish()
+
+		return nil, stderrError
+	}
+
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return pipes, nil
+}
+
+func (pipes *outputPipes) connect(output io.Writer) (io.Writer, error) {
+	if output == nil {
+		return nil, nil
+	}
+
+	if _, isFile := output.(*os.File); isFile {
+		return output, nil
+	}
+
+	reader, writer, pipeError := os.Pipe()
+	if pipeError != nil {
+		return nil, errorx.ExternalError.Wrap(pipeError, "Error creating output pipe")
+	}
+
+	pipes.readers = append(pipes.readers, reader)
+	pipes.writers = append(pipes.writers, writer)
+	pipes.copied.Add(1)
+
+	go func() {
+		defer pipes.copied.Done()
+
+		_, _ = io.Copy(output, reader)
+	}()
+
+	return writer, nil
+}
+
+// started closes the write ends of the pipes once the command holds them.
+func (pipes *outputPipes) started() {
+	for _, writer := range pipes.writers {
+		_ = writer.Close()
+	}
+}
+
+// finish reads the output until the pipes end or the drain period after the command exited passed, and closes them.
+func (pipes *outputPipes) finish() {
+	deadline := time.Now().Add(outputDrainPeriod)
+
+	for _, reader := range pipes.readers {
+		_ = reader.SetReadDeadline(deadline)
+	}
+
+	pipes.copied.Wait()
+
+	for _, reader := range pipes.readers {
+		_ = reader.Close()
+	}
+}
+
+// startAndWait starts the command and waits for it with wait. Its output is read until shortly after it exited.
+func startAndWait(cmd *exec.Cmd, wait func(cmd *exec.Cmd) error) error {
+	pipes, pipeError := pipeOutput(cmd)
+	if pipeError != nil {
+		return pipeError
+	}
+
+	startError := cmd.Start()
+	pipes.started()
+
+	if startError != nil {
+		pipes.finish()
+
+		return startError //nolint:wrapcheck // described by the command result
+	}
+
+	waitError := wait(cmd)
+	pipes.finish()
+
+	return waitError
+}
@@ -0,0 +1,76 @@
+package namespace
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/joomcode/errorx"
+)
+
+// outputLock keeps the lines of the namespaces running in parallel from being interleaved.
+var outputLock sync.Mutex //nolint:gochecknoglobals // shared by all namespaces writing to the same output
+
+// prefixWriter prefixes every line written to it, which has to be flushed after the last write.
+type prefixWriter struct {
+	writer  io.Writer
+	prefix  []byte
+	pending []byte
+}
+
+func newPrefixWriter(writer io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{
+		writer:  writer,
+		prefix:  []byte(prefix),
+		pending: make([]byte, 0),
+	}
+}
+
+func (writer *prefixWriter) Write(data []byte) (int, error) {
+	writer.pending = append(writer.pending, data...)
+
+	lastLineEnd := bytes.LastIndexByte(writer.pending, '\n')
+	if lastLineEnd < 0 {
+		return len(data), nil
+	}
+
+	if err := writer.writeLines(writer.pending[:lastLineEnd+1]); err != nil {
+		return 0, err
+	}
+
+	writer.pending = writer.pending[lastLineEnd+1:]
+
+	return len(data), nil
+}
+
+// flush writes the last line if it was not terminated.
+func (writer *prefixWriter) flush() {
+	if len(writer.pending) == 0 {
+		return
+	}
+
+	_ = writer.writeLines(append(writer.pending, '\n'))
+	writer.pending = writer.pending[:0]
+}
+
+func (writer *prefixWriter) writeLines(lines []byte) error {
+	var prefixed bytes.Buffer
+
+	for _, line := range bytes.SplitAfter(lines, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+
+		prefixed.Write(writer.prefix)
+		prefixed.Write(line)
+	}
+
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	if _, err := writer.writer.Write(prefixed.Bytes()); err != nil {
+		return errorx.ExternalError.Wrap(err, "Error writing output of namespace")
+	}
+
+	return nil
+}
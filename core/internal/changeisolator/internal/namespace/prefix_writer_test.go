@@ -0,0 +1,112 @@
+package namespace_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	uut "chast.io/core/internal/changeisolator/internal/namespace"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		writes   []string
+		expected string
+	}{
+		{
+			name:     "should prefix every line",
+			writes:   []string{"first\nsecond\n"},
+			expected: "[a] first\n[a] second\n",
+		},
+		{
+			name:     "should join partial lines",
+			writes:   []string{"fir", "st\nsec", "ond", "\n"},
+			expected: "[a] first\n[a] second\n",
+		},
+		{
+			name:     "should terminate the last line on flush",
+			writes:   []string{"first\nunterminated"},
+			expected: "[a] first\n[a] unterminated\n",
+		},
+		{
+			name:     "should keep empty lines",
+			writes:   []string{"first\n\nthird\n"},
+			expected: "[a] first\n[a] \n[a] third\n",
+		},
+		{
+			name:     "should write nothing without output",
+			writes:   []string{},
+			expected: "",
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var output bytes.Buffer
+
+			writer := uut.NewPrefixWriter(&output, "[a] ")
+
+			for _, data := range testCase.writes {
+				if written, err := writer.Write([]byte(data)); err != nil || written != len(data) {
+					t.Fatalf("Expected %d bytes to be written, but was %d with error '%v'", len(data), written, err)
+				}
+			}
+
+			uut.FlushOutput(writer)
+			uut.FlushOutput(writer)
+
+			if output.String() != testCase.expected {
+				t.Errorf("Expected output to be '%q', but was '%q'", testCase.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestPrefixWriter_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const lines = 200
+
+	var output bytes.Buffer
+
+	var waitGroup sync.WaitGroup
+
+	for _, prefix := range []string{"[a] ", "[b] "} {
+		writer := uut.NewPrefixWriter(&output, prefix)
+
+		waitGroup.Add(1)
+
+		go func(writer io.Writer, prefix string) {
+			defer waitGroup.Done()
+
+			for index := 0; index < lines; index++ {
+				// every line is written in two parts, which must not be separated by lines of the other writer
+				_, _ = writer.Write([]byte(fmt.Sprintf("line %d of ", index)))
+				_, _ = writer.Write([]byte(prefix + "\n"))
+			}
+		}(writer, prefix)
+	}
+
+	waitGroup.Wait()
+
+	outputLines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	if len(outputLines) != 2*lines {
+		t.Fatalf("Expected %d lines, but was %d", 2*lines, len(outputLines))
+	}
+
+	for _, line := range outputLines {
+		prefix := line[:4]
+		if !strings.HasSuffix(line, "of "+prefix) {
+			t.Errorf("Expected line of writer '%s' not to be interleaved, but was '%s'", prefix, line)
+		}
+	}
+}
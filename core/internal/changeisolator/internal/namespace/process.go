@@ -1,11 +1,16 @@
 package namespace
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"chast.io/core/internal/changeisolator/pkg/namespace"
 	chastlog "chast.io/core/internal/logger"
@@ -24,6 +29,12 @@ func init() { //nolint:gochecknoinits // This function needs to register the fun
 const processExecutionFunction = "nsExecution"
 
 func nsExecution() {
+	// the isolation and the commands must not inherit the files shared with the process outside the namespace,
+	// processes they leave running would keep the result pipe open
+	for fileDescriptor := firstExtraFileFileDescriptorNumber; fileDescriptor <= logFileDescriptorNumber; fileDescriptor++ {
+		syscall.CloseOnExec(fileDescriptor)
+	}
+
 	chastlog.Log.Printf("Running in isolated environment")
 
 	nsContext := loadNamespaceContext()
@@ -33,18 +44,31 @@ func nsExecution() {
 		chastlog.Log.Fatalf("Cannot load isolation strategy: %v", isolationStrategyBuildError)
 	}
 
+	setupStartTime := time.Now()
+
 	if err := isolator.PrepareInsideNS(); err != nil {
 		chastlog.Log.Fatalf("Error in preparing isolation - %s", err)
 	}
 
-	nsRun(nsContext)
+	isolationSetup := time.Since(setupStartTime)
+
+	// the whole process group is terminated on cancellation, the isolation is cleaned up before exiting
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	results := nsRun(ctx, nsContext)
+	stop()
+
+	writeRunReport(runReport{Results: results, IsolationSetup: isolationSetup})
 
 	if err := isolator.CleanupInsideNS(); err != nil {
 		chastlog.Log.Fatalf("Error in cleaning up isolation - %s", err)
 	}
 }
 
-const firstExtraFileFileDescriptorNumber = 3
+const (
+	firstExtraFileFileDescriptorNumber = 3
+	resultFileDescriptorNumber         = firstExtraFileFileDescriptorNumber + 1
+	logFileDescriptorNumber            = firstExtraFileFileDescriptorNumber + 2
+)
 
 func loadNamespaceContext() namespace.Context {
 	nsContext := *namespace.NewEmptyContext()
@@ -63,23 +87,125 @@ func loadNamespaceContext() namespace.Context {
 	return nsContext
 }
 
-func nsRun(nsContext namespace.Context) {
+// nsRun runs the commands until one of them fails, unless the run continues on errors, and returns their results.
+func nsRun(ctx context.Context, nsContext namespace.Context) []namespace.CommandResult {
+	results := make([]namespace.CommandResult, 0, len(nsContext.Commands))
+
+	log := openStepLog(&nsContext)
+	if log != nil {
+		defer log.close()
+	}
+
 	for _, command := range nsContext.Commands {
+		if ctx.Err() != nil {
+			chastlog.Log.Warnf("Skipping remaining commands: %v", ctx.Err())
+
+			break
+		}
+
 		commandString := strings.Join(command, " ")
 		chastlog.Log.Debugf("Running command \"%s\" in isolated environment", chalk.Blue.Color(commandString))
 
-		cmd := exec.Command("/bin/bash", "-c", commandString) // TODO make runner configurable
+		cmd := exec.Command(command[0], command[1:]...) //nolint:gosec // running the scripts of the recipe is intended
 
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 
-		cmd.Env = []string{"PS1=-[chast-ns-process]- # "}
+		cmd.Env = append([]string{"PS1=-[chast-ns-process]- # "}, nsContext.Environment...)
 
-		if err := cmd.Run(); err != nil {
-			chastlog.Log.Warnf("Error running command: %v", err)
-		}
+		result := runCommand(cmd, command, log, &nsContext, (*exec.Cmd).Wait)
+		results = append(results, result)
 
 		chastlog.Log.Debugf("Running command done!")
+
+		if result.Failed {
+			chastlog.Log.Warnf("Command \"%s\" failed with exit code %d: %s", commandString, result.ExitCode, result.Error)
+
+			if !nsContext.ContinueOnError {
+				break
+			}
+		}
+	}
+
+	return results
+}
+
+// runCommand starts the command, waits for it with wait and tees its output into the log of the step, if there is one.
+func runCommand(
+	cmd *exec.Cmd,
+	command []string,
+	log *stepLog,
+	nsContext *namespace.Context,
+	wait func(cmd *exec.Cmd) error,
+) namespace.CommandResult {
+	if log == nil {
+		startTime := time.Now()
+		result := commandResult(command, startAndWait(cmd, wait), nsContext)
+		result.Duration = time.Since(startTime)
+
+		return result
+	}
+
+	stdout := log.stream("out")
+	stderr := log.stream("err")
+	cmd.Stdout = io.MultiWriter(cmd.Stdout, stdout)
+	cmd.Stderr = io.MultiWriter(cmd.Stderr, stderr)
+
+	log.commandStarted(command)
+	startTime := time.Now()
+
+	result := commandResult(command, startAndWait(cmd, wait), nsContext)
+	result.Duration = time.Since(startTime)
+
+	stdout.flush()
+	stderr.flush()
+	log.commandFinished(result)
+
+	return result
+}
+
+// openStepLog returns the log the output of the commands is written to, or nil if the output is not logged.
+func openStepLog(nsContext *namespace.Context) *stepLog {
+	if nsContext.LogFile == "" {
+		return nil
+	}
+
+	return newStepLog(os.NewFile(uintptr(logFileDescriptorNumber), nsContext.LogFile))
+}
+
+func commandResult(command []string, runError error, nsContext *namespace.Context) namespace.CommandResult {
+	result := namespace.CommandResult{
+		Command:  command,
+		ExitCode: 0,
+		Error:    "",
+		Failed:   false,
+	}
+
+	if runError == nil {
+		return result
+	}
+
+	result.Error = runError.Error()
+	// commands which could not be started or were killed by a signal have no exit code
+	result.ExitCode = -1
+
+	var exitError *exec.ExitError
+	if errors.As(runError, &exitError) {
+		result.ExitCode = exitError.ExitCode()
+	}
+
+	result.Failed = result.ExitCode < 0 || !nsContext.IsAllowedExitCode(result.ExitCode)
+
+	return result
+}
+
+// writeRunReport sends the results of the commands to the process outside the namespace.
+func writeRunReport(report runReport) {
+	pipe := os.NewFile(uintptr(resultFileDescriptorNumber), "results")
+	defer func() { _ = pipe.Close() }()
+
+	if err := json.NewEncoder(pipe).Encode(report); err != nil {
+		chastlog.Log.Errorf("Error while sending command results: %v", err)
 	}
 }
@@ -0,0 +1,258 @@
+package namespace
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"chast.io/core/internal/changeisolator/pkg/namespace"
+	pathreplace "chast.io/core/internal/internal_util/path_replace"
+	chastlog "chast.io/core/internal/logger"
+	"chast.io/core/pkg/util/fs/folder"
+	"github.com/joomcode/errorx"
+	"github.com/ttacon/chalk"
+)
+
+const scratchFolderName = "scratch"
+
+// ScratchCopyRunnerContext runs the commands without namespaces in a scratch copy of the root folder. The changes are
+// captured by comparing the copy before and after the commands and written in the layout unionfs captures them in.
+// Paths within the root folder are redirected to the copy in the commands, the working directory and the
+// environment, the commands must not change files outside of it.
+type ScratchCopyRunnerContext struct {
+	nsContext *namespace.Context
+	metrics   namespace.RunMetrics
+}
+
+func NewScratchCopy(nsContext *namespace.Context) *ScratchCopyRunnerContext {
+	return &ScratchCopyRunnerContext{
+		nsContext: nsContext,
+		metrics:   namespace.RunMetrics{}, //nolint:exhaustruct // recorded while running
+	}
+}
+
+func (scrc *ScratchCopyRunnerContext) Initialize() error {
+	if !folder.DoesFolderExist(scrc.nsContext.RootFolder) {
+		return errorx.DataUnavailable.New("Root folder %s does not exist", scrc.nsContext.RootFolder)
+	}
+
+	return nil
+}
+
+// Run copies the root folder together with the changes of the merge folders, runs the commands in the copy until they
+// are done or the context is done and returns their results. It fails if a command failed, unless the run continues
+// on errors. The changes are captured even if a command failed, the copy is always removed.
+// The metrics of the run are available afterwards, even if it failed.
+func (scrc *ScratchCopyRunnerContext) Run(ctx context.Context) ([]namespace.CommandResult, error) {
+	nsContext := scrc.nsContext
+	startTime := time.Now()
+
+	tree := newScratchTree(nsContext.RootFolder, filepath.Join(nsContext.OperationDirectory, scratchFolderName))
+	defer tree.remove()
+
+	if err := tree.prepare(nsContext.MergeFolders); err != nil {
+		return nil, errorx.InternalError.Wrap(err, "Error preparing scratch copy")
+	}
+
+	scrc.metrics.IsolationSetup = time.Since(startTime)
+
+	results, runError := scrc.runCommands(ctx, tree)
+
+	for _, result := range results {
+		scrc.metrics.Commands += result.Duration
+	}
+
+	captureStartTime := time.Now()
+	captureError := tree.captureChanges(nsContext.ChangeCaptureFolder)
+	scrc.metrics.IsolationCleanup = time.Since(captureStartTime)
+
+	if runError != nil {
+		if captureError != nil {
+			chastlog.Log.Errorf("Error capturing changes of scratch copy: %v", captureError)
+		}
+
+		return results, runError
+	}
+
+	if captureError != nil {
+		return results, errorx.InternalError.Wrap(captureError, "Error capturing changes of scratch copy")
+	}
+
+	if failure := namespace.FirstFailure(results); failure != nil && !nsContext.ContinueOnError {
+		return results, errorx.ExternalError.Wrap(&namespace.CommandError{Result: *failure}, "Command failed")
+	}
+
+	return results, nil
+}
+
+// Metrics returns the metrics of the last run.
+func (scrc *ScratchCopyRunnerContext) Metrics() namespace.RunMetrics {
+	return scrc.metrics
+}
+
+// runCommands runs the commands in the copy until one of them fails, unless the run continues on errors, and returns
+// their results. It fails if the context is done before the commands are.
+func (scrc *ScratchCopyRunnerContext) runCommands(
+	ctx context.Context,
+	tree *scratchTree,
+) ([]namespace.CommandResult, error) {
+	nsContext := scrc.nsContext
+	results := make([]namespace.CommandResult, 0, len(nsContext.Commands))
+	redirect := newPathRedirect(tree.source, tree.copyPath(tree.source))
+
+	workingDirectory, workingDirectoryError := scratchWorkingDirectory(nsContext.WorkingDirectory, tree, redirect)
+	if workingDirectoryError != nil {
+		return results, workingDirectoryError
+	}
+
+	var log *stepLog
+
+	if nsContext.LogFile != "" {
+		logFile, logFileError := openLogFile(nsContext.LogFile)
+		if logFileError != nil {
+			return results, logFileError
+		}
+
+		log = newStepLog(logFile)
+		defer log.close()
+	}
+
+	environment := make([]string, 0, len(nsContext.Environment)+1)
+	environment = append(environment, "PS1=-[chast-scratch-process]- # ")
+
+	for _, variable := range nsContext.Environment {
+		environment = append(environment, redirect.apply(variable))
+	}
+
+	for _, command := range nsContext.Commands {
+		if ctx.Err() != nil {
+			break
+		}
+
+		commandString := strings.Join(command, " ")
+		chastlog.Log.Debugf("Running command \"%s\" in scratch copy", chalk.Blue.Color(commandString))
+
+		arguments := make([]string, 0, len(command))
+		for _, argument := range command {
+			arguments = append(arguments, redirect.apply(argument))
+		}
+
+		cmd := exec.Command(arguments[0], arguments[1:]...) //nolint:gosec // running the scripts of the recipe is intended
+		cmd.Dir = workingDirectory
+		cmd.Env = environment
+		stdout, stderr := scrc.outputWriters()
+		cmd.Stdin = nil
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		// all processes of the command can be stopped together
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true} //nolint:exhaustruct // only set the fields we need
+
+		result := runCommand(cmd, command, log, nsContext, func(cmd *exec.Cmd) error {
+			return scrc.waitUntilDone(ctx, cmd)
+		})
+		results = append(results, result)
+
+		flushOutput(stdout, stderr)
+
+		if result.Failed {
+			chastlog.Log.Warnf("Command \"%s\" failed with exit code %d: %s", commandString, result.ExitCode, result.Error)
+
+			if !nsContext.ContinueOnError {
+				break
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return results, errorx.TimeoutElapsed.Wrap(ctx.Err(), "Commands in scratch copy timed out")
+		}
+
+		return results, errorx.Interrupted.Wrap(ctx.Err(), "Commands in scratch copy were cancelled")
+	}
+
+	return results, nil
+}
+
+// scratchWorkingDirectory returns the working directory within the copy. A working directory outside of the copy is
+// used as it is.
+func scratchWorkingDirectory(workingDirectory string, tree *scratchTree, redirect *pathRedirect) (string, error) {
+	redirectedWorkingDirectory := redirect.apply(workingDirectory)
+	if redirectedWorkingDirectory == workingDirectory {
+		chastlog.Log.Warnf("Working directory %s is not within the scratch copy of %s", workingDirectory, tree.source)
+	}
+
+	workingDirectoryInfo, statError := os.Stat(redirectedWorkingDirectory)
+	if statError != nil {
+		return "", errorx.DataUnavailable.Wrap(statError, "Working directory %s does not exist", workingDirectory)
+	}
+
+	if !workingDirectoryInfo.IsDir() {
+		return "", errorx.IllegalArgument.New("Working directory %s is not a directory", workingDirectory)
+	}
+
+	return redirectedWorkingDirectory, nil
+}
+
+func (scrc *ScratchCopyRunnerContext) outputWriters() (io.Writer, io.Writer) {
+	if scrc.nsContext.OutputPrefix == "" {
+		return os.Stdout, os.Stderr
+	}
+
+	return newPrefixWriter(os.Stdout, scrc.nsContext.OutputPrefix), newPrefixWriter(os.Stderr, scrc.nsContext.OutputPrefix)
+}
+
+// waitUntilDone waits for the started command and stops its process group once the context is done.
+func (scrc *ScratchCopyRunnerContext) waitUntilDone(ctx context.Context, cmd *exec.Cmd) error {
+	processDone := make(chan struct{})
+	stopperDone := make(chan struct{})
+
+	go func() {
+		defer close(stopperDone)
+
+		select {
+		case <-ctx.Done():
+			stopProcessGroup(cmd.Process.Pid, processDone)
+		case <-processDone:
+		}
+	}()
+
+	waitError := cmd.Wait()
+
+	close(processDone)
+	<-stopperDone
+
+	if cmd.ProcessState == nil {
+		return waitError //nolint:wrapcheck // described by the command result
+	}
+
+	if usage, isRusage := cmd.ProcessState.SysUsage().(*syscall.Rusage); isRusage {
+		scrc.metrics.AddResourceUsage(usage)
+	}
+
+	return waitError //nolint:wrapcheck // described by the command result
+}
+
+// pathRedirect replaces a folder with another one in paths within strings.
+type pathRedirect struct {
+	source string
+	target string
+}
+
+func newPathRedirect(source string, target string) *pathRedirect {
+	return &pathRedirect{
+		source: source,
+		target: target,
+	}
+}
+
+// apply redirects the paths starting with the source, but not the ones only containing it, like "/other<source>".
+func (redirect *pathRedirect) apply(value string) string {
+	return pathreplace.ReplaceFolder(value, redirect.source, redirect.target)
+}
@@ -0,0 +1,98 @@
+package namespace_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	uut "chast.io/core/internal/changeisolator/internal/namespace"
+	"chast.io/core/internal/changeisolator/pkg/namespace"
+	"chast.io/core/internal/changeisolator/pkg/strategy"
+)
+
+// scratchContext returns the context of commands running in the scratch copy of a new project folder.
+func scratchContext(t *testing.T, logFile string, outputPrefix string, commands ...[]string) *namespace.Context {
+	t.Helper()
+
+	baseDir := t.TempDir()
+	projectFolder := filepath.Join(baseDir, "project")
+
+	if err := os.MkdirAll(projectFolder, 0o755); err != nil {
+		t.Fatalf("Error creating project folder: %v", err)
+	}
+
+	return namespace.NewContext(
+		projectFolder,
+		[]string{},
+		filepath.Join(baseDir, "changes"),
+		filepath.Join(baseDir, "operation"),
+		projectFolder,
+		commands,
+		[]string{},
+		[]int{},
+		false,
+		logFile,
+		outputPrefix,
+		strategy.UnionFS,
+	)
+}
+
+func TestScratchCopy_ProcessLeftRunning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		logged       bool
+		outputPrefix string
+	}{
+		{name: "should not wait for process holding the log", logged: true, outputPrefix: ""},
+		{name: "should not wait for process holding the prefixed output", logged: false, outputPrefix: "[step] "},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			logFile := ""
+			if testCase.logged {
+				logFile = filepath.Join(t.TempDir(), "step.log")
+			}
+
+			// the background process keeps the output open like a build daemon
+			nsContext := scratchContext(t, logFile, testCase.outputPrefix, []string{"sh", "-c", "sleep 20 & echo started"})
+			runner := uut.NewScratchCopy(nsContext)
+
+			if err := runner.Initialize(); err != nil {
+				t.Fatalf("Expected no error, but was '%v'", err)
+			}
+
+			startTime := time.Now()
+
+			results, runError := runner.Run(context.Background())
+			if runError != nil {
+				t.Fatalf("Expected no error, but was '%v'", runError)
+			}
+
+			if duration := time.Since(startTime); duration > 10*time.Second {
+				t.Errorf("Expected run to end once the command exited, but took %s", duration)
+			}
+
+			if len(results) != 1 || results[0].Failed {
+				t.Errorf("Expected 1 successful command, but was %+v", results)
+			}
+
+			if !testCase.logged {
+				return
+			}
+
+			content, readError := os.ReadFile(logFile)
+			if readError != nil || !strings.Contains(string(content), "out started") {
+				t.Errorf("Expected log to contain the output, but was '%s' (%v)", content, readError)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package namespace
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	chastlog "chast.io/core/internal/logger"
+	"chast.io/core/internal/post_processing/merger/pkg/mergeoptions"
+	"github.com/joomcode/errorx"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// ownerFolderPermission keeps the folders of the copy writable, so it can be changed and removed.
+	ownerFolderPermission = 0o700
+	compareBufferSize     = 64 * 1024
+)
+
+// scratchTree is a copy of a source folder, placed at the absolute path of the source within its location.
+type scratchTree struct {
+	source   string
+	location string
+	// snapshot is the state of the entries of the copy before the commands ran by their path relative to the copy.
+	snapshot map[string]entryState
+	// origins are the files the regular files of the copy were copied from by their path relative to the copy.
+	origins map[string]string
+}
+
+// entryState is what is compared to detect changes of an entry without reading its content.
+type entryState struct {
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
+	// linkTarget is the target of a symbolic link.
+	linkTarget string
+}
+
+func newScratchTree(source string, location string) *scratchTree {
+	return &scratchTree{
+		source:   source,
+		location: location,
+		snapshot: make(map[string]entryState),
+		origins:  make(map[string]string),
+	}
+}
+
+func (tree *scratchTree) copyPath(path string) string {
+	return filepath.Join(tree.location, path)
+}
+
+// prepare copies the source and applies the flattened changes of the merge folders in their order, deleting the
+// entries they mark as deleted. Changes outside of the source are not applied.
+func (tree *scratchTree) prepare(mergeFolders []string) error {
+	if err := os.RemoveAll(tree.location); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to remove scratch copy of previous attempt")
+	}
+
+	if err := tree.copyFolder(tree.source, false); err != nil {
+		return err
+	}
+
+	for _, mergeFolder := range mergeFolders {
+		changes := filepath.Join(mergeFolder, tree.source)
+		if _, err := os.Lstat(changes); os.IsNotExist(err) {
+			continue
+		}
+
+		if err := tree.copyFolder(changes, true); err != nil {
+			return err
+		}
+	}
+
+	return tree.takeSnapshot()
+}
+
+// copyFolder copies the folder into the copy of the source, replacing existing entries. If the folder contains
+// changes, the entries marked as deleted are removed from the copy.
+func (tree *scratchTree) copyFolder(folderLocation string, containsChanges bool) error {
+	copyRoot := tree.copyPath(tree.source)
+	deletedExtension := mergeoptions.NewMergeOptions().MetaFilesDeletedExtension
+
+	if walkError := filepath.WalkDir(folderLocation, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, relError := filepath.Rel(folderLocation, path)
+		if relError != nil {
+			return relError //nolint:wrapcheck // wrapped below
+		}
+
+		targetPath := filepath.Join(copyRoot, relativePath)
+
+		if containsChanges && !entry.IsDir() && strings.HasSuffix(path, deletedExtension) {
+			return os.RemoveAll(strings.TrimSuffix(targetPath, deletedExtension)) //nolint:wrapcheck // wrapped below
+		}
+
+		info, infoError := entry.Info()
+		if infoError != nil {
+			return infoError //nolint:wrapcheck // wrapped below
+		}
+
+		if existing, existingError := os.Lstat(targetPath); existingError == nil &&
+			(!existing.IsDir() || !entry.IsDir()) {
+			if err := os.RemoveAll(targetPath); err != nil {
+				return err //nolint:wrapcheck // wrapped below
+			}
+		}
+
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(targetPath, info.Mode().Perm()|ownerFolderPermission) //nolint:wrapcheck // wrapped below
+		case info.Mode()&fs.ModeSymlink != 0:
+			linkTarget, readLinkError := os.Readlink(path)
+			if readLinkError != nil {
+				return readLinkError //nolint:wrapcheck // wrapped below
+			}
+
+			return os.Symlink(linkTarget, targetPath) //nolint:wrapcheck // wrapped below
+		case info.Mode().IsRegular():
+			tree.origins[relativePath] = path
+
+			return cloneFile(path, targetPath, info.Mode().Perm())
+		default:
+			chastlog.Log.Debugf("Skipping special file %s in scratch copy", path)
+
+			return nil
+		}
+	}); walkError != nil {
+		return errorx.ExternalError.Wrap(walkError, "Failed to copy %s to scratch copy", folderLocation)
+	}
+
+	return nil
+}
+
+func (tree *scratchTree) takeSnapshot() error {
+	return tree.walkCopy(func(relativePath string, path string, state entryState) error {
+		tree.snapshot[relativePath] = state
+
+		return nil
+	})
+}
+
+// captureChanges writes the entries which were created or changed since the snapshot to the change capture folder
+// at their original path. Deleted entries are marked in the meta files folder, like unionfs does.
+func (tree *scratchTree) captureChanges(changeCaptureFolder string) error {
+	if err := os.MkdirAll(changeCaptureFolder, os.ModePerm); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to create change capture folder")
+	}
+
+	present := make(map[string]bool)
+
+	if err := tree.walkCopy(func(relativePath string, path string, state entryState) error {
+		present[relativePath] = true
+
+		changed, compareError := tree.isChanged(relativePath, path, state)
+		if compareError != nil || !changed {
+			return compareError
+		}
+
+		return captureEntry(path, filepath.Join(changeCaptureFolder, tree.source, relativePath), state)
+	}); err != nil {
+		return err
+	}
+
+	return tree.markDeletedEntries(changeCaptureFolder, present)
+}
+
+// markDeletedEntries marks the entries of the snapshot which are not present anymore as deleted. Only the topmost
+// deleted folder is marked, its content is deleted with it.
+func (tree *scratchTree) markDeletedEntries(changeCaptureFolder string, present map[string]bool) error {
+	options := mergeoptions.NewMergeOptions()
+
+	for relativePath := range tree.snapshot {
+		if present[relativePath] {
+			continue
+		}
+
+		parent := filepath.Dir(relativePath)
+		if _, parentExisted := tree.snapshot[parent]; parent != relativePath && parentExisted && !present[parent] {
+			continue
+		}
+
+		marker := filepath.Join(changeCaptureFolder, options.MetaFilesLocation, tree.source, relativePath) +
+			options.MetaFilesDeletedExtension
+
+		if err := os.MkdirAll(filepath.Dir(marker), os.ModePerm); err != nil {
+			return errorx.ExternalError.Wrap(err, "Failed to create folder of deletion marker %s", marker)
+		}
+
+		markerFile, createError := os.Create(marker)
+		if createError != nil {
+			return errorx.ExternalError.Wrap(createError, "Failed to create deletion marker %s", marker)
+		}
+
+		_ = markerFile.Close()
+	}
+
+	return nil
+}
+
+// isChanged compares the entry with its snapshot. The content of regular files is only compared if their
+// modification time changed.
+func (tree *scratchTree) isChanged(relativePath string, path string, state entryState) (bool, error) {
+	before, existed := tree.snapshot[relativePath]
+
+	switch {
+	case !existed || before.mode != state.mode:
+		return true, nil
+	case state.mode.IsDir():
+		return false, nil
+	case state.mode&fs.ModeSymlink != 0:
+		return before.linkTarget != state.linkTarget, nil
+	case state.size != before.size:
+		return true, nil
+	case state.modTime.Equal(before.modTime):
+		return false, nil
+	}
+
+	origin, hasOrigin := tree.origins[relativePath]
+	if !hasOrigin {
+		return true, nil
+	}
+
+	same, compareError := sameContent(origin, path)
+
+	return !same, compareError
+}
+
+// walkCopy calls visit for each entry of the copy with its path relative to the copy.
+func (tree *scratchTree) walkCopy(visit func(relativePath string, path string, state entryState) error) error {
+	copyRoot := tree.copyPath(tree.source)
+
+	if walkError := filepath.WalkDir(copyRoot, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, relError := filepath.Rel(copyRoot, path)
+		if relError != nil {
+			return relError //nolint:wrapcheck // wrapped below
+		}
+
+		state, stateError := readEntryState(path, entry)
+		if stateError != nil {
+			return stateError
+		}
+
+		return visit(relativePath, path, state)
+	}); walkError != nil {
+		return errorx.ExternalError.Wrap(walkError, "Failed to walk scratch copy of %s", tree.source)
+	}
+
+	return nil
+}
+
+func (tree *scratchTree) remove() {
+	if err := os.RemoveAll(tree.location); err != nil {
+		chastlog.Log.Errorf("Failed to remove scratch copy %s: %v", tree.location, err)
+	}
+}
+
+func readEntryState(path string, entry fs.DirEntry) (entryState, error) {
+	info, infoError := entry.Info()
+	if infoError != nil {
+		return entryState{}, infoError //nolint:exhaustruct,wrapcheck // wrapped by walkCopy
+	}
+
+	state := entryState{
+		mode:       info.Mode(),
+		size:       info.Size(),
+		modTime:    info.ModTime(),
+		linkTarget: "",
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		linkTarget, readLinkError := os.Readlink(path)
+		if readLinkError != nil {
+			return state, readLinkError //nolint:wrapcheck // wrapped by walkCopy
+		}
+
+		state.linkTarget = linkTarget
+	}
+
+	return state, nil
+}
+
+// captureEntry writes the entry to the change capture folder, special files are not captured.
+func captureEntry(path string, targetPath string, state entryState) error {
+	if state.mode.IsDir() {
+		return os.MkdirAll(targetPath, state.mode.Perm()|ownerFolderPermission) //nolint:wrapcheck // wrapped by walkCopy
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), os.ModePerm); err != nil {
+		return err //nolint:wrapcheck // wrapped by walkCopy
+	}
+
+	switch {
+	case state.mode&fs.ModeSymlink != 0:
+		return os.Symlink(state.linkTarget, targetPath) //nolint:wrapcheck // wrapped by walkCopy
+	case state.mode.IsRegular():
+		return cloneFile(path, targetPath, state.mode.Perm())
+	default:
+		chastlog.Log.Warnf("Not capturing special file %s", path)
+
+		return nil
+	}
+}
+
+// cloneFile shares the content of the file with the target if the file system supports it and copies it otherwise.
+func cloneFile(source string, target string, permission fs.FileMode) error {
+	sourceFile, openError := os.Open(source)
+	if openError != nil {
+		return openError //nolint:wrapcheck // wrapped by the caller
+	}
+
+	defer func() { _ = sourceFile.Close() }()
+
+	targetFile, createError := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, permission)
+	if createError != nil {
+		return createError //nolint:wrapcheck // wrapped by the caller
+	}
+
+	if cloneError := unix.IoctlFileClone(int(targetFile.Fd()), int(sourceFile.Fd())); cloneError != nil {
+		if _, copyError := io.Copy(targetFile, sourceFile); copyError != nil {
+			_ = targetFile.Close()
+
+			return copyError //nolint:wrapcheck // wrapped by the caller
+		}
+	}
+
+	return targetFile.Close() //nolint:wrapcheck // wrapped by the caller
+}
+
+func sameContent(first string, second string) (bool, error) {
+	firstFile, firstOpenError := os.Open(first)
+	if firstOpenError != nil {
+		return false, firstOpenError //nolint:wrapcheck // wrapped by walkCopy
+	}
+
+	defer func() { _ = firstFile.Close() }()
+
+	secondFile, secondOpenError := os.Open(second)
+	if secondOpenError != nil {
+		return false, secondOpenError //nolint:wrapcheck // wrapped by walkCopy
+	}
+
+	defer func() { _ = secondFile.Close() }()
+
+	firstBuffer := make([]byte, compareBufferSize)
+	secondBuffer := make([]byte, compareBufferSize)
+
+	for {
+		firstCount, firstReadError := io.ReadFull(firstFile, firstBuffer)
+		secondCount, secondReadError := io.ReadFull(secondFile, secondBuffer)
+
+		if !bytes.Equal(firstBuffer[:firstCount], secondBuffer[:secondCount]) {
+			return false, nil
+		}
+
+		firstDone := isEndOfFile(firstReadError)
+		secondDone := isEndOfFile(secondReadError)
+
+		switch {
+		case firstDone || secondDone:
+			return firstDone == secondDone, nil
+		case firstReadError != nil:
+			return false, firstReadError //nolint:wrapcheck // wrapped by walkCopy
+		case secondReadError != nil:
+			return false, secondReadError //nolint:wrapcheck // wrapped by walkCopy
+		}
+	}
+}
+
+func isEndOfFile(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
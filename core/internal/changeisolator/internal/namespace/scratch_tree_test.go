@@ -0,0 +1,447 @@
+package namespace_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	uut "chast.io/core/internal/changeisolator/internal/namespace"
+	"chast.io/core/internal/post_processing/merger/pkg/dirmerger"
+	"chast.io/core/internal/post_processing/merger/pkg/mergeoptions"
+)
+
+const (
+	folderEntry = "<folder>"
+	linkPrefix  = "-> "
+)
+
+// writeEntries creates the entries in the root. Folders are written as folderEntry, symbolic links as their target
+// prefixed with linkPrefix and files as their content.
+func writeEntries(t *testing.T, root string, entries map[string]string) {
+	t.Helper()
+
+	for path, content := range entries {
+		fullPath := filepath.Join(root, path)
+
+		if content == folderEntry {
+			if err := os.MkdirAll(fullPath, 0o755); err != nil {
+				t.Fatalf("Error creating folder: %v", err)
+			}
+
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("Error creating folder: %v", err)
+		}
+
+		if strings.HasPrefix(content, linkPrefix) {
+			if err := os.Symlink(strings.TrimPrefix(content, linkPrefix), fullPath); err != nil {
+				t.Fatalf("Error creating symlink: %v", err)
+			}
+
+			continue
+		}
+
+		if err := os.WriteFile(fullPath, []byte(content), 0o600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+}
+
+// readEntries returns the entries below the root in the representation of writeEntries, or no entries if the root
+// does not exist.
+func readEntries(t *testing.T, root string) map[string]string {
+	t.Helper()
+
+	entries := make(map[string]string)
+
+	if _, err := os.Lstat(root); os.IsNotExist(err) {
+		return entries
+	}
+
+	if walkError := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+
+		relativePath, _ := filepath.Rel(root, path)
+
+		switch {
+		case entry.IsDir():
+			entries[relativePath] = folderEntry
+		case entry.Type()&fs.ModeSymlink != 0:
+			linkTarget, _ := os.Readlink(path)
+			entries[relativePath] = linkPrefix + linkTarget
+		default:
+			content, _ := os.ReadFile(path)
+			entries[relativePath] = string(content)
+		}
+
+		return nil
+	}); walkError != nil {
+		t.Fatalf("Error reading %s: %v", root, walkError)
+	}
+
+	return entries
+}
+
+// prepareScratchTree copies a new source folder with the entries and returns the copy and the source.
+func prepareScratchTree(t *testing.T, entries map[string]string, mergeFolders ...string) (*uut.ScratchTree, string) {
+	t.Helper()
+
+	source := filepath.Join(t.TempDir(), "project")
+	writeEntries(t, source, entries)
+
+	tree := uut.NewScratchTree(source, filepath.Join(t.TempDir(), "scratch"))
+	if err := tree.Prepare(mergeFolders); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	return tree, source
+}
+
+func TestScratchTree_CaptureChanges(t *testing.T) {
+	t.Parallel()
+
+	deletedExtension := mergeoptions.NewMergeOptions().MetaFilesDeletedExtension
+
+	tests := []struct {
+		name            string
+		entries         map[string]string
+		change          func(t *testing.T, copyRoot string)
+		expectedChanges map[string]string
+		expectedMarkers map[string]string
+	}{
+		{
+			name:    "should capture created file",
+			entries: map[string]string{"src/A.java": "a"},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				writeEntries(t, copyRoot, map[string]string{"src/B.java": "b"})
+			},
+			expectedChanges: map[string]string{"src": folderEntry, "src/B.java": "b"},
+			expectedMarkers: map[string]string{},
+		},
+		{
+			name:    "should capture created folder",
+			entries: map[string]string{"src/A.java": "a"},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				writeEntries(t, copyRoot, map[string]string{"src/empty": folderEntry})
+			},
+			expectedChanges: map[string]string{"src": folderEntry, "src/empty": folderEntry},
+			expectedMarkers: map[string]string{},
+		},
+		{
+			name:    "should capture modified file",
+			entries: map[string]string{"src/A.java": "a", "src/B.java": "b"},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				writeEntries(t, copyRoot, map[string]string{"src/A.java": "changed"})
+			},
+			expectedChanges: map[string]string{"src": folderEntry, "src/A.java": "changed"},
+			expectedMarkers: map[string]string{},
+		},
+		{
+			name:    "should capture file modified to the same size",
+			entries: map[string]string{"src/A.java": "aaaa"},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				writeEntries(t, copyRoot, map[string]string{"src/A.java": "bbbb"})
+			},
+			expectedChanges: map[string]string{"src": folderEntry, "src/A.java": "bbbb"},
+			expectedMarkers: map[string]string{},
+		},
+		{
+			name:    "should not capture file rewritten with the same content",
+			entries: map[string]string{"src/A.java": "a"},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				writeEntries(t, copyRoot, map[string]string{"src/A.java": "a"})
+			},
+			expectedChanges: map[string]string{},
+			expectedMarkers: map[string]string{},
+		},
+		{
+			name:    "should mark deleted file",
+			entries: map[string]string{"src/A.java": "a", "src/B.java": "b"},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				removeEntry(t, filepath.Join(copyRoot, "src/A.java"))
+			},
+			expectedChanges: map[string]string{},
+			expectedMarkers: map[string]string{"src": folderEntry, "src/A.java" + deletedExtension: ""},
+		},
+		{
+			name: "should only mark the topmost deleted folder",
+			entries: map[string]string{
+				"src/lib/A.java":        "a",
+				"src/lib/nested/B.java": "b",
+				"src/C.java":            "c",
+			},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				removeEntry(t, filepath.Join(copyRoot, "src/lib"))
+			},
+			expectedChanges: map[string]string{},
+			expectedMarkers: map[string]string{"src": folderEntry, "src/lib" + deletedExtension: ""},
+		},
+		{
+			name:    "should capture created and retargeted symlinks",
+			entries: map[string]string{"src/A.java": "a", "src/B.java": "b", "src/current": linkPrefix + "A.java"},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				removeEntry(t, filepath.Join(copyRoot, "src/current"))
+				writeEntries(t, copyRoot, map[string]string{
+					"src/current": linkPrefix + "B.java",
+					"src/link":    linkPrefix + "A.java",
+				})
+			},
+			expectedChanges: map[string]string{
+				"src":         folderEntry,
+				"src/current": linkPrefix + "B.java",
+				"src/link":    linkPrefix + "A.java",
+			},
+			expectedMarkers: map[string]string{},
+		},
+		{
+			name:    "should capture file replaced by folder",
+			entries: map[string]string{"src/A": "a"},
+			change: func(t *testing.T, copyRoot string) {
+				t.Helper()
+				removeEntry(t, filepath.Join(copyRoot, "src/A"))
+				writeEntries(t, copyRoot, map[string]string{"src/A/B.java": "b"})
+			},
+			expectedChanges: map[string]string{"src": folderEntry, "src/A": folderEntry, "src/A/B.java": "b"},
+			expectedMarkers: map[string]string{},
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree, source := prepareScratchTree(t, testCase.entries)
+			testCase.change(t, tree.CopyPath(source))
+
+			changeCaptureFolder := filepath.Join(t.TempDir(), "changes")
+			if err := tree.CaptureChanges(changeCaptureFolder); err != nil {
+				t.Fatalf("Expected no error, but was '%v'", err)
+			}
+
+			if changes := readEntries(t, filepath.Join(changeCaptureFolder, source)); !reflect.DeepEqual(
+				changes, testCase.expectedChanges) {
+				t.Errorf("Expected changes to be '%v', but was '%v'", testCase.expectedChanges, changes)
+			}
+
+			metaFilesFolder := filepath.Join(changeCaptureFolder, mergeoptions.NewMergeOptions().MetaFilesLocation, source)
+			if markers := readEntries(t, metaFilesFolder); !reflect.DeepEqual(markers, testCase.expectedMarkers) {
+				t.Errorf("Expected deletion markers to be '%v', but was '%v'", testCase.expectedMarkers, markers)
+			}
+		})
+	}
+}
+
+func removeEntry(t *testing.T, path string) {
+	t.Helper()
+
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatalf("Error removing %s: %v", path, err)
+	}
+}
+
+// TestScratchTree_CaptureChangesMerged applies the captured changes like a pipeline does and expects the result to
+// match the copy after the changes.
+func TestScratchTree_CaptureChangesMerged(t *testing.T) {
+	t.Parallel()
+
+	entries := map[string]string{
+		"src/Modified.java":     "before",
+		"src/SameSize.java":     "aaaa",
+		"src/Deleted.java":      "deleted",
+		"src/lib/A.java":        "a",
+		"src/lib/nested/B.java": "b",
+		"src/current":           linkPrefix + "Modified.java",
+		"docs/README.md":        "docs",
+	}
+
+	tree, source := prepareScratchTree(t, entries)
+	copyRoot := tree.CopyPath(source)
+
+	removeEntry(t, filepath.Join(copyRoot, "src/Deleted.java"))
+	removeEntry(t, filepath.Join(copyRoot, "src/lib"))
+	removeEntry(t, filepath.Join(copyRoot, "src/current"))
+	writeEntries(t, copyRoot, map[string]string{
+		"src/Modified.java":     "after",
+		"src/SameSize.java":     "bbbb",
+		"src/Created.java":      "created",
+		"src/current":           linkPrefix + "Created.java",
+		"src/generated/D.java":  "d",
+		"docs/guide/install.md": "install",
+	})
+
+	changeCaptureFolder := filepath.Join(t.TempDir(), "changes")
+	if err := tree.CaptureChanges(changeCaptureFolder); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	// the step post processing flattens the meta files of the change capture
+	stagingFolder := filepath.Join(t.TempDir(), "staging")
+	flattenOptions := mergeoptions.NewMergeOptions()
+	flattenOptions.BlockOverwrite = false
+	flattenOptions.CopyMode = true
+	flattenOptions.MergeMetaFilesFolder = true
+	flattenOptions.DeleteEmptyFolders = false
+
+	if err := dirmerger.MergeFolders(
+		[]dirmerger.MergeEntity{dirmerger.NewMergeEntity(changeCaptureFolder, nil)},
+		stagingFolder,
+		flattenOptions,
+	); err != nil {
+		t.Fatalf("Expected no error flattening changes, but was '%v'", err)
+	}
+
+	// applying the changes merges them into the root, which contains the unchanged source
+	rootFolder := t.TempDir()
+	writeEntries(t, filepath.Join(rootFolder, source), entries)
+
+	applyOptions := mergeoptions.NewMergeOptions()
+	applyOptions.BlockOverwrite = false
+
+	if err := dirmerger.MergeFolders(
+		[]dirmerger.MergeEntity{dirmerger.NewMergeEntity(stagingFolder, nil)},
+		rootFolder,
+		applyOptions,
+	); err != nil {
+		t.Fatalf("Expected no error applying changes, but was '%v'", err)
+	}
+
+	markers := make([]string, 0)
+
+	for path := range readEntries(t, rootFolder) {
+		if strings.HasSuffix(path, applyOptions.MetaFilesDeletedExtension) {
+			markers = append(markers, filepath.Join(rootFolder, path))
+		}
+	}
+
+	if err := dirmerger.RemoveMarkedAsDeletedPaths(markers, applyOptions); err != nil {
+		t.Fatalf("Expected no error removing deleted paths, but was '%v'", err)
+	}
+
+	expected := readEntries(t, copyRoot)
+	if applied := readEntries(t, filepath.Join(rootFolder, source)); !reflect.DeepEqual(applied, expected) {
+		t.Errorf("Expected applied changes to be '%v', but was '%v'", expected, applied)
+	}
+}
+
+func TestScratchTree_PrepareWithMergeFolders(t *testing.T) {
+	t.Parallel()
+
+	deletedExtension := mergeoptions.NewMergeOptions().MetaFilesDeletedExtension
+	mergeFolder := t.TempDir()
+
+	source := filepath.Join(t.TempDir(), "project")
+	writeEntries(t, source, map[string]string{"src/A.java": "a", "src/B.java": "b", "src/lib/C.java": "c"})
+
+	// the flattened changes of the dependencies mark deleted entries inline
+	writeEntries(t, filepath.Join(mergeFolder, source), map[string]string{
+		"src/A.java":                    "changed",
+		"src/B.java" + deletedExtension: "",
+		"src/lib" + deletedExtension:    "",
+		"src/D.java":                    "d",
+	})
+	writeEntries(t, filepath.Join(mergeFolder, "outside"), map[string]string{"E.java": "e"})
+
+	tree := uut.NewScratchTree(source, filepath.Join(t.TempDir(), "scratch"))
+	if err := tree.Prepare([]string{mergeFolder}); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	expected := map[string]string{"src": folderEntry, "src/A.java": "changed", "src/D.java": "d"}
+	if copied := readEntries(t, tree.CopyPath(source)); !reflect.DeepEqual(copied, expected) {
+		t.Errorf("Expected copy to be '%v', but was '%v'", expected, copied)
+	}
+
+	// the changes of the dependencies are not changes of the step
+	changeCaptureFolder := filepath.Join(t.TempDir(), "changes")
+	if err := tree.CaptureChanges(changeCaptureFolder); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if changes := readEntries(t, changeCaptureFolder); len(changes) != 0 {
+		t.Errorf("Expected no changes, but was '%v'", changes)
+	}
+
+	if sourceEntries := readEntries(t, source); sourceEntries["src/A.java"] != "a" || sourceEntries["src/B.java"] != "b" {
+		t.Errorf("Expected source to be unchanged, but was '%v'", sourceEntries)
+	}
+}
+
+func TestRedirectPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    string
+		target   string
+		expected string
+	}{
+		{name: "should redirect the source", value: "/p/src", target: "/s/p/src", expected: "/s/p/src"},
+		{
+			name:     "should redirect paths within the source",
+			value:    "/p/src/A.java",
+			target:   "/s/p/src",
+			expected: "/s/p/src/A.java",
+		},
+		{
+			name:     "should redirect all paths",
+			value:    "--in=/p/src:/p/src/lib",
+			target:   "/s/p/src",
+			expected: "--in=/s/p/src:/s/p/src/lib",
+		},
+		{
+			name:     "should not redirect longer names",
+			value:    "/p/srcs/A.java",
+			target:   "/s/p/src",
+			expected: "/p/srcs/A.java",
+		},
+		{name: "should not redirect names with dashes", value: "/p/src-old", target: "/s/p/src", expected: "/p/src-old"},
+		{name: "should not redirect names with dots", value: "/p/src.bak", target: "/s/p/src", expected: "/p/src.bak"},
+		{
+			name:     "should not redirect paths ending with the source",
+			value:    "/other/p/src/A.java",
+			target:   "/s/p/src",
+			expected: "/other/p/src/A.java",
+		},
+		{
+			name:     "should not redirect urls",
+			value:    "--url=https://example.com/p/src",
+			target:   "/s/p/src",
+			expected: "--url=https://example.com/p/src",
+		},
+		{
+			name:     "should redirect paths following separators",
+			value:    "PATH=/bin:/p/src/bin",
+			target:   "/s/p/src",
+			expected: "PATH=/bin:/s/p/src/bin",
+		},
+		{name: "should keep dollar signs of the target", value: "/p/src/A", target: "/s/$1/src", expected: "/s/$1/src/A"},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			redirected := uut.RedirectPaths("/p/src", testCase.target, testCase.value)
+			if redirected != testCase.expected {
+				t.Errorf("Expected '%s', but was '%s'", testCase.expected, redirected)
+			}
+		})
+	}
+}
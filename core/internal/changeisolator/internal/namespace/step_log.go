@@ -0,0 +1,91 @@
+package namespace
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"chast.io/core/internal/changeisolator/pkg/namespace"
+)
+
+const logTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// stepLog writes the output of the commands to the log of the step, each line prefixed with a timestamp and the
+// stream it was written to. Commands are separated by header and footer lines.
+type stepLog struct {
+	writer io.WriteCloser
+	lock   sync.Mutex
+}
+
+func newStepLog(writer io.WriteCloser) *stepLog {
+	return &stepLog{
+		writer: writer,
+		lock:   sync.Mutex{},
+	}
+}
+
+func (log *stepLog) close() {
+	_ = log.writer.Close()
+}
+
+func (log *stepLog) commandStarted(command []string) {
+	log.writeLine("===", "command: "+strings.Join(command, " "))
+}
+
+func (log *stepLog) commandFinished(result namespace.CommandResult) {
+	status := "succeeded"
+	if result.Error != "" {
+		status = result.Error
+	}
+
+	log.writeLine("===", fmt.Sprintf("exit code %d after %s: %s", result.ExitCode, result.Duration.Round(time.Millisecond), status))
+}
+
+// stream returns a writer for the output of a command, which has to be flushed after the command is done.
+func (log *stepLog) stream(name string) *logStream {
+	return &logStream{
+		log:     log,
+		name:    name,
+		pending: make([]byte, 0),
+	}
+}
+
+func (log *stepLog) writeLine(stream string, line string) {
+	log.lock.Lock()
+	defer log.lock.Unlock()
+
+	_, _ = fmt.Fprintf(log.writer, "%s %s %s\n", time.Now().Format(logTimeFormat), stream, line)
+}
+
+type logStream struct {
+	log     *stepLog
+	name    string
+	pending []byte
+}
+
+func (stream *logStream) Write(data []byte) (int, error) {
+	stream.pending = append(stream.pending, data...)
+
+	for {
+		lineEnd := bytes.IndexByte(stream.pending, '\n')
+		if lineEnd < 0 {
+			break
+		}
+
+		stream.log.writeLine(stream.name, string(stream.pending[:lineEnd]))
+		stream.pending = stream.pending[lineEnd+1:]
+	}
+
+	return len(data), nil
+}
+
+// flush writes the last line if the command did not terminate it.
+func (stream *logStream) flush() {
+	if len(stream.pending) > 0 {
+		stream.log.writeLine(stream.name, string(stream.pending))
+		stream.pending = stream.pending[:0]
+	}
+}
@@ -1,20 +1,54 @@
 package changeisolator
 
 import (
+	"context"
+
 	namespaceInternal "chast.io/core/internal/changeisolator/internal/namespace"
 	"chast.io/core/internal/changeisolator/pkg/namespace"
 	"github.com/joomcode/errorx"
 )
 
-func RunCommandInIsolatedEnvironment(nsContext *namespace.Context) error {
+// RunCommandInIsolatedEnvironment runs the commands of the context in an isolated environment and returns the results
+// of the commands which ran and the metrics of the run. If the context is done before, the commands are stopped. The
+// error is caused by a namespace.CommandError if a command failed.
+func RunCommandInIsolatedEnvironment(
+	ctx context.Context,
+	nsContext *namespace.Context,
+) ([]namespace.CommandResult, namespace.RunMetrics, error) {
 	userNamespaceRunnerContext := namespaceInternal.New(nsContext)
 	if err := userNamespaceRunnerContext.Initialize(); err != nil {
-		return errorx.InternalError.Wrap(err, "Error initializing user namespace runner context")
+		return nil, userNamespaceRunnerContext.Metrics(),
+			errorx.InternalError.Wrap(err, "Error initializing user namespace runner context")
+	}
+
+	results, runError := userNamespaceRunnerContext.Run(ctx)
+	if runError != nil {
+		return results, userNamespaceRunnerContext.Metrics(),
+			errorx.InternalError.Wrap(runError, "Failed to run command in isolated environment")
+	}
+
+	return results, userNamespaceRunnerContext.Metrics(), nil
+}
+
+// RunCommandInScratchCopy runs the commands of the context without namespaces in a scratch copy of its root folder,
+// which contains the flattened changes of its merge folders, and returns the results of the commands which ran and
+// the metrics of the run. The changes are captured in the same layout as in an isolated environment. If the context is
+// done before, the commands are stopped. The error is caused by a namespace.CommandError if a command failed.
+func RunCommandInScratchCopy(
+	ctx context.Context,
+	nsContext *namespace.Context,
+) ([]namespace.CommandResult, namespace.RunMetrics, error) {
+	scratchCopyRunnerContext := namespaceInternal.NewScratchCopy(nsContext)
+	if err := scratchCopyRunnerContext.Initialize(); err != nil {
+		return nil, scratchCopyRunnerContext.Metrics(),
+			errorx.InternalError.Wrap(err, "Error initializing scratch copy runner context")
 	}
 
-	if err := userNamespaceRunnerContext.Run(); err != nil {
-		return errorx.InternalError.Wrap(err, "Failed to run command in isolated environment")
+	results, runError := scratchCopyRunnerContext.Run(ctx)
+	if runError != nil {
+		return results, scratchCopyRunnerContext.Metrics(),
+			errorx.InternalError.Wrap(runError, "Failed to run command in scratch copy")
 	}
 
-	return nil
+	return results, scratchCopyRunnerContext.Metrics(), nil
 }
@@ -0,0 +1,44 @@
+package namespace
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CommandResult is the outcome of a command run in the namespace.
+type CommandResult struct {
+	Command  []string `json:"command"`
+	ExitCode int      `json:"exitCode"`
+	// Error describes why the command did not succeed, empty if it exited with zero.
+	Error string `json:"error,omitempty"`
+	// Failed is set if the command exited with a code which is neither zero nor allowed.
+	Failed bool `json:"failed,omitempty"`
+	// Duration is the wall time the command ran.
+	Duration time.Duration `json:"duration"`
+}
+
+func (result *CommandResult) CommandString() string {
+	return strings.Join(result.Command, " ")
+}
+
+// CommandError is the cause of the error of a run whose command failed.
+type CommandError struct {
+	Result CommandResult
+}
+
+func (err *CommandError) Error() string {
+	return fmt.Sprintf("command \"%s\" failed with exit code %d: %s",
+		err.Result.CommandString(), err.Result.ExitCode, err.Result.Error)
+}
+
+// FirstFailure returns the first failed command, or nil if no command failed.
+func FirstFailure(results []CommandResult) *CommandResult {
+	for index := range results {
+		if results[index].Failed {
+			return &results[index]
+		}
+	}
+
+	return nil
+}
@@ -13,6 +13,16 @@ type Context struct {
 	OperationDirectory  string
 	WorkingDirectory    string
 	Commands            [][]string
+	// Environment contains additional "KEY=value" entries for the environment of the commands.
+	Environment []string
+	// AllowedExitCodes are exit codes which do not fail a command in addition to zero.
+	AllowedExitCodes []int
+	// ContinueOnError runs the remaining commands after a command failed and does not fail the run.
+	ContinueOnError bool
+	// LogFile is the file the output of the commands is appended to, nothing is logged if it is empty.
+	LogFile string
+	// OutputPrefix is written before every line the commands write to stdout and stderr, if it is not empty.
+	OutputPrefix string
 
 	IsolationStrategy strategy.IsolationStrategy
 }
@@ -24,6 +34,11 @@ func NewContext(
 	operationDirectory string,
 	workingDirectory string,
 	command [][]string,
+	environment []string,
+	allowedExitCodes []int,
+	continueOnError bool,
+	logFile string,
+	outputPrefix string,
 	isolationStrategy strategy.IsolationStrategy,
 ) *Context {
 	return &Context{
@@ -33,11 +48,31 @@ func NewContext(
 		OperationDirectory:  operationDirectory,
 		WorkingDirectory:    workingDirectory,
 		Commands:            command,
+		Environment:         environment,
+		AllowedExitCodes:    allowedExitCodes,
+		ContinueOnError:     continueOnError,
+		LogFile:             logFile,
+		OutputPrefix:        outputPrefix,
 
 		IsolationStrategy: isolationStrategy,
 	}
 }
 
+// IsAllowedExitCode checks if the exit code does not fail a command.
+func (nsc *Context) IsAllowedExitCode(exitCode int) bool {
+	if exitCode == 0 {
+		return true
+	}
+
+	for _, allowedExitCode := range nsc.AllowedExitCodes {
+		if exitCode == allowedExitCode {
+			return true
+		}
+	}
+
+	return false
+}
+
 func NewEmptyContext() *Context {
 	return &Context{} //nolint:exhaustruct // initialized empty here for later full initialization
 }
@@ -0,0 +1,35 @@
+package namespace
+
+import (
+	"syscall"
+	"time"
+)
+
+// RunMetrics describes where the wall time of an isolated run went and which resources its processes used.
+type RunMetrics struct {
+	// IsolationSetup is the time spent preparing the isolated file system outside and inside the namespace.
+	IsolationSetup time.Duration
+	// Commands is the time the commands ran.
+	Commands time.Duration
+	// IsolationCleanup is the remaining time of the run, mostly spent tearing down the isolated file system.
+	IsolationCleanup time.Duration
+	// UserCPU and SystemCPU are the CPU times of the process of the namespace and all processes it waited for.
+	UserCPU   time.Duration
+	SystemCPU time.Duration
+	// MaxRSSKiB is the largest resident set size of the process of the namespace or one of its children.
+	MaxRSSKiB int64
+}
+
+// AddResourceUsage adds the resources used by a terminated process to the metrics.
+func (metrics *RunMetrics) AddResourceUsage(usage *syscall.Rusage) {
+	if usage == nil {
+		return
+	}
+
+	metrics.UserCPU += time.Duration(usage.Utime.Nano())
+	metrics.SystemCPU += time.Duration(usage.Stime.Nano())
+
+	if maxRSS := int64(usage.Maxrss); maxRSS > metrics.MaxRSSKiB { //nolint:unconvert // the type depends on the platform
+		metrics.MaxRSSKiB = maxRSS
+	}
+}
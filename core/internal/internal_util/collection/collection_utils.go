@@ -85,3 +85,23 @@ func Prepend[S interface{}](source []S, elements ...S) []S {
 func Append[S interface{}](source []S, elements ...S) []S {
 	return append(source, elements...)
 }
+
+// Chunk splits the source into consecutive chunks of the given size. The last chunk may be smaller.
+func Chunk[S interface{}](source []S, size int) [][]S {
+	if size < 1 {
+		size = 1
+	}
+
+	chunks := make([][]S, 0, (len(source)+size-1)/size)
+
+	for start := 0; start < len(source); start += size {
+		end := start + size
+		if end > len(source) {
+			end = len(source)
+		}
+
+		chunks = append(chunks, source[start:end])
+	}
+
+	return chunks
+}
@@ -0,0 +1,175 @@
+package glob
+
+import (
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joomcode/errorx"
+)
+
+const metaCharacters = "*?["
+
+// Expand returns all files matching the absolute glob pattern, sorted by path.
+// "*" and "?" match within a single path segment, "**" matches across any number of segments and "[...]" matches a
+// character class.
+func Expand(pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		return nil, errorx.IllegalArgument.New("Glob pattern \"%s\" must be absolute", pattern)
+	}
+
+	pattern = filepath.Clean(pattern)
+
+	matcher, compileError := regexp.Compile(ToRegexp(pattern))
+	if compileError != nil {
+		return nil, errorx.IllegalFormat.Wrap(compileError, "Invalid glob pattern \"%s\"", pattern)
+	}
+
+	root := StaticRoot(pattern)
+	matches := make([]string, 0)
+
+	if walkError := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() && matcher.MatchString(path) {
+			matches = append(matches, path)
+		}
+
+		return nil
+	}); walkError != nil {
+		return nil, errorx.ExternalError.Wrap(walkError, "Could not expand glob pattern \"%s\"", pattern)
+	}
+
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+// IsPattern checks if the value contains any glob meta characters.
+func IsPattern(value string) bool {
+	return strings.ContainsAny(value, metaCharacters)
+}
+
+// ToRegexp converts a glob pattern into an anchored regular expression.
+func ToRegexp(pattern string) string {
+	var result strings.Builder
+
+	result.WriteString("^")
+
+	for index := 0; index < len(pattern); index++ {
+		character := pattern[index]
+
+		switch character {
+		case '*':
+			if index+1 < len(pattern) && pattern[index+1] == '*' {
+				index++
+
+				if index+1 < len(pattern) && pattern[index+1] == '/' {
+					index++
+
+					result.WriteString("(.*/)?")
+				} else {
+					result.WriteString(".*")
+				}
+			} else {
+				result.WriteString("[^/]*")
+			}
+		case '?':
+			result.WriteString("[^/]")
+		case '[':
+			class, closingIndex, isClass := classToRegexp(pattern, index)
+			if !isClass {
+				result.WriteString(regexp.QuoteMeta(string(character)))
+
+				continue
+			}
+
+			result.WriteString(class)
+
+			index = closingIndex
+		default:
+			result.WriteString(regexp.QuoteMeta(string(character)))
+		}
+	}
+
+	result.WriteString("$")
+
+	return result.String()
+}
+
+// classToRegexp converts the character class starting at the index into a regular expression and returns it together
+// with the index of its closing bracket. A class starting with "!" is negated, "-" separates the bounds of a range and
+// "\" escapes the next character. A "]" directly after the opening bracket or the negation is part of the class. It
+// is no class if it is not closed.
+func classToRegexp(pattern string, start int) (string, int, bool) {
+	var class strings.Builder
+
+	class.WriteString("[")
+
+	index := start + 1
+	if index < len(pattern) && pattern[index] == '!' {
+		class.WriteString("^")
+
+		index++
+	}
+
+	firstIndex := index
+
+	for ; index < len(pattern); index++ {
+		character := pattern[index]
+
+		switch {
+		case character == ']' && index > firstIndex:
+			class.WriteString("]")
+
+			return class.String(), index, true
+		case character == '\\' && index+1 < len(pattern):
+			index++
+			class.WriteString(quoteClassCharacter(pattern[index]))
+		case character == '-' && index > firstIndex && index+1 < len(pattern) && pattern[index+1] != ']':
+			class.WriteString("-")
+		default:
+			class.WriteString(quoteClassCharacter(character))
+		}
+	}
+
+	return "", start, false
+}
+
+// quoteClassCharacter escapes the character if it has a meaning within a character class of a regular expression.
+func quoteClassCharacter(character byte) string {
+	if strings.IndexByte(`\]^[-`, character) >= 0 {
+		return `\` + string(character)
+	}
+
+	return string(character)
+}
+
+// StaticRoot returns the deepest folder of the pattern which does not contain any meta characters.
+func StaticRoot(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	staticSegments := make([]string, 0, len(segments))
+
+	for _, segment := range segments {
+		if IsPattern(segment) {
+			break
+		}
+
+		staticSegments = append(staticSegments, segment)
+	}
+
+	if len(staticSegments) == len(segments) {
+		return pattern // no pattern at all, the path itself is walked
+	}
+
+	root := strings.Join(staticSegments, "/")
+	if root == "" {
+		return "/"
+	}
+
+	return root
+}
@@ -0,0 +1,93 @@
+package glob_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	uut "chast.io/core/internal/internal_util/glob"
+)
+
+func TestToRegexp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		value   string
+		matches bool
+	}{
+		{pattern: "/src/*.java", value: "/src/Main.java", matches: true},
+		{pattern: "/src/*.java", value: "/src/main/Main.java", matches: false},
+		{pattern: "/src/**/*.java", value: "/src/Main.java", matches: true},
+		{pattern: "/src/**/*.java", value: "/src/main/java/Main.java", matches: true},
+		{pattern: "/src/**", value: "/src/main/Main.py", matches: true},
+		{pattern: "/src/?.java", value: "/src/A.java", matches: true},
+		{pattern: "/src/?.java", value: "/src/AB.java", matches: false},
+		{pattern: "/src/[AB].java", value: "/src/B.java", matches: true},
+		{pattern: "/src/[!AB].java", value: "/src/B.java", matches: false},
+		{pattern: "/src/a+b.java", value: "/src/a+b.java", matches: true},
+		{pattern: "/src/[!x].java", value: "/src/y.java", matches: true},
+		{pattern: "/src/[!x].java", value: "/src/x.java", matches: false},
+		{pattern: "/src/[a!b].java", value: "/src/!.java", matches: true},
+		{pattern: "/src/[a!b].java", value: "/src/b.java", matches: true},
+		{pattern: "/src/[a!b].java", value: "/src/^.java", matches: false},
+		{pattern: "/src/[a!b].java", value: "/src/c.java", matches: false},
+		{pattern: `/src/[\]].java`, value: "/src/].java", matches: true},
+		{pattern: `/src/[\]].java`, value: `/src/\.java`, matches: false},
+		{pattern: "/src/[]a].java", value: "/src/].java", matches: true},
+		{pattern: "/src/[!]].java", value: "/src/].java", matches: false},
+		{pattern: "/src/[a-c].java", value: "/src/b.java", matches: true},
+		{pattern: "/src/[a-].java", value: "/src/-.java", matches: true},
+		{pattern: "/src/[a-].java", value: "/src/b.java", matches: false},
+		{pattern: "/src/[^a].java", value: "/src/^.java", matches: true},
+		{pattern: "/src/[^a].java", value: "/src/b.java", matches: false},
+		{pattern: "/src/[a.java", value: "/src/[a.java", matches: true},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.pattern+" "+testCase.value, func(t *testing.T) {
+			t.Parallel()
+
+			matcher := regexp.MustCompile(uut.ToRegexp(testCase.pattern))
+			if matches := matcher.MatchString(testCase.value); matches != testCase.matches {
+				t.Errorf("Expected match to be %v, but was %v", testCase.matches, matches)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+
+	for _, file := range []string{"Main.java", "main/Other.java", "main/deep/Deep.java", "main/script.py"} {
+		path := filepath.Join(baseDir, file)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Error creating folder: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte{}, 0o600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+
+	matches, err := uut.Expand(filepath.Join(baseDir, "**", "*.java"))
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	expected := []string{
+		filepath.Join(baseDir, "Main.java"),
+		filepath.Join(baseDir, "main", "Other.java"),
+		filepath.Join(baseDir, "main", "deep", "Deep.java"),
+	}
+	if !reflect.DeepEqual(matches, expected) {
+		t.Errorf("Expected matches to be '%v', but was '%v'", expected, matches)
+	}
+}
@@ -0,0 +1,231 @@
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"chast.io/core/internal/internal_util/glob"
+	chastlog "chast.io/core/internal/logger"
+)
+
+// FileNames are the ignore files read in every folder. Rules of later files take precedence.
+var FileNames = []string{".gitignore", ".chastignore"} //nolint:gochecknoglobals // constant list
+
+const gitFolder = ".git"
+
+type rule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher decides if paths are ignored according to the ignore files between its root and the path.
+// It follows the gitignore semantics: rules of nested files take precedence over the ones of parent folders,
+// later rules take precedence over earlier ones and files inside ignored folders can not be re-included.
+type Matcher struct {
+	root string
+
+	lock         sync.Mutex
+	rulesByDir   map[string][]rule
+	ignoredByDir map[string]bool
+}
+
+// FindRoot returns the root of the repository containing the path, or the folder of the path if there is none.
+func FindRoot(path string) string {
+	absolutePath, _ := filepath.Abs(path)
+
+	folder := absolutePath
+	if info, err := os.Stat(absolutePath); err != nil || !info.IsDir() {
+		folder = filepath.Dir(absolutePath)
+	}
+
+	for current := folder; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, gitFolder)); err == nil {
+			return current
+		}
+
+		if current == filepath.Dir(current) {
+			return folder
+		}
+	}
+}
+
+func NewMatcher(root string) *Matcher {
+	absoluteRoot, _ := filepath.Abs(root)
+
+	return &Matcher{
+		root:         absoluteRoot,
+		lock:         sync.Mutex{},
+		rulesByDir:   make(map[string][]rule),
+		ignoredByDir: make(map[string]bool),
+	}
+}
+
+// IsIgnored checks if the absolute path is ignored. Paths outside the root are never ignored.
+func (m *Matcher) IsIgnored(path string, isDir bool) bool {
+	path = filepath.Clean(path)
+
+	if path == m.root || !strings.HasPrefix(path, m.root+string(filepath.Separator)) && m.root != "/" {
+		return false
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if parent := filepath.Dir(path); parent != m.root && m.isFolderIgnored(parent) {
+		return true
+	}
+
+	return m.matches(path, isDir)
+}
+
+func (m *Matcher) isFolderIgnored(folder string) bool {
+	if ignored, isCached := m.ignoredByDir[folder]; isCached {
+		return ignored
+	}
+
+	ignored := false
+
+	if parent := filepath.Dir(folder); parent != m.root && len(parent) > len(m.root) {
+		ignored = m.isFolderIgnored(parent)
+	}
+
+	if !ignored {
+		ignored = m.matches(folder, true)
+	}
+
+	m.ignoredByDir[folder] = ignored
+
+	return ignored
+}
+
+// matches evaluates the rules of all folders from the root to the parent of the path, the last matching rule wins.
+func (m *Matcher) matches(path string, isDir bool) bool {
+	if filepath.Base(path) == gitFolder {
+		return true
+	}
+
+	folders := make([]string, 0)
+	for folder := filepath.Dir(path); len(folder) >= len(m.root); folder = filepath.Dir(folder) {
+		folders = append([]string{folder}, folders...)
+
+		if folder == m.root || folder == filepath.Dir(folder) {
+			break
+		}
+	}
+
+	ignored := false
+
+	for _, folder := range folders {
+		relativePath, relError := filepath.Rel(folder, path)
+		if relError != nil {
+			continue
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+
+		for _, folderRule := range m.rulesOf(folder) {
+			if folderRule.dirOnly && !isDir {
+				continue
+			}
+
+			if folderRule.pattern.MatchString(relativePath) {
+				ignored = !folderRule.negate
+			}
+		}
+	}
+
+	return ignored
+}
+
+func (m *Matcher) rulesOf(folder string) []rule {
+	if rules, isCached := m.rulesByDir[folder]; isCached {
+		return rules
+	}
+
+	rules := make([]rule, 0)
+
+	for _, fileName := range FileNames {
+		rules = append(rules, readRules(filepath.Join(folder, fileName))...)
+	}
+
+	m.rulesByDir[folder] = rules
+
+	return rules
+}
+
+func readRules(ignoreFilePath string) []rule {
+	file, openError := os.Open(ignoreFilePath)
+	if openError != nil {
+		return nil // missing ignore files are the common case
+	}
+	defer file.Close()
+
+	rules := make([]rule, 0)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if parsedRule, isRule := parseRule(scanner.Text()); isRule {
+			rules = append(rules, parsedRule)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		chastlog.Log.Warnf("Could not read ignore file %s: %v", ignoreFilePath, err)
+	}
+
+	return rules
+}
+
+// parseRule parses a single line of an ignore file. Blank lines and comments are no rules.
+func parseRule(line string) (rule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false //nolint:exhaustruct // no rule
+	}
+
+	negate := false
+
+	switch {
+	case strings.HasPrefix(line, "!"):
+		negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, "\\!"), strings.HasPrefix(line, "\\#"):
+		line = line[1:]
+	}
+
+	dirOnly := strings.HasSuffix(line, "/")
+	line = strings.TrimSuffix(line, "/")
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	if line == "" {
+		return rule{}, false //nolint:exhaustruct // no rule
+	}
+
+	expression := strings.TrimPrefix(glob.ToRegexp(line), "^")
+	if anchored {
+		expression = "^" + expression
+	} else {
+		expression = "^(.*/)?" + expression
+	}
+
+	pattern, compileError := regexp.Compile(expression)
+	if compileError != nil {
+		chastlog.Log.Warnf("Skipping invalid ignore pattern \"%s\": %v", line, compileError)
+
+		return rule{}, false //nolint:exhaustruct // no rule
+	}
+
+	return rule{
+		pattern: pattern,
+		negate:  negate,
+		dirOnly: dirOnly,
+	}, true
+}
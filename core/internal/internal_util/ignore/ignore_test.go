@@ -0,0 +1,105 @@
+package ignore_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	uut "chast.io/core/internal/internal_util/ignore"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Error creating folder: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+func TestMatcher_IsIgnored(t *testing.T) { //nolint:funlen // table test
+	t.Parallel()
+
+	root := t.TempDir()
+
+	writeTestFile(t, filepath.Join(root, ".gitignore"), `
+# comment
+node_modules/
+*.log
+!important.log
+/build
+docs/**/generated
+secret
+`)
+	writeTestFile(t, filepath.Join(root, "src", ".gitignore"), "!secret\nlocal.txt\n")
+	writeTestFile(t, filepath.Join(root, ".chastignore"), "*.bak\n")
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{path: "node_modules", isDir: true, ignored: true},
+		{path: "node_modules/lib/index.js", isDir: false, ignored: true},
+		{path: "src/node_modules/index.js", isDir: false, ignored: true},
+		{path: "node_modules", isDir: false, ignored: false},
+		{path: "debug.log", isDir: false, ignored: true},
+		{path: "src/debug.log", isDir: false, ignored: true},
+		{path: "important.log", isDir: false, ignored: false},
+		{path: "build/output.jar", isDir: false, ignored: true},
+		{path: "src/build/Main.java", isDir: false, ignored: false},
+		{path: "docs/generated", isDir: true, ignored: true},
+		{path: "docs/api/v1/generated/index.html", isDir: false, ignored: true},
+		{path: "secret", isDir: false, ignored: true},
+		{path: "src/secret", isDir: false, ignored: false},
+		{path: "src/local.txt", isDir: false, ignored: true},
+		{path: "local.txt", isDir: false, ignored: false},
+		{path: "src/Main.java.bak", isDir: false, ignored: true},
+		{path: ".git/config", isDir: false, ignored: true},
+		{path: "src/Main.java", isDir: false, ignored: false},
+	}
+
+	matcher := uut.NewMatcher(root)
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.path, func(t *testing.T) {
+			t.Parallel()
+
+			if ignored := matcher.IsIgnored(filepath.Join(root, testCase.path), testCase.isDir); ignored != testCase.ignored {
+				t.Errorf("Expected ignored to be %v, but was %v", testCase.ignored, ignored)
+			}
+		})
+	}
+}
+
+func TestMatcher_IsIgnored_OutsideRoot(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "project", ".gitignore"), "*\n")
+
+	if uut.NewMatcher(filepath.Join(root, "project")).IsIgnored(filepath.Join(root, "other", "file.txt"), false) {
+		t.Errorf("Expected paths outside of the root to not be ignored")
+	}
+}
+
+func TestFindRoot(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatalf("Error creating folder: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(root, "src", "main", "Main.java"), "")
+
+	if actualRoot := uut.FindRoot(filepath.Join(root, "src", "main", "Main.java")); actualRoot != root {
+		t.Errorf("Expected root to be '%s', but was '%s'", root, actualRoot)
+	}
+}
@@ -0,0 +1,58 @@
+package pathreplace
+
+import "strings"
+
+// ReplaceFolder replaces the folder with the replacement wherever the value contains it as a path or as the beginning
+// of a path, e.g. in "--input=<folder>/src". Occurrences within longer names or paths, such as "/other<folder>",
+// "<folder>-old" or "<folder>.bak", are kept.
+func ReplaceFolder(value string, folder string, replacement string) string {
+	if folder == "" {
+		return value
+	}
+
+	var builder strings.Builder
+
+	remaining := value
+	previous := byte(0) // the byte before the remaining value, zero at the beginning
+
+	for {
+		index := strings.Index(remaining, folder)
+		if index < 0 {
+			builder.WriteString(remaining)
+
+			return builder.String()
+		}
+
+		if index > 0 {
+			previous = remaining[index-1]
+		}
+
+		end := index + len(folder)
+		startsPath := !isPathByte(previous)
+		endsName := end == len(remaining) || !isNameByte(remaining[end])
+
+		builder.WriteString(remaining[:index])
+
+		if startsPath && endsName {
+			builder.WriteString(replacement)
+		} else {
+			builder.WriteString(folder)
+		}
+
+		previous = folder[len(folder)-1]
+		remaining = remaining[end:]
+	}
+}
+
+// isNameByte reports whether the byte can be part of a file name. Bytes of multi-byte characters are.
+func isNameByte(character byte) bool {
+	return character >= 'a' && character <= 'z' ||
+		character >= 'A' && character <= 'Z' ||
+		character >= '0' && character <= '9' ||
+		character == '_' || character == '.' || character == '-' ||
+		character >= 0x80
+}
+
+func isPathByte(character byte) bool {
+	return isNameByte(character) || character == '/'
+}
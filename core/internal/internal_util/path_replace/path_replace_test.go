@@ -0,0 +1,39 @@
+package pathreplace_test
+
+import (
+	"testing"
+
+	uut "chast.io/core/internal/internal_util/path_replace"
+)
+
+func TestReplaceFolder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "should replace the folder", value: "/p/src", expected: "/s/src"},
+		{name: "should replace paths within the folder", value: "/p/src/A.java", expected: "/s/src/A.java"},
+		{name: "should replace the folder within arguments", value: "--in=/p/src", expected: "--in=/s/src"},
+		{name: "should replace all paths", value: "/p/src:/p/src/lib /p/src", expected: "/s/src:/s/src/lib /s/src"},
+		{name: "should keep longer names", value: "/p/srcs/A.java", expected: "/p/srcs/A.java"},
+		{name: "should keep names with dashes", value: "/p/src-old", expected: "/p/src-old"},
+		{name: "should keep names with dots", value: "/p/src.bak", expected: "/p/src.bak"},
+		{name: "should keep paths ending with the folder", value: "/other/p/src/A.java", expected: "/other/p/src/A.java"},
+		{name: "should keep urls", value: "file:///p/src", expected: "file:///p/src"},
+		{name: "should keep values without the folder", value: "--verbose", expected: "--verbose"},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			if replaced := uut.ReplaceFolder(testCase.value, "/p/src", "/s/src"); replaced != testCase.expected {
+				t.Errorf("Expected '%s', but was '%s'", testCase.expected, replaced)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package shell
+
+import (
+	"strings"
+
+	"github.com/joomcode/errorx"
+)
+
+// Shell is the interpreter a script is run with.
+type Shell = string
+
+const (
+	Bash    Shell = "bash"
+	Sh      Shell = "sh"
+	Python3 Shell = "python3"
+	// None runs the script as a single program; its arguments are split like a POSIX shell without expansions.
+	None Shell = "none"
+
+	Default = Bash
+)
+
+func Shells() []Shell {
+	return []Shell{Bash, Sh, Python3, None}
+}
+
+func IsSupported(shell Shell) bool {
+	for _, supportedShell := range Shells() {
+		if shell == supportedShell {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Invocation returns the arguments running the script verbatim with the shell.
+func Invocation(shell Shell, script string) ([]string, error) {
+	switch shell {
+	case Bash, "":
+		return []string{"/bin/bash", "-c", script}, nil
+	case Sh:
+		return []string{"/bin/sh", "-c", script}, nil
+	case Python3:
+		return []string{"python3", "-c", script}, nil
+	case None:
+		arguments, splitError := SplitArguments(script)
+		if splitError != nil {
+			return nil, splitError
+		}
+
+		if len(arguments) == 0 {
+			return nil, errorx.IllegalArgument.New("Script without shell must not be empty")
+		}
+
+		return arguments, nil
+	default:
+		return nil, errorx.IllegalArgument.New("Unsupported shell '%s', supported are %v", shell, Shells())
+	}
+}
+
+// SplitArguments splits the script into arguments like a POSIX shell, honoring single quotes, double quotes and
+// backslash escapes. No expansions take place.
+func SplitArguments(script string) ([]string, error) { //nolint:cyclop // state machine of the quoting rules
+	arguments := make([]string, 0)
+
+	var current strings.Builder
+
+	inArgument := false
+	quote := rune(0)
+	escaped := false
+
+	for _, character := range script {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune(`$"\`+"`\n", character) {
+				current.WriteRune('\\') // inside double quotes, the backslash only escapes special characters
+			}
+
+			if character != '\n' {
+				current.WriteRune(character)
+			}
+
+			escaped = false
+		case character == '\\' && quote != '\'':
+			escaped = true
+			inArgument = true
+		case quote != 0:
+			if character == quote {
+				quote = 0
+			} else {
+				current.WriteRune(character)
+			}
+		case character == '\'' || character == '"':
+			quote = character
+			inArgument = true
+		case character == ' ' || character == '\t' || character == '\n':
+			if inArgument {
+				arguments = append(arguments, current.String())
+				current.Reset()
+
+				inArgument = false
+			}
+		default:
+			current.WriteRune(character)
+
+			inArgument = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errorx.IllegalFormat.New("Unterminated %c quote in %q", quote, script)
+	}
+
+	if escaped {
+		return nil, errorx.IllegalFormat.New("Unterminated escape at the end of %q", script)
+	}
+
+	if inArgument {
+		arguments = append(arguments, current.String())
+	}
+
+	return arguments, nil
+}
@@ -0,0 +1,91 @@
+package shell_test
+
+import (
+	"reflect"
+	"testing"
+
+	uut "chast.io/core/internal/internal_util/shell"
+)
+
+func TestSplitArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "plain", script: "mv a.out  a", want: []string{"mv", "a.out", "a"}},
+		{name: "single quotes", script: `comby 'f(:[a], :[b])' x`, want: []string{"comby", "f(:[a], :[b])", "x"}},
+		{name: "double quotes", script: `echo "a 'b' \"c\" \d"`, want: []string{"echo", `a 'b' "c" \d`}},
+		{name: "escaped space", script: `cat my\ file`, want: []string{"cat", "my file"}},
+		{name: "empty quotes", script: `printf ''`, want: []string{"printf", ""}},
+		{name: "adjacent quotes", script: `a'b'"c"d`, want: []string{"abcd"}},
+		{name: "newlines", script: "a \\\nb\nc", want: []string{"a", "b", "c"}},
+		{name: "empty", script: "  ", want: []string{}},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := uut.SplitArguments(testCase.script)
+			if err != nil {
+				t.Fatalf("Expected no error, but was '%v'", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("Expected arguments to be %q, but was %q", testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitArguments_Unterminated(t *testing.T) {
+	t.Parallel()
+
+	for _, script := range []string{`echo 'a`, `echo "a`, `echo a\`} {
+		if _, err := uut.SplitArguments(script); err == nil {
+			t.Errorf("Expected error for %q, but was nil", script)
+		}
+	}
+}
+
+func TestInvocation(t *testing.T) {
+	t.Parallel()
+
+	script := `comby 'a b' "$inputFile"`
+
+	tests := []struct {
+		shell uut.Shell
+		want  []string
+	}{
+		{shell: uut.Bash, want: []string{"/bin/bash", "-c", script}},
+		{shell: uut.Sh, want: []string{"/bin/sh", "-c", script}},
+		{shell: uut.Python3, want: []string{"python3", "-c", script}},
+		{shell: uut.None, want: []string{"comby", "a b", "$inputFile"}},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.shell, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := uut.Invocation(testCase.shell, script)
+			if err != nil {
+				t.Fatalf("Expected no error, but was '%v'", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("Expected invocation to be %q, but was %q", testCase.want, got)
+			}
+		})
+	}
+
+	if _, err := uut.Invocation("fish", script); err == nil {
+		t.Error("Expected error for unsupported shell, but was nil")
+	}
+}
@@ -0,0 +1,117 @@
+package treehash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"chast.io/core/internal/internal_util/ignore"
+	"github.com/joomcode/errorx"
+)
+
+// HashFile returns the hex encoded SHA-256 hash of the content of the file.
+func HashFile(path string) (string, error) {
+	fileHash := sha256.New()
+
+	if err := writeFileContent(fileHash, path); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(fileHash.Sum(nil)), nil
+}
+
+// HashTree returns the hex encoded SHA-256 hash of the paths, permissions and contents of all files below the root,
+// which can also be a single file. Paths ignored by .gitignore and .chastignore files are not part of the hash.
+func HashTree(root string) (string, error) {
+	return hashTree(root, true)
+}
+
+// HashTreeIncludingIgnored returns the hash of HashTree, but ignore files are not respected.
+func HashTreeIncludingIgnored(root string) (string, error) {
+	return hashTree(root, false)
+}
+
+func hashTree(root string, respectIgnoreFiles bool) (string, error) {
+	absoluteRoot, absError := filepath.Abs(root)
+	if absError != nil {
+		return "", errorx.ExternalError.Wrap(absError, "Failed to get absolute path of %s", root)
+	}
+
+	ignoreMatcher := ignore.NewMatcher(ignore.FindRoot(absoluteRoot))
+	treeHash := sha256.New()
+
+	if walkError := filepath.WalkDir(absoluteRoot, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if respectIgnoreFiles && path != absoluteRoot && ignoreMatcher.IsIgnored(path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		return writeEntry(treeHash, absoluteRoot, path, entry)
+	}); walkError != nil {
+		return "", errorx.ExternalError.Wrap(walkError, "Failed to hash %s", absoluteRoot)
+	}
+
+	return hex.EncodeToString(treeHash.Sum(nil)), nil
+}
+
+func writeEntry(treeHash hash.Hash, root string, path string, entry fs.DirEntry) error {
+	relativePath, relError := filepath.Rel(root, path)
+	if relError != nil {
+		return errorx.InternalError.Wrap(relError, "Failed to get relative path of %s", path)
+	}
+
+	relativePath = filepath.ToSlash(relativePath)
+
+	info, infoError := entry.Info()
+	if infoError != nil {
+		return errorx.ExternalError.Wrap(infoError, "Failed to get file info of %s", path)
+	}
+
+	switch {
+	case entry.IsDir():
+		_, _ = fmt.Fprintf(treeHash, "d %s\n", relativePath)
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, readLinkError := os.Readlink(path)
+		if readLinkError != nil {
+			return errorx.ExternalError.Wrap(readLinkError, "Failed to read link %s", path)
+		}
+
+		_, _ = fmt.Fprintf(treeHash, "l %s %s\n", relativePath, target)
+	case info.Mode().IsRegular():
+		contentHash, hashError := HashFile(path)
+		if hashError != nil {
+			return hashError
+		}
+
+		_, _ = fmt.Fprintf(treeHash, "f %s %o %s\n", relativePath, info.Mode().Perm(), contentHash)
+	}
+
+	return nil
+}
+
+func writeFileContent(writer io.Writer, path string) error {
+	file, openError := os.Open(path)
+	if openError != nil {
+		return errorx.ExternalError.Wrap(openError, "Failed to open %s", path)
+	}
+
+	defer file.Close()
+
+	if _, err := io.Copy(writer, file); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to read %s", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package treehash_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	uut "chast.io/core/internal/internal_util/tree_hash"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Error creating folder: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+func hashTree(t *testing.T, root string) string {
+	t.Helper()
+
+	treeHash, err := uut.HashTree(root)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	return treeHash
+}
+
+func TestHashTree(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".gitignore"), "build/\n")
+	writeFile(t, filepath.Join(root, "src", "Main.java"), "class Main {}")
+
+	initialHash := hashTree(t, root)
+
+	if rehashed := hashTree(t, root); rehashed != initialHash {
+		t.Errorf("Expected hash of unchanged tree to be %s, but was %s", initialHash, rehashed)
+	}
+
+	writeFile(t, filepath.Join(root, "build", "Main.class"), "binary")
+
+	if ignoredChangeHash := hashTree(t, root); ignoredChangeHash != initialHash {
+		t.Errorf("Expected ignored paths not to change the hash, but was %s instead of %s", ignoredChangeHash, initialHash)
+	}
+
+	writeFile(t, filepath.Join(root, "src", "Main.java"), "class Main { }")
+
+	if changedHash := hashTree(t, root); changedHash == initialHash {
+		t.Error("Expected changed content to change the hash, but it was equal")
+	}
+}
+
+func TestHashTree_RenamedFile(t *testing.T) {
+	t.Parallel()
+
+	first := t.TempDir()
+	writeFile(t, filepath.Join(first, "A.java"), "content")
+
+	second := t.TempDir()
+	writeFile(t, filepath.Join(second, "B.java"), "content")
+
+	if hashTree(t, first) == hashTree(t, second) {
+		t.Error("Expected renamed file to change the hash, but it was equal")
+	}
+}
@@ -2,14 +2,22 @@ package dependencygraph
 
 import (
 	"chast.io/core/internal/internal_util/graph"
+	runselection "chast.io/core/internal/pipeline/pkg/run_selection"
 	"chast.io/core/internal/run_model/pkg/model/refactoring"
 	"github.com/joomcode/errorx"
 )
 
-func BuildExecutionOrder(runModel *refactoring.RunModel) ([][]*refactoring.Run, error) {
+// BuildExecutionOrder groups the selected runs, so each group only depends on the runs of earlier groups.
+func BuildExecutionOrder(
+	runModel *refactoring.RunModel,
+	selection *runselection.Selection,
+) ([][]*refactoring.Run, error) {
 	executionOrder := make([][]*refactoring.Run, 0)
 
-	dependencyGraph := buildDependencyGraph(runModel)
+	dependencyGraph, dependencyGraphBuildError := buildDependencyGraph(runModel)
+	if dependencyGraphBuildError != nil {
+		return nil, dependencyGraphBuildError
+	}
 
 	if dependencyGraph.HasCycles() {
 		return nil, errorx.InternalError.New("Cyclic dependency detected")
@@ -42,10 +50,14 @@ func BuildExecutionOrder(runModel *refactoring.RunModel) ([][]*refactoring.Run,
 		executionOrder = append(executionOrder, level)
 	}
 
-	return executionOrder, nil
+	if selection.IsEmpty() {
+		return executionOrder, nil
+	}
+
+	return selectRuns(executionOrder, selection)
 }
 
-func buildDependencyGraph(runModel *refactoring.RunModel) *graph.DoubleConnectedGraph[*refactoring.Run] {
+func buildDependencyGraph(runModel *refactoring.RunModel) (*graph.DoubleConnectedGraph[*refactoring.Run], error) {
 	nodesMap := make(map[*refactoring.Run]*graph.Node[*refactoring.Run])
 
 	runGraph := graph.NewDoubleConnectedGraph[*refactoring.Run]()
@@ -60,14 +72,14 @@ func buildDependencyGraph(runModel *refactoring.RunModel) *graph.DoubleConnected
 		for _, dependency := range node.Self.Dependencies {
 			dependencyNode := nodesMap[dependency]
 			if dependencyNode == nil {
-				runGraph.RemoveNode(node)
-				// this can happen if the dependency is a run that is not part of the run model due to a filter
-				break
+				// dependencies on runs which are not selected are resolved while building the run model
+				return nil, errorx.InternalError.New("Run '%s' depends on a run which is not part of the run model",
+					node.Self.ID)
 			}
 
 			runGraph.AddEdge(node, dependencyNode)
 		}
 	}
 
-	return runGraph
+	return runGraph, nil
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	uut "chast.io/core/internal/pipeline/internal/dependency_graph"
+	runselection "chast.io/core/internal/pipeline/pkg/run_selection"
 	"chast.io/core/internal/run_model/pkg/model/refactoring"
 )
 
@@ -45,7 +46,7 @@ func TestBuildExecutionOrder_SingleRun(t *testing.T) {
 
 	runModel1 := dependencyGraphDummyRunModelWithSingleRun()
 
-	executionOrder, _ := uut.BuildExecutionOrder(runModel1)
+	executionOrder, _ := uut.BuildExecutionOrder(runModel1, runselection.NewSelection())
 
 	t.Run("should return a single stage", func(t *testing.T) {
 		t.Parallel()
@@ -131,7 +132,7 @@ func TestBuildExecutionOrder_MultipleRuns_WithStages(t *testing.T) {
 		},
 	}
 
-	executionOrder, _ := uut.BuildExecutionOrder(runModel)
+	executionOrder, _ := uut.BuildExecutionOrder(runModel, runselection.NewSelection())
 
 	t.Run("should set stages", func(t *testing.T) {
 		t.Parallel()
@@ -252,26 +253,12 @@ func TestBuildExecutionOrder_MissingDependencies(t *testing.T) {
 		},
 	}
 
-	executionOrder, _ := uut.BuildExecutionOrder(runModel)
+	_, err := uut.BuildExecutionOrder(runModel, runselection.NewSelection())
 
-	t.Run("should set stages", func(t *testing.T) {
-		t.Parallel()
-		if len(executionOrder) != 1 {
-			t.Fatalf("expected execution order to contain 1 stages but was %d", len(executionOrder))
-		}
-	})
-
-	t.Run("should set stage 1", func(t *testing.T) {
-		t.Parallel()
-		if len(executionOrder[0]) != 1 {
-			t.Fatalf("expected execution order to contain 1 run in stage 1 but was %d", len(executionOrder[0]))
-		}
-	})
-
-	t.Run("should set stage 1 step 1", func(t *testing.T) {
+	t.Run("should return error", func(t *testing.T) {
 		t.Parallel()
-		if executionOrder[0][0] != runIndependent {
-			t.Errorf("expected execution order to contain runIndependent in stage 1 step 1 but was %s", executionOrder[0][0].ID)
+		if err == nil {
+			t.Fatal("expected error for dependency on run which is not part of the run model but was nil")
 		}
 	})
 }
@@ -349,7 +336,7 @@ func TestBuildExecutionOrder_CyclicDependencyDetection(t *testing.T) {
 		},
 	}
 
-	_, err := uut.BuildExecutionOrder(runModel)
+	_, err := uut.BuildExecutionOrder(runModel, runselection.NewSelection())
 
 	if err == nil {
 		t.Error("expected error to be returned but was nil")
@@ -0,0 +1,144 @@
+package dependencygraph
+
+import (
+	"sort"
+	"strings"
+
+	runselection "chast.io/core/internal/pipeline/pkg/run_selection"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+// selectRuns removes all runs from the execution order which are not selected. Dependencies of selected runs are
+// always selected, so the pruned execution order is still complete.
+func selectRuns(executionOrder [][]*refactoring.Run, selection *runselection.Selection) ([][]*refactoring.Run, error) {
+	runsByID := make(map[string][]*refactoring.Run)
+	executionGroupOfRun := make(map[*refactoring.Run]int)
+
+	for executionGroup, runs := range executionOrder {
+		for _, run := range runs {
+			runsByID[run.ID] = append(runsByID[run.ID], run)
+			executionGroupOfRun[run] = executionGroup
+		}
+	}
+
+	lookup := func(runID string) ([]*refactoring.Run, error) {
+		runs, found := runsByID[runID]
+		if !found {
+			return nil, errorx.IllegalArgument.New("No run with id '%s', available runs are: %s",
+				runID, strings.Join(sortedRunIDs(runsByID), ", "))
+		}
+
+		return runs, nil
+	}
+
+	selected := make(map[*refactoring.Run]bool)
+
+	for _, runID := range selection.Only {
+		runs, lookupError := lookup(runID)
+		if lookupError != nil {
+			return nil, lookupError
+		}
+
+		for _, run := range runs {
+			selectWithDependencies(run, selected)
+		}
+	}
+
+	if selection.Until != "" {
+		runs, lookupError := lookup(selection.Until)
+		if lookupError != nil {
+			return nil, lookupError
+		}
+
+		lastExecutionGroup := 0
+
+		for _, run := range runs {
+			selectWithDependencies(run, selected)
+
+			if executionGroupOfRun[run] > lastExecutionGroup {
+				lastExecutionGroup = executionGroupOfRun[run]
+			}
+		}
+
+		for _, runs := range executionOrder[:lastExecutionGroup] {
+			for _, run := range runs {
+				selected[run] = true
+			}
+		}
+	}
+
+	if len(selection.Only) == 0 && selection.Until == "" {
+		for run := range executionGroupOfRun {
+			selected[run] = true
+		}
+	}
+
+	for _, runID := range selection.Skip {
+		runs, lookupError := lookup(runID)
+		if lookupError != nil {
+			return nil, lookupError
+		}
+
+		for _, run := range runs {
+			delete(selected, run)
+		}
+	}
+
+	for run := range selected {
+		for _, dependency := range run.Dependencies {
+			if !selected[dependency] {
+				return nil, errorx.IllegalArgument.New("Run '%s' can not be skipped, run '%s' depends on it",
+					dependency.ID, run.ID)
+			}
+		}
+	}
+
+	return pruneExecutionOrder(executionOrder, selected), nil
+}
+
+func selectWithDependencies(run *refactoring.Run, selected map[*refactoring.Run]bool) {
+	if selected[run] {
+		return
+	}
+
+	selected[run] = true
+
+	for _, dependency := range run.Dependencies {
+		selectWithDependencies(dependency, selected)
+	}
+}
+
+func pruneExecutionOrder(
+	executionOrder [][]*refactoring.Run,
+	selected map[*refactoring.Run]bool,
+) [][]*refactoring.Run {
+	prunedExecutionOrder := make([][]*refactoring.Run, 0, len(executionOrder))
+
+	for _, runs := range executionOrder {
+		prunedRuns := make([]*refactoring.Run, 0, len(runs))
+
+		for _, run := range runs {
+			if selected[run] {
+				prunedRuns = append(prunedRuns, run)
+			}
+		}
+
+		if len(prunedRuns) > 0 {
+			prunedExecutionOrder = append(prunedExecutionOrder, prunedRuns)
+		}
+	}
+
+	return prunedExecutionOrder
+}
+
+func sortedRunIDs(runsByID map[string][]*refactoring.Run) []string {
+	runIDs := make([]string, 0, len(runsByID))
+	for runID := range runsByID {
+		runIDs = append(runIDs, runID)
+	}
+
+	sort.Strings(runIDs)
+
+	return runIDs
+}
@@ -0,0 +1,128 @@
+package dependencygraph_test
+
+import (
+	"reflect"
+	"testing"
+
+	uut "chast.io/core/internal/pipeline/internal/dependency_graph"
+	runselection "chast.io/core/internal/pipeline/pkg/run_selection"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+)
+
+// selectionDummyRunModel returns the runs format -> rearrange -> mv and the independent run lint.
+func selectionDummyRunModel() *refactoring.RunModel {
+	format := &refactoring.Run{ID: "format"} //nolint:exhaustruct // not required for test
+	lint := &refactoring.Run{ID: "lint"}     //nolint:exhaustruct // not required for test
+	rearrange := &refactoring.Run{           //nolint:exhaustruct // not required for test
+		ID:           "rearrange",
+		Dependencies: []*refactoring.Run{format},
+	}
+	mv := &refactoring.Run{ //nolint:exhaustruct // not required for test
+		ID:           "mv",
+		Dependencies: []*refactoring.Run{rearrange},
+	}
+
+	return &refactoring.RunModel{ //nolint:exhaustruct // not required for test
+		Run: []*refactoring.Run{format, lint, rearrange, mv},
+	}
+}
+
+func executionOrderIDs(executionOrder [][]*refactoring.Run) [][]string {
+	ids := make([][]string, 0, len(executionOrder))
+
+	for _, runs := range executionOrder {
+		runIDs := make(map[string]bool)
+		for _, run := range runs {
+			runIDs[run.ID] = true
+		}
+
+		sortedIDs := make([]string, 0, len(runs))
+		for _, runID := range []string{"format", "lint", "rearrange", "mv"} {
+			if runIDs[runID] {
+				sortedIDs = append(sortedIDs, runID)
+			}
+		}
+
+		ids = append(ids, sortedIDs)
+	}
+
+	return ids
+}
+
+func TestBuildExecutionOrder_Selection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		selection *runselection.Selection
+		expected  [][]string
+	}{
+		{
+			name:      "all runs",
+			selection: runselection.NewSelection(),
+			expected:  [][]string{{"format", "lint"}, {"rearrange"}, {"mv"}},
+		},
+		{
+			name:      "only pulls in dependencies",
+			selection: &runselection.Selection{Only: []string{"rearrange"}, Until: "", Skip: nil},
+			expected:  [][]string{{"format"}, {"rearrange"}},
+		},
+		{
+			name:      "until includes earlier execution groups",
+			selection: &runselection.Selection{Only: nil, Until: "rearrange", Skip: nil},
+			expected:  [][]string{{"format", "lint"}, {"rearrange"}},
+		},
+		{
+			name:      "skip removes runs",
+			selection: &runselection.Selection{Only: nil, Until: "", Skip: []string{"lint", "mv"}},
+			expected:  [][]string{{"format"}, {"rearrange"}},
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			executionOrder, err := uut.BuildExecutionOrder(selectionDummyRunModel(), testCase.selection)
+			if err != nil {
+				t.Fatalf("Expected no error, but was %v", err)
+			}
+
+			if ids := executionOrderIDs(executionOrder); !reflect.DeepEqual(ids, testCase.expected) {
+				t.Errorf("Expected execution order to be %v, but was %v", testCase.expected, ids)
+			}
+		})
+	}
+}
+
+func TestBuildExecutionOrder_InvalidSelection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		selection *runselection.Selection
+	}{
+		{
+			name:      "unknown run",
+			selection: &runselection.Selection{Only: []string{"unknown"}, Until: "", Skip: nil},
+		},
+		{
+			name:      "skipped dependency",
+			selection: &runselection.Selection{Only: []string{"mv"}, Until: "", Skip: []string{"format"}},
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := uut.BuildExecutionOrder(selectionDummyRunModel(), testCase.selection); err == nil {
+				t.Errorf("Expected an error, but was nil")
+			}
+		})
+	}
+}
@@ -4,20 +4,69 @@ import (
 	"chast.io/core/internal/internal_util/collection"
 	dependencygraph "chast.io/core/internal/pipeline/internal/dependency_graph"
 	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	runselection "chast.io/core/internal/pipeline/pkg/run_selection"
+	"chast.io/core/internal/pipeline/pkg/workspace"
 	refactoringRunModelIsolator "chast.io/core/internal/run_model/pkg/isolator/refactoring"
 	"chast.io/core/internal/run_model/pkg/model/refactoring"
 	"github.com/joomcode/errorx"
 )
 
-func BuildRunPipeline(runModel *refactoring.RunModel) (*refactoringpipelinemodel.Pipeline, error) {
+func BuildRunPipeline(
+	runModel *refactoring.RunModel,
+	locations *workspace.Locations,
+) (*refactoringpipelinemodel.Pipeline, error) {
+	return BuildSelectedRunPipeline(runModel, locations, runselection.NewSelection())
+}
+
+// BuildSelectedRunPipeline builds a pipeline of the selected runs only. The pipeline is partial if not all runs are
+// selected.
+func BuildSelectedRunPipeline(
+	runModel *refactoring.RunModel,
+	locations *workspace.Locations,
+	selection *runselection.Selection,
+) (*refactoringpipelinemodel.Pipeline, error) {
+	pipeline := refactoringpipelinemodel.NewPipeline(
+		locations.OperationLocation,
+		locations.ChangeCaptureLocation,
+		locations.RootFileSystemLocation,
+	)
+	pipeline.Partial = !selection.IsEmpty()
+
+	return buildRunPipeline(runModel, pipeline, selection)
+}
+
+// RestoreRunPipeline builds the pipeline with the uuid of a pipeline built before from the same run model.
+func RestoreRunPipeline(
+	runModel *refactoring.RunModel,
+	locations *workspace.Locations,
+	pipelineUUID string,
+) (*refactoringpipelinemodel.Pipeline, error) {
+	return buildRunPipeline(runModel, refactoringpipelinemodel.RestorePipeline(
+		pipelineUUID,
+		locations.OperationLocation,
+		locations.ChangeCaptureLocation,
+		locations.RootFileSystemLocation,
+	), runselection.NewSelection())
+}
+
+func buildRunPipeline(
+	runModel *refactoring.RunModel,
+	pipeline *refactoringpipelinemodel.Pipeline,
+	selection *runselection.Selection,
+) (*refactoringpipelinemodel.Pipeline, error) {
 	// TODO verify id uniqueness
-	isolatedExecutionOrder, isolatedExecutionOrderBuildError := buildIsolatedExecutionOrder(runModel)
+	isolatedExecutionOrder, isolatedExecutionOrderBuildError := buildIsolatedExecutionOrder(runModel, selection)
 	if isolatedExecutionOrderBuildError != nil {
 		return nil, errorx.InternalError.Wrap(isolatedExecutionOrderBuildError, "failed to build isolated execution order")
 	}
 
-	// TODO make configurable
-	pipeline := refactoringpipelinemodel.NewPipeline("/tmp/chast/", "/tmp/chast-changes/", "/")
+	if runModel.ClassificationStats != nil {
+		pipeline.ClassificationStats = runModel.ClassificationStats
+	}
+
+	if runModel.DependencyDecisions != nil {
+		pipeline.DependencyDecisions = runModel.DependencyDecisions
+	}
 
 	stepsLookup := make(map[*refactoring.Run]*refactoringpipelinemodel.Step)
 
@@ -43,8 +92,9 @@ func BuildRunPipeline(runModel *refactoring.RunModel) (*refactoringpipelinemodel
 
 func buildIsolatedExecutionOrder(
 	runModel *refactoring.RunModel,
+	selection *runselection.Selection,
 ) ([][]*refactoring.SingleRunModel, error) {
-	executionOrder, executionOrderBuildError := dependencygraph.BuildExecutionOrder(runModel)
+	executionOrder, executionOrderBuildError := dependencygraph.BuildExecutionOrder(runModel, selection)
 	if executionOrderBuildError != nil {
 		return nil, errorx.InternalError.Wrap(executionOrderBuildError, "failed to build execution order")
 	}
@@ -6,6 +6,7 @@ import (
 
 	chastlog "chast.io/core/internal/logger"
 	uut "chast.io/core/internal/pipeline/pkg/builder/refactoring"
+	"chast.io/core/internal/pipeline/pkg/workspace"
 	"chast.io/core/internal/run_model/pkg/model/refactoring"
 )
 
@@ -40,7 +41,7 @@ func BenchmarkBuildExecutionOrder(b *testing.B) {
 	logLevel := chastlog.Log.GetLevel()
 	chastlog.Log.SetLevel(chastlog.FatalLevel)
 
-	_, _ = uut.BuildRunPipeline(runModel)
+	_, _ = uut.BuildRunPipeline(runModel, workspace.NewLocations("/tmp/chast"))
 
 	chastlog.Log.SetLevel(logLevel)
 }
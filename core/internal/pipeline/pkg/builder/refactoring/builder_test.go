@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	uut "chast.io/core/internal/pipeline/pkg/builder/refactoring"
+	"chast.io/core/internal/pipeline/pkg/workspace"
 	"chast.io/core/internal/run_model/pkg/model/refactoring"
 )
 
@@ -45,7 +46,15 @@ func TestBuildRunPipeline_SingleRun(t *testing.T) {
 
 	runModel1 := builderDummyRunModelWithSingleRun()
 
-	actualPipeline, _ := uut.BuildRunPipeline(runModel1)
+	locations := &workspace.Locations{
+		OperationLocation:      "/tmp/chast",
+		ChangeCaptureLocation:  "/tmp/chast-changes/",
+		RootFileSystemLocation: "/",
+		CacheLocation:          "",
+		MinimumFreeSpace:       0,
+	}
+
+	actualPipeline, _ := uut.BuildRunPipeline(runModel1, locations)
 
 	t.Run("should set UUID", func(t *testing.T) {
 		t.Parallel()
@@ -155,7 +164,7 @@ func TestBuildRunPipeline_MultipleRuns_WithExecutionGroups(t *testing.T) {
 		},
 	}
 
-	actualPipeline, _ := uut.BuildRunPipeline(runModel)
+	actualPipeline, _ := uut.BuildRunPipeline(runModel, workspace.NewLocations("/tmp/chast"))
 
 	t.Run("should set execution groups", func(t *testing.T) {
 		t.Parallel()
@@ -325,7 +334,7 @@ func TestBuildExecutionOrder_CyclicDependencyDetection(t *testing.T) {
 		},
 	}
 
-	_, err := uut.BuildRunPipeline(runModel)
+	_, err := uut.BuildRunPipeline(runModel, workspace.NewLocations("/tmp/chast"))
 
 	if err == nil {
 		t.Error("expected error to be returned but was nil")
@@ -0,0 +1,79 @@
+package event
+
+import (
+	"time"
+
+	"chast.io/core/internal/pipeline/pkg/metrics"
+)
+
+type Type = string
+
+const (
+	PipelineStarted  Type = "pipelineStarted"
+	PipelineFinished Type = "pipelineFinished"
+	StepQueued       Type = "stepQueued"
+	StepStarted      Type = "stepStarted"
+	StepFinished     Type = "stepFinished"
+	// StepRetrying is notified after an attempt of a step failed and before it is attempted again.
+	StepRetrying           Type = "stepRetrying"
+	PostProcessingStarted  Type = "postProcessingStarted"
+	PostProcessingFinished Type = "postProcessingFinished"
+	MergeConflict          Type = "mergeConflict"
+	ChangesApplied         Type = "changesApplied"
+	// ReportBuilt is notified after the report of the changes of a pipeline was built.
+	ReportBuilt Type = "reportBuilt"
+)
+
+type Status = string
+
+const (
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	// Cached is the status of steps whose changes were restored from the cache instead of running them.
+	Cached Status = "cached"
+)
+
+// Event describes a change in the lifecycle of a pipeline. Only the fields relevant for the type are set.
+type Event struct {
+	Type         Type      `json:"type"`
+	Time         time.Time `json:"time"`
+	PipelineUUID string    `json:"pipelineUuid"`
+	StepUUID     string    `json:"stepUuid,omitempty"`
+	RunID        string    `json:"runId,omitempty"`
+	Status       Status    `json:"status,omitempty"`
+	// ExitCode is the exit code of the isolated process of a finished step, nil if it is unknown.
+	ExitCode       *int     `json:"exitCode,omitempty"`
+	DurationMillis int64    `json:"durationMs,omitempty"`
+	Error          string   `json:"error,omitempty"`
+	Steps          int      `json:"steps,omitempty"`
+	Path           string   `json:"path,omitempty"`
+	ConflictSteps  []string `json:"conflictSteps,omitempty"`
+	ChangedPaths   int      `json:"changedPaths,omitempty"`
+	// LogFile is the file the output of the commands of a finished step is logged to.
+	LogFile string `json:"logFile,omitempty"`
+	// Attempt is the number of the attempt of a retrying step which is started next, starting with 1.
+	Attempt int `json:"attempt,omitempty"`
+	// Timings are the wall times of the phases of a finished step, of the post processing or of building the report.
+	Timings []metrics.PhaseTiming `json:"timings,omitempty"`
+	// ResourceUsage describes the resources used by the processes of a finished step.
+	ResourceUsage *metrics.ResourceUsage `json:"resourceUsage,omitempty"`
+}
+
+// Observer is notified about the events of the pipelines it is added to. Events of a pipeline may be notified
+// concurrently.
+type Observer interface {
+	Notify(event Event)
+}
+
+func (event Event) Duration() time.Duration {
+	return time.Duration(event.DurationMillis) * time.Millisecond
+}
+
+// ErrorMessage returns the message of the error, or an empty message if there is no error.
+func ErrorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
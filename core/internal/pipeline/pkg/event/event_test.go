@@ -0,0 +1,91 @@
+package event_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	uut "chast.io/core/internal/pipeline/pkg/event"
+)
+
+func TestJSONLinesObserver_Notify(t *testing.T) {
+	t.Parallel()
+
+	var output bytes.Buffer
+
+	observer := uut.NewJSONLinesObserver(&output)
+	exitCode := 2
+
+	observer.Notify(uut.Event{ //nolint:exhaustruct // not required for test
+		Type:         uut.StepStarted,
+		PipelineUUID: "PIPELINE-1",
+		StepUUID:     "step-1",
+	})
+	observer.Notify(uut.Event{ //nolint:exhaustruct // not required for test
+		Type:           uut.StepFinished,
+		PipelineUUID:   "PIPELINE-1",
+		StepUUID:       "step-1",
+		Status:         uut.Failed,
+		ExitCode:       &exitCode,
+		DurationMillis: 1500,
+	})
+
+	events := make([]uut.Event, 0)
+	scanner := bufio.NewScanner(&output)
+
+	for scanner.Scan() {
+		var event uut.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Expected every line to be an event, but was '%s': %v", scanner.Text(), err)
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, but was %d", len(events))
+	}
+
+	if events[0].Type != uut.StepStarted || events[0].ExitCode != nil {
+		t.Errorf("Expected started event without exit code, but was %+v", events[0])
+	}
+
+	if events[1].ExitCode == nil || *events[1].ExitCode != exitCode {
+		t.Errorf("Expected exit code %d, but was %v", exitCode, events[1].ExitCode)
+	}
+
+	if events[1].Duration().Milliseconds() != 1500 {
+		t.Errorf("Expected duration to be 1.5s, but was %s", events[1].Duration())
+	}
+}
+
+func TestProgressObserver_Notify(t *testing.T) {
+	t.Parallel()
+
+	var output bytes.Buffer
+
+	observer := uut.NewProgressObserver(&output)
+
+	for _, runID := range []string{"format", "lint"} {
+		observer.Notify(uut.Event{Type: uut.StepQueued, RunID: runID}) //nolint:exhaustruct // not required for test
+	}
+
+	observer.Notify(uut.Event{Type: uut.StepFinished, RunID: "format", Status: uut.Cached}) //nolint:exhaustruct,lll // not required for test
+	observer.Notify(uut.Event{Type: uut.StepFinished, RunID: "lint", Status: uut.Failed})   //nolint:exhaustruct,lll // not required for test
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, but was %d: %v", len(lines), lines)
+	}
+
+	if !strings.HasPrefix(lines[0], "[1/2]") || !strings.Contains(lines[0], "format") {
+		t.Errorf("Expected first line to show format as step 1 of 2, but was '%s'", lines[0])
+	}
+
+	if !strings.HasPrefix(lines[1], "[2/2]") || !strings.Contains(lines[1], "lint") {
+		t.Errorf("Expected second line to show lint as step 2 of 2, but was '%s'", lines[1])
+	}
+}
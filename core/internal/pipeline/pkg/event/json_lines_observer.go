@@ -0,0 +1,38 @@
+package event
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	chastlog "chast.io/core/internal/logger"
+)
+
+// JSONLinesObserver writes each event as a single line of JSON.
+type JSONLinesObserver struct {
+	writer io.Writer
+	lock   sync.Mutex
+}
+
+func NewJSONLinesObserver(writer io.Writer) *JSONLinesObserver {
+	return &JSONLinesObserver{
+		writer: writer,
+		lock:   sync.Mutex{},
+	}
+}
+
+func (observer *JSONLinesObserver) Notify(event Event) {
+	line, marshalError := json.Marshal(event)
+	if marshalError != nil {
+		chastlog.Log.Errorf("Failed to serialize event %s: %v", event.Type, marshalError)
+
+		return
+	}
+
+	observer.lock.Lock()
+	defer observer.lock.Unlock()
+
+	if _, err := observer.writer.Write(append(line, '\n')); err != nil {
+		chastlog.Log.Debugf("Failed to write event %s: %v", event.Type, err)
+	}
+}
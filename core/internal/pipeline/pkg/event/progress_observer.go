@@ -0,0 +1,81 @@
+package event
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ttacon/chalk"
+)
+
+// ProgressObserver renders the progress of the steps as one line per finished step.
+type ProgressObserver struct {
+	writer        io.Writer
+	lock          sync.Mutex
+	queuedSteps   int
+	finishedSteps int
+}
+
+func NewProgressObserver(writer io.Writer) *ProgressObserver {
+	return &ProgressObserver{
+		writer:        writer,
+		lock:          sync.Mutex{},
+		queuedSteps:   0,
+		finishedSteps: 0,
+	}
+}
+
+func (observer *ProgressObserver) Notify(event Event) {
+	observer.lock.Lock()
+	defer observer.lock.Unlock()
+
+	switch event.Type {
+	case StepQueued:
+		observer.queuedSteps++
+	case StepStarted:
+		observer.printf("[%d/%d] %s %s", observer.finishedSteps+1, observer.queuedSteps, chalk.Blue.Color("▶"), stepName(event))
+	case StepFinished:
+		observer.finishedSteps++
+		observer.printf("[%d/%d] %s %s (%s)",
+			observer.finishedSteps, observer.queuedSteps, statusSymbol(event.Status), stepName(event), event.Duration())
+	case StepRetrying:
+		observer.printf("[%d/%d] %s %s attempt %d after: %s",
+			observer.finishedSteps+1, observer.queuedSteps, chalk.Yellow.Color("↻"), stepName(event), event.Attempt,
+			event.Error)
+	case PostProcessingStarted:
+		observer.printf("Merging changes of all steps")
+	case MergeConflict:
+		observer.printf("%s %s is changed by %v", chalk.Red.Color("Conflict:"), event.Path, event.ConflictSteps)
+	case PipelineFinished:
+		if event.Status == Failed {
+			observer.printf("%s Pipeline failed after %s: %s", chalk.Red.Color("✗"), event.Duration(), event.Error)
+		} else {
+			observer.printf("%s Pipeline finished in %s", chalk.Green.Color("✓"), event.Duration())
+		}
+	case ChangesApplied:
+		observer.printf("Applied %d changed paths", event.ChangedPaths)
+	}
+}
+
+func (observer *ProgressObserver) printf(format string, arguments ...interface{}) {
+	_, _ = fmt.Fprintf(observer.writer, format+"\n", arguments...)
+}
+
+func stepName(event Event) string {
+	if event.RunID == "" {
+		return event.StepUUID
+	}
+
+	return event.RunID
+}
+
+func statusSymbol(status Status) string {
+	switch status {
+	case Failed:
+		return chalk.Red.Color("✗")
+	case Cached:
+		return chalk.Cyan.Color("↺")
+	default:
+		return chalk.Green.Color("✓")
+	}
+}
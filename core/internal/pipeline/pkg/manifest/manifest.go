@@ -0,0 +1,281 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	chastlog "chast.io/core/internal/logger"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"chast.io/core/internal/pipeline/pkg/workspace"
+	refactoringplan "chast.io/core/internal/plan/pkg/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+// Version is increased whenever the manifest format changes incompatibly.
+const Version = 1
+
+const manifestFilePermission = 0o644
+
+type Status = string
+
+const (
+	Pending   Status = "pending"
+	Running   Status = "running"
+	Failed    Status = "failed"
+	Completed Status = "completed"
+	// Unknown is the status of pipeline folders without a readable manifest.
+	Unknown Status = "unknown"
+)
+
+// Manifest is the persisted state of a pipeline, which allows to resume it after a step failed.
+type Manifest struct {
+	Version                int                   `json:"version"`
+	PipelineUUID           string                `json:"pipelineUuid"`
+	Status                 Status                `json:"status"`
+	Error                  string                `json:"error,omitempty"`
+	CreatedAt              time.Time             `json:"createdAt"`
+	UpdatedAt              time.Time             `json:"updatedAt"`
+	OperationLocation      string                `json:"operationLocation"`
+	ChangeCaptureLocation  string                `json:"changeCaptureLocation"`
+	RootFileSystemLocation string                `json:"rootFileSystemLocation"`
+	Plan                   *refactoringplan.Plan `json:"plan,omitempty"`
+	Steps                  []*StepState          `json:"steps"`
+
+	file string
+}
+
+type StepState struct {
+	UUID                  string `json:"uuid"`
+	RunID                 string `json:"runId,omitempty"`
+	Status                Status `json:"status"`
+	Error                 string `json:"error,omitempty"`
+	ChangeCaptureLocation string `json:"changeCaptureLocation"`
+	FinalChangesLocation  string `json:"finalChangesLocation"`
+	// Inputs are the change capture locations of all previous steps the step is isolated on.
+	Inputs []string `json:"inputs,omitempty"`
+	// LogFile is the file the output of the commands of the step is logged to.
+	LogFile string `json:"logFile,omitempty"`
+}
+
+// New creates the manifest of the pipeline, in which all steps are pending.
+func New(pipeline *refactoringpipelinemodel.Pipeline, plan *refactoringplan.Plan) *Manifest {
+	steps := make([]*StepState, 0)
+
+	for _, executionGroup := range pipeline.ExecutionGroups {
+		if executionGroup == nil {
+			continue
+		}
+
+		for _, step := range executionGroup.Steps {
+			steps = append(steps, &StepState{
+				UUID:                  step.UUID,
+				RunID:                 step.RunModel.Run.ID,
+				Status:                Pending,
+				Error:                 "",
+				ChangeCaptureLocation: step.ChangeCaptureLocation,
+				FinalChangesLocation:  step.GetFinalChangesLocation(),
+				Inputs:                step.GetPreviousChangeCaptureLocations(),
+				LogFile:               step.GetLogFile(),
+			})
+		}
+	}
+
+	now := time.Now()
+
+	return &Manifest{
+		Version:                Version,
+		PipelineUUID:           pipeline.UUID,
+		Status:                 Pending,
+		Error:                  "",
+		CreatedAt:              now,
+		UpdatedAt:              now,
+		OperationLocation:      pipeline.OperationLocation,
+		ChangeCaptureLocation:  filepath.Dir(pipeline.ChangeCaptureLocation),
+		RootFileSystemLocation: pipeline.RootFileSystemLocation,
+		Plan:                   plan,
+		Steps:                  steps,
+		file:                   pipeline.GetManifestFile(),
+	}
+}
+
+// Read reads the manifest of the pipeline with the uuid in the change capture location.
+func Read(changeCaptureLocation string, pipelineUUID string) (*Manifest, error) {
+	file := refactoringpipelinemodel.ManifestFile(changeCaptureLocation, pipelineUUID)
+
+	content, readError := os.ReadFile(file)
+	if readError != nil {
+		if os.IsNotExist(readError) {
+			return nil, errorx.DataUnavailable.New("No pipeline %s in %s", pipelineUUID, changeCaptureLocation)
+		}
+
+		return nil, errorx.ExternalError.Wrap(readError, "Failed to read manifest %s", file)
+	}
+
+	var manifest Manifest
+
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, errorx.IllegalFormat.Wrap(err, "Failed to parse manifest %s", file)
+	}
+
+	if manifest.Version != Version {
+		return nil, errorx.IllegalFormat.New("Unsupported manifest version %d, expected %d", manifest.Version, Version)
+	}
+
+	manifest.file = file
+
+	return &manifest, nil
+}
+
+// List returns the manifests of all pipelines in the change capture location, ordered by their creation.
+// Pipeline folders without a readable manifest are listed with the status Unknown.
+func List(changeCaptureLocation string) ([]*Manifest, error) {
+	entries, readError := os.ReadDir(changeCaptureLocation)
+	if readError != nil {
+		if os.IsNotExist(readError) {
+			return make([]*Manifest, 0), nil
+		}
+
+		return nil, errorx.ExternalError.Wrap(readError, "Failed to list pipelines in %s", changeCaptureLocation)
+	}
+
+	manifests := make([]*Manifest, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), refactoringpipelinemodel.UUIDPrefix) {
+			continue
+		}
+
+		manifest, manifestReadError := Read(changeCaptureLocation, entry.Name())
+		if manifestReadError != nil {
+			chastlog.Log.Debugf("Pipeline %s has no readable manifest: %v", entry.Name(), manifestReadError)
+
+			manifest = &Manifest{ //nolint:exhaustruct // nothing else is known about the pipeline
+				PipelineUUID:          entry.Name(),
+				Status:                Unknown,
+				ChangeCaptureLocation: changeCaptureLocation,
+				Steps:                 make([]*StepState, 0),
+			}
+
+			if info, infoError := entry.Info(); infoError == nil {
+				manifest.CreatedAt = info.ModTime()
+				manifest.UpdatedAt = info.ModTime()
+			}
+		}
+
+		manifests = append(manifests, manifest)
+	}
+
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifests[i].CreatedAt.Before(manifests[j].CreatedAt)
+	})
+
+	return manifests, nil
+}
+
+// Save writes the manifest atomically, so an interrupted invocation never leaves a partially written manifest.
+func (manifest *Manifest) Save() error {
+	manifest.UpdatedAt = time.Now()
+
+	content, marshalError := json.MarshalIndent(manifest, "", "  ")
+	if marshalError != nil {
+		return errorx.InternalError.Wrap(marshalError, "Failed to serialize manifest")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(manifest.file), os.ModePerm); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to create folder of manifest %s", manifest.file)
+	}
+
+	temporaryFile := manifest.file + ".tmp"
+	if err := os.WriteFile(temporaryFile, content, manifestFilePermission); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to write manifest %s", temporaryFile)
+	}
+
+	if err := os.Rename(temporaryFile, manifest.file); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to replace manifest %s", manifest.file)
+	}
+
+	return nil
+}
+
+// Locations returns the locations the pipeline was created in. The cache is not part of the pipeline.
+func (manifest *Manifest) Locations() *workspace.Locations {
+	return &workspace.Locations{
+		OperationLocation:      manifest.OperationLocation,
+		ChangeCaptureLocation:  manifest.ChangeCaptureLocation,
+		RootFileSystemLocation: manifest.RootFileSystemLocation,
+		CacheLocation:          "",
+		MinimumFreeSpace:       0,
+	}
+}
+
+func (manifest *Manifest) Step(stepUUID string) *StepState {
+	for _, step := range manifest.Steps {
+		if step.UUID == stepUUID {
+			return step
+		}
+	}
+
+	return nil
+}
+
+func (manifest *Manifest) IsCompleted(stepUUID string) bool {
+	step := manifest.Step(stepUUID)
+
+	return step != nil && step.Status == Completed
+}
+
+// CompletedSteps returns the number of completed steps.
+func (manifest *Manifest) CompletedSteps() int {
+	completedSteps := 0
+
+	for _, step := range manifest.Steps {
+		if step.Status == Completed {
+			completedSteps++
+		}
+	}
+
+	return completedSteps
+}
+
+func (manifest *Manifest) StepStarted(stepUUID string) {
+	manifest.Status = Running
+	manifest.Error = ""
+	manifest.setStepStatus(stepUUID, Running, nil)
+}
+
+func (manifest *Manifest) StepCompleted(stepUUID string) {
+	manifest.setStepStatus(stepUUID, Completed, nil)
+}
+
+func (manifest *Manifest) StepFailed(stepUUID string, err error) {
+	manifest.setStepStatus(stepUUID, Failed, err)
+	manifest.Failed(err)
+}
+
+func (manifest *Manifest) Failed(err error) {
+	manifest.Status = Failed
+	manifest.Error = err.Error()
+}
+
+func (manifest *Manifest) Completed() {
+	manifest.Status = Completed
+	manifest.Error = ""
+}
+
+func (manifest *Manifest) setStepStatus(stepUUID string, status Status, err error) {
+	step := manifest.Step(stepUUID)
+	if step == nil {
+		return
+	}
+
+	step.Status = status
+	step.Error = ""
+
+	if err != nil {
+		step.Error = err.Error()
+	}
+}
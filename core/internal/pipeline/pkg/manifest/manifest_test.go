@@ -0,0 +1,127 @@
+package manifest_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	uut "chast.io/core/internal/pipeline/pkg/manifest"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+)
+
+func dummyPipeline(t *testing.T) *refactoringpipelinemodel.Pipeline {
+	t.Helper()
+
+	baseDir := t.TempDir()
+	pipeline := refactoringpipelinemodel.NewPipeline(
+		filepath.Join(baseDir, "operation"),
+		filepath.Join(baseDir, "changes"),
+		"/",
+	)
+
+	format := &refactoring.Run{ID: "format"} //nolint:exhaustruct // not required for test
+	lint := &refactoring.Run{ID: "lint"}     //nolint:exhaustruct // not required for test
+
+	formatStep := refactoringpipelinemodel.NewStep(&refactoring.SingleRunModel{Run: format})
+	lintStep := refactoringpipelinemodel.NewStep(&refactoring.SingleRunModel{Run: lint})
+	lintStep.AddDependency(formatStep)
+
+	for _, step := range []*refactoringpipelinemodel.Step{formatStep, lintStep} {
+		executionGroup := refactoringpipelinemodel.NewExecutionGroup()
+		executionGroup.AddStep(step)
+		pipeline.AddExecutionGroup(executionGroup)
+	}
+
+	return pipeline
+}
+
+func TestManifest_SaveAndRead(t *testing.T) {
+	t.Parallel()
+
+	pipeline := dummyPipeline(t)
+	formatStep := pipeline.ExecutionGroups[0].Steps[0]
+	lintStep := pipeline.ExecutionGroups[1].Steps[0]
+
+	manifest := uut.New(pipeline, nil)
+	manifest.StepStarted(formatStep.UUID)
+	manifest.StepCompleted(formatStep.UUID)
+	manifest.StepStarted(lintStep.UUID)
+	manifest.StepFailed(lintStep.UUID, errors.New("lint failed"))
+
+	if err := manifest.Save(); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	changeCaptureLocation := filepath.Dir(pipeline.ChangeCaptureLocation)
+
+	readManifest, readError := uut.Read(changeCaptureLocation, pipeline.UUID)
+	if readError != nil {
+		t.Fatalf("Expected no error, but was '%v'", readError)
+	}
+
+	if readManifest.Status != uut.Failed {
+		t.Errorf("Expected status to be '%s', but was '%s'", uut.Failed, readManifest.Status)
+	}
+
+	if !readManifest.IsCompleted(formatStep.UUID) {
+		t.Error("Expected format step to be completed, but was not")
+	}
+
+	if readManifest.IsCompleted(lintStep.UUID) {
+		t.Error("Expected lint step not to be completed, but was")
+	}
+
+	lintState := readManifest.Step(lintStep.UUID)
+	if lintState.Error != "lint failed" {
+		t.Errorf("Expected error to be 'lint failed', but was '%s'", lintState.Error)
+	}
+
+	if len(lintState.Inputs) != 1 || lintState.Inputs[0] != formatStep.ChangeCaptureLocation {
+		t.Errorf("Expected inputs to be ['%s'], but was '%v'", formatStep.ChangeCaptureLocation, lintState.Inputs)
+	}
+
+	if lintState.LogFile != lintStep.GetLogFile() {
+		t.Errorf("Expected log file to be '%s', but was '%s'", lintStep.GetLogFile(), lintState.LogFile)
+	}
+
+	if readManifest.Locations().OperationLocation != pipeline.OperationLocation {
+		t.Errorf("Expected operation location to be '%s', but was '%s'",
+			pipeline.OperationLocation, readManifest.Locations().OperationLocation)
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	pipeline := dummyPipeline(t)
+	changeCaptureLocation := filepath.Dir(pipeline.ChangeCaptureLocation)
+
+	if err := uut.New(pipeline, nil).Save(); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	leftover := refactoringpipelinemodel.UUIDPrefix + "leftover"
+	if err := os.MkdirAll(filepath.Join(changeCaptureLocation, leftover), os.ModePerm); err != nil {
+		t.Fatalf("Error creating leftover pipeline: %v", err)
+	}
+
+	manifests, listError := uut.List(changeCaptureLocation)
+	if listError != nil {
+		t.Fatalf("Expected no error, but was '%v'", listError)
+	}
+
+	statuses := make(map[string]uut.Status)
+	for _, manifest := range manifests {
+		statuses[manifest.PipelineUUID] = manifest.Status
+	}
+
+	if statuses[pipeline.UUID] != uut.Pending {
+		t.Errorf("Expected status of pipeline to be '%s', but was '%s'", uut.Pending, statuses[pipeline.UUID])
+	}
+
+	if statuses[leftover] != uut.Unknown {
+		t.Errorf("Expected status of leftover to be '%s', but was '%s'", uut.Unknown, statuses[leftover])
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"time"
+
+	"chast.io/core/internal/changeisolator/pkg/namespace"
+)
+
+type Phase = string
+
+const (
+	// IsolationSetup prepares the isolated file system of a step, e.g. mounting the union file system.
+	IsolationSetup Phase = "isolationSetup"
+	// Commands runs the commands of a step.
+	Commands Phase = "commands"
+	// IsolationCleanup tears down the isolated file system of a step.
+	IsolationCleanup Phase = "isolationCleanup"
+	// StepPostProcessing filters the changes of a step and publishes them to its dependents.
+	StepPostProcessing Phase = "stepPostProcessing"
+	// Merge merges the changes of the final steps of the pipeline.
+	Merge Phase = "merge"
+	// DiffBuilding builds the diff of the changes of the pipeline for the report.
+	DiffBuilding Phase = "diffBuilding"
+)
+
+// Phases lists all phases in the order they happen.
+func Phases() []Phase {
+	return []Phase{IsolationSetup, Commands, IsolationCleanup, StepPostProcessing, Merge, DiffBuilding}
+}
+
+// PhaseTiming is the wall time spent in a phase.
+type PhaseTiming struct {
+	Phase          Phase `json:"phase"`
+	DurationMillis int64 `json:"durationMs"`
+}
+
+func NewPhaseTiming(phase Phase, duration time.Duration) PhaseTiming {
+	return PhaseTiming{
+		Phase:          phase,
+		DurationMillis: duration.Milliseconds(),
+	}
+}
+
+func (timing PhaseTiming) Duration() time.Duration {
+	return time.Duration(timing.DurationMillis) * time.Millisecond
+}
+
+// Total returns the wall time spent in the phase over all timings.
+func Total(timings []PhaseTiming, phase Phase) time.Duration {
+	total := time.Duration(0)
+
+	for _, timing := range timings {
+		if timing.Phase == phase {
+			total += timing.Duration()
+		}
+	}
+
+	return total
+}
+
+// ResourceUsage describes the resources used by the processes of a step.
+type ResourceUsage struct {
+	UserCPUMillis   int64 `json:"userCpuMs"`
+	SystemCPUMillis int64 `json:"systemCpuMs"`
+	MaxRSSKiB       int64 `json:"maxRssKiB"`
+}
+
+// IsolatedRunTimings returns the timings of the phases of an isolated run.
+func IsolatedRunTimings(runMetrics namespace.RunMetrics) []PhaseTiming {
+	return []PhaseTiming{
+		NewPhaseTiming(IsolationSetup, runMetrics.IsolationSetup),
+		NewPhaseTiming(Commands, runMetrics.Commands),
+		NewPhaseTiming(IsolationCleanup, runMetrics.IsolationCleanup),
+	}
+}
+
+// AddIsolatedRun adds the resources used by an isolated run. The usage is nil if no resources were used yet.
+func AddIsolatedRun(usage *ResourceUsage, runMetrics namespace.RunMetrics) *ResourceUsage {
+	if usage == nil {
+		usage = &ResourceUsage{UserCPUMillis: 0, SystemCPUMillis: 0, MaxRSSKiB: 0}
+	}
+
+	usage.UserCPUMillis += runMetrics.UserCPU.Milliseconds()
+	usage.SystemCPUMillis += runMetrics.SystemCPU.Milliseconds()
+
+	if runMetrics.MaxRSSKiB > usage.MaxRSSKiB {
+		usage.MaxRSSKiB = runMetrics.MaxRSSKiB
+	}
+
+	return usage
+}
+
+func (usage *ResourceUsage) CPU() time.Duration {
+	return time.Duration(usage.UserCPUMillis+usage.SystemCPUMillis) * time.Millisecond
+}
@@ -0,0 +1,52 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"chast.io/core/internal/changeisolator/pkg/namespace"
+	uut "chast.io/core/internal/pipeline/pkg/metrics"
+)
+
+func TestTotal(t *testing.T) {
+	t.Parallel()
+
+	timings := []uut.PhaseTiming{
+		uut.NewPhaseTiming(uut.Commands, 2*time.Second),
+		uut.NewPhaseTiming(uut.IsolationSetup, 300*time.Millisecond),
+		uut.NewPhaseTiming(uut.Commands, 500*time.Millisecond),
+	}
+
+	if total := uut.Total(timings, uut.Commands); total != 2500*time.Millisecond {
+		t.Errorf("Expected commands to take 2.5s, but was %s", total)
+	}
+
+	if total := uut.Total(timings, uut.Merge); total != 0 {
+		t.Errorf("Expected merge to take no time, but was %s", total)
+	}
+}
+
+func TestAddIsolatedRun(t *testing.T) {
+	t.Parallel()
+
+	firstAttempt := namespace.RunMetrics{ //nolint:exhaustruct // not required for test
+		UserCPU:   time.Second,
+		SystemCPU: 200 * time.Millisecond,
+		MaxRSSKiB: 4096,
+	}
+	secondAttempt := namespace.RunMetrics{ //nolint:exhaustruct // not required for test
+		UserCPU:   2 * time.Second,
+		SystemCPU: 100 * time.Millisecond,
+		MaxRSSKiB: 1024,
+	}
+
+	usage := uut.AddIsolatedRun(uut.AddIsolatedRun(nil, firstAttempt), secondAttempt)
+
+	if usage.CPU() != 3300*time.Millisecond {
+		t.Errorf("Expected CPU time of both attempts to be 3.3s, but was %s", usage.CPU())
+	}
+
+	if usage.MaxRSSKiB != 4096 {
+		t.Errorf("Expected max RSS to be the largest of both attempts 4096 KiB, but was %d KiB", usage.MaxRSSKiB)
+	}
+}
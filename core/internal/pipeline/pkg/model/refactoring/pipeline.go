@@ -2,7 +2,11 @@ package refactoringpipelinemodel
 
 import (
 	"path/filepath"
+	"time"
 
+	"chast.io/core/internal/pipeline/pkg/event"
+	"chast.io/core/internal/pipeline/pkg/metrics"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
 	"github.com/google/uuid"
 )
 
@@ -12,25 +16,74 @@ type Pipeline struct {
 	ChangeCaptureLocation  string
 	RootFileSystemLocation string
 	UUID                   string
+	ClassificationStats    *refactoring.ClassificationStats
+	DependencyDecisions    []refactoring.DependencyDecision
+	// Partial is set if only a selection of the runs is part of the pipeline. The changes of its steps are kept for
+	// inspection then.
+	Partial bool
+	// Timings are the wall times of the phases of the pipeline which are not part of a step.
+	Timings []metrics.PhaseTiming
+	// Conflicts are the paths changed by more than one final step, which are left out of the merged changes.
+	Conflicts []MergeConflict
+
+	observers []event.Observer
+}
+
+// MergeConflict is a path changed by more than one final step, e.g. by two branches of a fanned out primary parameter.
+type MergeConflict struct {
+	Path  string
+	Steps []string
 }
 
+// UUIDPrefix starts the uuid of every pipeline and the name of its folder in the change capture location.
+const UUIDPrefix = "PIPELINE-"
+
 func NewPipeline(
 	operationLocation string,
 	changeCaptureLocation string,
 	rootFileSystemLocation string,
+) *Pipeline {
+	return RestorePipeline(UUIDPrefix+uuid.New().String(), operationLocation, changeCaptureLocation, rootFileSystemLocation)
+}
+
+// RestorePipeline creates a pipeline with the uuid of a pipeline created before, so it uses the same locations.
+func RestorePipeline(
+	pipelineUUID string,
+	operationLocation string,
+	changeCaptureLocation string,
+	rootFileSystemLocation string,
 ) *Pipeline {
 	absOperationLocation, _ := filepath.Abs(operationLocation)
 	absChangeCaptureLocation, _ := filepath.Abs(changeCaptureLocation)
 	absRootFileSystemLocation, _ := filepath.Abs(rootFileSystemLocation)
 
-	pipelineUUID := "PIPELINE-" + uuid.New().String()
-
 	return &Pipeline{
 		UUID:                   pipelineUUID,
 		ExecutionGroups:        make([]*ExecutionGroup, 1),
 		OperationLocation:      absOperationLocation,
 		ChangeCaptureLocation:  filepath.Join(absChangeCaptureLocation, pipelineUUID),
 		RootFileSystemLocation: absRootFileSystemLocation,
+		ClassificationStats:    refactoring.NewClassificationStats(),
+		DependencyDecisions:    make([]refactoring.DependencyDecision, 0),
+		Partial:                false,
+		Timings:                make([]metrics.PhaseTiming, 0),
+		Conflicts:              make([]MergeConflict, 0),
+		observers:              make([]event.Observer, 0),
+	}
+}
+
+// AddObserver adds an observer which is notified about all events of the pipeline.
+func (p *Pipeline) AddObserver(observer event.Observer) {
+	p.observers = append(p.observers, observer)
+}
+
+// Notify completes the event with the pipeline uuid and the current time and notifies all observers about it.
+func (p *Pipeline) Notify(pipelineEvent event.Event) {
+	pipelineEvent.PipelineUUID = p.UUID
+	pipelineEvent.Time = time.Now()
+
+	for _, observer := range p.observers {
+		observer.Notify(pipelineEvent)
 	}
 }
 
@@ -42,6 +95,31 @@ func (p *Pipeline) GetFinalChangeCaptureLocation() string {
 	return filepath.Join(p.ChangeCaptureLocation, "final")
 }
 
+// GetLogLocation returns the folder the logs of the steps are kept in. It is not removed by the cleanup.
+func (p *Pipeline) GetLogLocation() string {
+	return filepath.Join(p.ChangeCaptureLocation, "logs")
+}
+
+// GetLockFile returns the file locked by the invocation running, resuming or removing the pipeline.
+func (p *Pipeline) GetLockFile() string {
+	return LockFile(filepath.Dir(p.ChangeCaptureLocation), p.UUID)
+}
+
+// GetManifestFile returns the file the state of the pipeline is persisted in.
+func (p *Pipeline) GetManifestFile() string {
+	return ManifestFile(filepath.Dir(p.ChangeCaptureLocation), p.UUID)
+}
+
+// LockFile returns the lock file of the pipeline with the uuid in the change capture location.
+func LockFile(changeCaptureLocation string, pipelineUUID string) string {
+	return filepath.Join(changeCaptureLocation, pipelineUUID, "lock")
+}
+
+// ManifestFile returns the manifest file of the pipeline with the uuid in the change capture location.
+func ManifestFile(changeCaptureLocation string, pipelineUUID string) string {
+	return filepath.Join(changeCaptureLocation, pipelineUUID, "manifest.json")
+}
+
 func (p *Pipeline) AddExecutionGroup(executionGroup *ExecutionGroup) {
 	executionGroup.withPipeline(p)
 
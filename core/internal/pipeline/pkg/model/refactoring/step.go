@@ -2,8 +2,11 @@ package refactoringpipelinemodel
 
 import (
 	"path/filepath"
+	"sync"
 
+	"chast.io/core/internal/changeisolator/pkg/namespace"
 	"chast.io/core/internal/internal_util/collection"
+	"chast.io/core/internal/pipeline/pkg/metrics"
 	"chast.io/core/internal/run_model/pkg/model/refactoring"
 )
 
@@ -17,6 +20,16 @@ type Step struct {
 	Pipeline     *Pipeline
 	Dependencies []*Step
 	Dependents   []*Step
+
+	// CommandResults are the results of the commands of the step, empty if its changes were restored from the cache.
+	CommandResults []namespace.CommandResult
+	// Timings are the wall times of the phases of the step, including all attempts.
+	Timings []metrics.PhaseTiming
+	// ResourceUsage sums the resources of all attempts, nil if the commands of the step did not run.
+	ResourceUsage *metrics.ResourceUsage
+
+	// previousChangesLock serializes the dependencies publishing their changes to the step concurrently.
+	previousChangesLock sync.Mutex
 }
 
 func NewStep(runModel *refactoring.SingleRunModel) *Step {
@@ -55,6 +68,18 @@ func (s *Step) GetMergedPreviousChangesLocation() string {
 	return s.ChangeCaptureLocation + "-prev"
 }
 
+// LockMergedPreviousChanges locks the merged previous changes of the step until the returned function is called.
+func (s *Step) LockMergedPreviousChanges() func() {
+	s.previousChangesLock.Lock()
+
+	return s.previousChangesLock.Unlock
+}
+
+// GetLogFile returns the file the output of the commands of the step is logged to.
+func (s *Step) GetLogFile() string {
+	return filepath.Join(s.Pipeline.GetLogLocation(), s.UUID+".log")
+}
+
 func (s *Step) GetPreviousChangeCaptureLocations() []string {
 	locations := make([]string, 0)
 	steps := make([]*Step, 0)
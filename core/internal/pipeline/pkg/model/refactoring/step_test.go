@@ -256,6 +256,30 @@ func TestStep_GetPreviousChangesLocation(t *testing.T) {
 
 // endregion
 
+// region GetLogFile
+
+func TestStep_GetLogFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return log file of step in log location of pipeline", func(t *testing.T) {
+		t.Parallel()
+
+		step := stepDummyStep(1)
+		group := uut.NewExecutionGroup()
+		group.AddStep(step)
+
+		pipeline := stepDummyPipeline()
+		pipeline.AddExecutionGroup(group)
+
+		expectedLogFile := filepath.Join(pipeline.ChangeCaptureLocation, "logs", step.UUID+".log")
+		if step.GetLogFile() != expectedLogFile {
+			t.Errorf("Expected log file to be '%s', but was '%s'", expectedLogFile, step.GetLogFile())
+		}
+	})
+}
+
+// endregion
+
 // region ChangeFilteringLocations
 
 func TestStep_ChangeFilteringLocations(t *testing.T) {
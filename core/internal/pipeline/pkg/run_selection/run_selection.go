@@ -0,0 +1,26 @@
+package runselection
+
+// Selection prunes the runs of a pipeline, e.g. to debug a single run of a recipe.
+// Runs are referred to by their id. All runs which share the id are selected.
+type Selection struct {
+	// Only selects the runs with these ids and the runs they depend on.
+	Only []string
+	// Until selects the run with this id, the runs it depends on and all runs of earlier execution groups.
+	Until string
+	// Skip removes the runs with these ids. A run can not be skipped if a selected run depends on it.
+	Skip []string
+}
+
+// NewSelection selects all runs.
+func NewSelection() *Selection {
+	return &Selection{
+		Only:  make([]string, 0),
+		Until: "",
+		Skip:  make([]string, 0),
+	}
+}
+
+// IsEmpty checks if all runs are selected.
+func (selection *Selection) IsEmpty() bool {
+	return selection == nil || (len(selection.Only) == 0 && selection.Until == "" && len(selection.Skip) == 0)
+}
@@ -0,0 +1,464 @@
+package stepcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"chast.io/core/internal/internal_util/glob"
+	treehash "chast.io/core/internal/internal_util/tree_hash"
+	chastlog "chast.io/core/internal/logger"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+)
+
+const (
+	// DefaultMaxSize is the number of bytes the cache is pruned to after storing a step.
+	DefaultMaxSize int64 = 1024 * 1024 * 1024
+
+	entryFileName    = "entry.json"
+	captureFolder    = "capture"
+	temporaryPrefix  = "tmp-"
+	folderPermission = 0o755
+	entryPermission  = 0o644
+)
+
+// Cache stores the change captures of steps by a key of everything the changes of a step depend on.
+type Cache struct {
+	location string
+	// maxSize is the number of bytes the cache is pruned to after storing a step, 0 disables pruning.
+	maxSize int64
+}
+
+// Entry describes a stored change capture.
+type Entry struct {
+	Key        string    `json:"key"`
+	RunID      string    `json:"runId,omitempty"`
+	Size       int64     `json:"size"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+type Stats struct {
+	Location string
+	Entries  int
+	Size     int64
+}
+
+func NewCache(location string, maxSize int64) *Cache {
+	return &Cache{
+		location: location,
+		maxSize:  maxSize,
+	}
+}
+
+// Key returns the key of the step, built from the recipe hash, the resolved command, the content of its working
+// directory, the input files in the change locations of the step (or the whole input if it has none) and the change
+// captures of all previous steps.
+// Steps whose inputs cannot be determined are not cacheable, for them the key is empty.
+func Key(step *refactoringpipelinemodel.Step, recipeHash string, inputHash string) (string, error) {
+	if recipeHash == "" {
+		return "", nil
+	}
+
+	run := step.RunModel.Run
+	keyHash := sha256.New()
+
+	_, _ = fmt.Fprintf(keyHash, "recipe %s\n", recipeHash)
+
+	resolvedCommand, marshalError := json.Marshal(struct {
+		Cmds             [][]string
+		WorkingDirectory string
+		Environment      map[string]string
+		DockerImage      string
+		AllowedExitCodes []int
+		ContinueOnError  bool
+	}{
+		Cmds:             run.Command.Cmds,
+		WorkingDirectory: run.Command.WorkingDirectory,
+		Environment:      run.Environment,
+		DockerImage:      dockerImage(step),
+		AllowedExitCodes: run.Command.AllowedExitCodes,
+		ContinueOnError:  run.Command.ContinueOnError,
+	})
+	if marshalError != nil {
+		return "", errorx.InternalError.Wrap(marshalError, "Failed to serialize command of step %s", step.UUID)
+	}
+
+	_, _ = fmt.Fprintf(keyHash, "command %s\n", resolvedCommand)
+
+	// the scripts the commands run usually lie in the working directory, e.g. the run folder of the recipe
+	workingDirectory := run.Command.WorkingDirectory
+	if !filepath.IsAbs(workingDirectory) || workingDirectory == "/" {
+		return "", nil
+	}
+
+	if err := writeTree(keyHash, "working directory", workingDirectory, treehash.HashTree); err != nil {
+		return "", err
+	}
+
+	cacheable, inputError := writeInputs(keyHash, step, inputHash)
+	if inputError != nil || !cacheable {
+		return "", inputError
+	}
+
+	// only the content of the previous change captures is relevant, their locations differ for every pipeline
+	for _, previousChangeCaptureLocation := range step.GetPreviousChangeCaptureLocations() {
+		if err := writeTree(keyHash, "previous", previousChangeCaptureLocation, treehash.HashTreeIncludingIgnored); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(keyHash.Sum(nil)), nil
+}
+
+func dockerImage(step *refactoringpipelinemodel.Step) string {
+	if step.RunModel.Run.Docker == nil {
+		return ""
+	}
+
+	return step.RunModel.Run.Docker.DockerImage
+}
+
+func writeInputs(writer io.Writer, step *refactoringpipelinemodel.Step, inputHash string) (bool, error) {
+	changeLocations := step.ChangeFilteringLocations()
+	if changeLocations == nil || len(changeLocations.Include) == 0 {
+		if inputHash == "" {
+			return false, nil
+		}
+
+		_, _ = fmt.Fprintf(writer, "input %s\n", inputHash)
+
+		return true, nil
+	}
+
+	for _, include := range changeLocations.Include {
+		root := glob.StaticRoot(include)
+		if !filepath.IsAbs(root) || root == "/" {
+			return false, nil // the files the step reads cannot be bounded
+		}
+
+		_, _ = fmt.Fprintf(writer, "include %s\n", include)
+
+		if err := writeTree(writer, "tree "+root, root, treehash.HashTree); err != nil {
+			return false, err
+		}
+	}
+
+	for _, exclude := range changeLocations.Exclude {
+		_, _ = fmt.Fprintf(writer, "exclude %s\n", exclude)
+	}
+
+	return true, nil
+}
+
+func writeTree(writer io.Writer, label string, root string, hashTree func(string) (string, error)) error {
+	if _, err := os.Lstat(root); os.IsNotExist(err) {
+		_, _ = fmt.Fprintf(writer, "%s missing\n", label)
+
+		return nil
+	}
+
+	treeHash, hashError := hashTree(root)
+	if hashError != nil {
+		return errorx.Decorate(hashError, "Failed to hash %s", root)
+	}
+
+	_, _ = fmt.Fprintf(writer, "%s %s\n", label, treeHash)
+
+	return nil
+}
+
+// Restore copies the stored change capture of the key to the change capture location of the step.
+// It returns false if there is no entry for the key.
+func (cache *Cache) Restore(key string, step *refactoringpipelinemodel.Step) (bool, error) {
+	entryLocation := filepath.Join(cache.location, key)
+
+	entry, readError := readEntry(entryLocation)
+	if readError != nil {
+		if errorx.IsOfType(readError, errorx.DataUnavailable) {
+			return false, nil
+		}
+
+		return false, readError
+	}
+
+	if err := os.RemoveAll(step.ChangeCaptureLocation); err != nil {
+		return false, errorx.ExternalError.Wrap(err, "Failed to clear change capture of step %s", step.UUID)
+	}
+
+	if err := copyTree(filepath.Join(entryLocation, captureFolder), step.ChangeCaptureLocation); err != nil {
+		return false, errorx.Decorate(err, "Failed to restore change capture of step %s", step.UUID)
+	}
+
+	entry.LastUsedAt = time.Now()
+	if err := writeEntry(entryLocation, entry); err != nil {
+		chastlog.Log.Debugf("Failed to update last use of cache entry %s: %v", key, err)
+	}
+
+	return true, nil
+}
+
+// Store copies the change capture of the step to the entry of the key and prunes the cache to its maximum size.
+func (cache *Cache) Store(key string, step *refactoringpipelinemodel.Step) error {
+	temporaryLocation := filepath.Join(cache.location, temporaryPrefix+uuid.New().String())
+
+	defer func() { _ = os.RemoveAll(temporaryLocation) }()
+
+	if err := copyTree(step.ChangeCaptureLocation, filepath.Join(temporaryLocation, captureFolder)); err != nil {
+		return errorx.Decorate(err, "Failed to store change capture of step %s", step.UUID)
+	}
+
+	size, sizeError := treeSize(temporaryLocation)
+	if sizeError != nil {
+		return sizeError
+	}
+
+	now := time.Now()
+	if err := writeEntry(temporaryLocation, &Entry{
+		Key:        key,
+		RunID:      step.RunModel.Run.ID,
+		Size:       size,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temporaryLocation, filepath.Join(cache.location, key)); err != nil {
+		if errors.Is(err, fs.ErrExist) || errors.Is(err, syscall.ENOTEMPTY) {
+			return nil // stored concurrently by another invocation
+		}
+
+		return errorx.ExternalError.Wrap(err, "Failed to store cache entry %s", key)
+	}
+
+	if cache.maxSize > 0 {
+		if _, err := cache.Prune(cache.maxSize, 0); err != nil {
+			return errorx.Decorate(err, "Failed to prune cache")
+		}
+	}
+
+	return nil
+}
+
+// Entries returns all entries of the cache, least recently used first.
+func (cache *Cache) Entries() ([]*Entry, error) {
+	directoryEntries, readError := os.ReadDir(cache.location)
+	if readError != nil {
+		if os.IsNotExist(readError) {
+			return make([]*Entry, 0), nil
+		}
+
+		return nil, errorx.ExternalError.Wrap(readError, "Failed to list cache %s", cache.location)
+	}
+
+	entries := make([]*Entry, 0, len(directoryEntries))
+
+	for _, directoryEntry := range directoryEntries {
+		if !directoryEntry.IsDir() || strings.HasPrefix(directoryEntry.Name(), temporaryPrefix) {
+			continue
+		}
+
+		entry, entryReadError := readEntry(filepath.Join(cache.location, directoryEntry.Name()))
+		if entryReadError != nil {
+			chastlog.Log.Debugf("Skipping unreadable cache entry %s: %v", directoryEntry.Name(), entryReadError)
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsedAt.Before(entries[j].LastUsedAt)
+	})
+
+	return entries, nil
+}
+
+func (cache *Cache) Stats() (*Stats, error) {
+	entries, entriesError := cache.Entries()
+	if entriesError != nil {
+		return nil, entriesError
+	}
+
+	stats := &Stats{Location: cache.location, Entries: len(entries), Size: 0}
+	for _, entry := range entries {
+		stats.Size += entry.Size
+	}
+
+	return stats, nil
+}
+
+// Prune removes the least recently used entries until the cache is at most maxSize bytes large, and all entries
+// which were not used for longer than maxAge. A maxAge of 0 keeps entries of any age.
+// It returns the number of removed entries.
+func (cache *Cache) Prune(maxSize int64, maxAge time.Duration) (int, error) {
+	entries, entriesError := cache.Entries()
+	if entriesError != nil {
+		return 0, entriesError
+	}
+
+	size := int64(0)
+	for _, entry := range entries {
+		size += entry.Size
+	}
+
+	removedEntries := 0
+
+	for _, entry := range entries {
+		expired := maxAge > 0 && time.Since(entry.LastUsedAt) > maxAge
+		if size <= maxSize && !expired {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(cache.location, entry.Key)); err != nil {
+			return removedEntries, errorx.ExternalError.Wrap(err, "Failed to remove cache entry %s", entry.Key)
+		}
+
+		size -= entry.Size
+		removedEntries++
+	}
+
+	return removedEntries, nil
+}
+
+func readEntry(entryLocation string) (*Entry, error) {
+	content, readError := os.ReadFile(filepath.Join(entryLocation, entryFileName))
+	if readError != nil {
+		if os.IsNotExist(readError) {
+			return nil, errorx.DataUnavailable.New("No cache entry %s", entryLocation)
+		}
+
+		return nil, errorx.ExternalError.Wrap(readError, "Failed to read cache entry %s", entryLocation)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, errorx.IllegalFormat.Wrap(err, "Failed to parse cache entry %s", entryLocation)
+	}
+
+	return &entry, nil
+}
+
+func writeEntry(entryLocation string, entry *Entry) error {
+	content, marshalError := json.MarshalIndent(entry, "", "  ")
+	if marshalError != nil {
+		return errorx.InternalError.Wrap(marshalError, "Failed to serialize cache entry")
+	}
+
+	if err := os.WriteFile(filepath.Join(entryLocation, entryFileName), content, entryPermission); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to write cache entry %s", entryLocation)
+	}
+
+	return nil
+}
+
+// copyTree copies folders, files and symlinks with their permissions. Meta files of the isolation are copied as they
+// are, so the copy can be used like the original change capture.
+func copyTree(source string, target string) error {
+	if _, err := os.Lstat(source); os.IsNotExist(err) {
+		// no changes were captured
+		if err := os.MkdirAll(target, folderPermission); err != nil {
+			return errorx.ExternalError.Wrap(err, "Failed to create %s", target)
+		}
+
+		return nil
+	}
+
+	if walkError := filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, relError := filepath.Rel(source, path)
+		if relError != nil {
+			return relError //nolint:wrapcheck // wrapped below
+		}
+
+		targetPath := filepath.Join(target, relativePath)
+
+		info, infoError := entry.Info()
+		if infoError != nil {
+			return infoError //nolint:wrapcheck // wrapped below
+		}
+
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(targetPath, info.Mode().Perm()|0o700) //nolint:wrapcheck // wrapped below
+		case info.Mode()&fs.ModeSymlink != 0:
+			linkTarget, readLinkError := os.Readlink(path)
+			if readLinkError != nil {
+				return readLinkError //nolint:wrapcheck // wrapped below
+			}
+
+			return os.Symlink(linkTarget, targetPath) //nolint:wrapcheck // wrapped below
+		default:
+			return copyFile(path, targetPath, info.Mode().Perm())
+		}
+	}); walkError != nil {
+		return errorx.ExternalError.Wrap(walkError, "Failed to copy %s to %s", source, target)
+	}
+
+	return nil
+}
+
+func copyFile(source string, target string, permission fs.FileMode) error {
+	sourceFile, openError := os.Open(source)
+	if openError != nil {
+		return openError //nolint:wrapcheck // wrapped by copyTree
+	}
+
+	defer sourceFile.Close()
+
+	targetFile, createError := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, permission)
+	if createError != nil {
+		return createError //nolint:wrapcheck // wrapped by copyTree
+	}
+
+	_, copyError := io.Copy(targetFile, sourceFile)
+	closeError := targetFile.Close()
+
+	if copyError != nil {
+		return copyError //nolint:wrapcheck // wrapped by copyTree
+	}
+
+	return closeError //nolint:wrapcheck // wrapped by copyTree
+}
+
+func treeSize(root string) (int64, error) {
+	size := int64(0)
+
+	if walkError := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.Type().IsRegular() {
+			info, infoError := entry.Info()
+			if infoError != nil {
+				return infoError //nolint:wrapcheck // wrapped below
+			}
+
+			size += info.Size()
+		}
+
+		return nil
+	}); walkError != nil {
+		return 0, errorx.ExternalError.Wrap(walkError, "Failed to get size of %s", root)
+	}
+
+	return size, nil
+}
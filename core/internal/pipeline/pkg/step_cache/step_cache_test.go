@@ -0,0 +1,228 @@
+package stepcache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	uut "chast.io/core/internal/pipeline/pkg/step_cache"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Error creating folder: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+// dummyStep returns a step of a new pipeline, which includes the changes in the input folder and runs in it.
+func dummyStep(t *testing.T, inputFolder string, command string) *refactoringpipelinemodel.Step {
+	t.Helper()
+
+	return dummyStepIn(t, inputFolder, inputFolder, command)
+}
+
+// dummyStepIn returns a step of a new pipeline, which includes the changes in the input folder and runs in the working
+// directory.
+func dummyStepIn(
+	t *testing.T,
+	inputFolder string,
+	workingDirectory string,
+	command string,
+) *refactoringpipelinemodel.Step {
+	t.Helper()
+
+	baseDir := t.TempDir()
+	pipeline := refactoringpipelinemodel.NewPipeline(
+		filepath.Join(baseDir, "operation"),
+		filepath.Join(baseDir, "changes"),
+		"/",
+	)
+
+	run := &refactoring.Run{ //nolint:exhaustruct // not required for test
+		ID:              "format",
+		Command:         &refactoring.Command{Cmds: [][]string{{command}}, WorkingDirectory: workingDirectory},
+		ChangeLocations: &refactoring.ChangeLocations{Include: []string{inputFolder}}, //nolint:exhaustruct // include only
+	}
+
+	step := refactoringpipelinemodel.NewStep(&refactoring.SingleRunModel{Run: run})
+	executionGroup := refactoringpipelinemodel.NewExecutionGroup()
+	executionGroup.AddStep(step)
+	pipeline.AddExecutionGroup(executionGroup)
+
+	return step
+}
+
+func key(t *testing.T, step *refactoringpipelinemodel.Step) string {
+	t.Helper()
+
+	stepKey, keyError := uut.Key(step, "recipeHash", "")
+	if keyError != nil {
+		t.Fatalf("Expected no error, but was '%v'", keyError)
+	}
+
+	return stepKey
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	inputFolder := t.TempDir()
+	writeFile(t, filepath.Join(inputFolder, "Main.java"), "class Main {}")
+
+	firstKey := key(t, dummyStep(t, inputFolder, "format"))
+
+	if firstKey == "" {
+		t.Fatal("Expected step to be cacheable, but was not")
+	}
+
+	if secondKey := key(t, dummyStep(t, inputFolder, "format")); secondKey != firstKey {
+		t.Errorf("Expected key of another pipeline to be '%s', but was '%s'", firstKey, secondKey)
+	}
+
+	if commandKey := key(t, dummyStep(t, inputFolder, "lint")); commandKey == firstKey {
+		t.Error("Expected key to change with the command, but was equal")
+	}
+
+	writeFile(t, filepath.Join(inputFolder, "Main.java"), "class Main { }")
+
+	if inputKey := key(t, dummyStep(t, inputFolder, "format")); inputKey == firstKey {
+		t.Error("Expected key to change with the input, but was equal")
+	}
+}
+
+func TestKey_FailureHandling(t *testing.T) {
+	t.Parallel()
+
+	inputFolder := t.TempDir()
+	writeFile(t, filepath.Join(inputFolder, "Main.java"), "class Main {}")
+
+	firstKey := key(t, dummyStep(t, inputFolder, "format"))
+
+	continuingStep := dummyStep(t, inputFolder, "format")
+	continuingStep.RunModel.Run.Command.ContinueOnError = true
+
+	if continuingKey := key(t, continuingStep); continuingKey == firstKey {
+		t.Error("Expected key to change with continuing on errors, but was equal")
+	}
+
+	allowingStep := dummyStep(t, inputFolder, "format")
+	allowingStep.RunModel.Run.Command.AllowedExitCodes = []int{1}
+
+	if allowingKey := key(t, allowingStep); allowingKey == firstKey {
+		t.Error("Expected key to change with the allowed exit codes, but was equal")
+	}
+}
+
+func TestKey_WorkingDirectory(t *testing.T) {
+	t.Parallel()
+
+	inputFolder := t.TempDir()
+	runFolder := filepath.Join(t.TempDir(), "run")
+	writeFile(t, filepath.Join(inputFolder, "Main.java"), "class Main {}")
+	writeFile(t, filepath.Join(runFolder, "format.sh"), "sed -i 's/ {/{/' \"$1\"")
+
+	firstKey := key(t, dummyStepIn(t, inputFolder, runFolder, "./format.sh"))
+
+	if firstKey == "" {
+		t.Fatal("Expected step to be cacheable, but was not")
+	}
+
+	writeFile(t, filepath.Join(runFolder, "format.sh"), "sed -i 's/{ /{/' \"$1\"")
+
+	if scriptKey := key(t, dummyStepIn(t, inputFolder, runFolder, "./format.sh")); scriptKey == firstKey {
+		t.Error("Expected key to change with the script in the working directory, but was equal")
+	}
+}
+
+func TestKey_NotCacheable(t *testing.T) {
+	t.Parallel()
+
+	step := dummyStep(t, "/", "format")
+
+	if stepKey := key(t, step); stepKey != "" {
+		t.Errorf("Expected step including the root to not be cacheable, but key was '%s'", stepKey)
+	}
+
+	if stepKey, _ := uut.Key(dummyStep(t, t.TempDir(), "format"), "", ""); stepKey != "" {
+		t.Errorf("Expected step without recipe hash to not be cacheable, but key was '%s'", stepKey)
+	}
+}
+
+func TestCache_StoreAndRestore(t *testing.T) {
+	t.Parallel()
+
+	cache := uut.NewCache(t.TempDir(), 0)
+	inputFolder := t.TempDir()
+
+	step := dummyStep(t, inputFolder, "format")
+	writeFile(t, filepath.Join(step.ChangeCaptureLocation, "src", "Main.java"), "class Main {}")
+
+	if restored, _ := cache.Restore("key", step); restored {
+		t.Fatal("Expected no entry before storing, but was restored")
+	}
+
+	if err := cache.Store("key", step); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	otherStep := dummyStep(t, inputFolder, "format")
+
+	restored, restoreError := cache.Restore("key", otherStep)
+	if restoreError != nil || !restored {
+		t.Fatalf("Expected entry to be restored, but was %t with error '%v'", restored, restoreError)
+	}
+
+	content, readError := os.ReadFile(filepath.Join(otherStep.ChangeCaptureLocation, "src", "Main.java"))
+	if readError != nil || string(content) != "class Main {}" {
+		t.Errorf("Expected restored file content to be 'class Main {}', but was '%s' (%v)", content, readError)
+	}
+
+	stats, _ := cache.Stats()
+	if stats.Entries != 1 || stats.Size != int64(len("class Main {}")) {
+		t.Errorf("Expected 1 entry of %d bytes, but was %d entries of %d bytes",
+			len("class Main {}"), stats.Entries, stats.Size)
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	t.Parallel()
+
+	cache := uut.NewCache(t.TempDir(), 0)
+	step := dummyStep(t, t.TempDir(), "format")
+	writeFile(t, filepath.Join(step.ChangeCaptureLocation, "file"), "0123456789")
+
+	for _, entryKey := range []string{"old", "new"} {
+		if err := cache.Store(entryKey, step); err != nil {
+			t.Fatalf("Expected no error, but was '%v'", err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	removedEntries, pruneError := cache.Prune(15, 0)
+	if pruneError != nil {
+		t.Fatalf("Expected no error, but was '%v'", pruneError)
+	}
+
+	if removedEntries != 1 {
+		t.Errorf("Expected 1 removed entry, but was %d", removedEntries)
+	}
+
+	entries, _ := cache.Entries()
+	if len(entries) != 1 || entries[0].Key != "new" {
+		t.Errorf("Expected only the entry 'new' to be kept, but was '%v'", entries)
+	}
+
+	if removedEntries, _ = cache.Prune(0, 0); removedEntries != 1 {
+		t.Errorf("Expected the last entry to be removed, but was %d removed entries", removedEntries)
+	}
+}
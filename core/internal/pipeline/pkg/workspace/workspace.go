@@ -0,0 +1,154 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	chastlog "chast.io/core/internal/logger"
+	"github.com/joomcode/errorx"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// DefaultMinimumFreeSpace is the number of bytes which have to be available before a pipeline starts.
+	DefaultMinimumFreeSpace uint64 = 256 * 1024 * 1024
+
+	operationFolder     = "operation"
+	changeCaptureFolder = "changes"
+	cacheFolder         = "cache"
+	defaultRootFolder   = "/"
+	folderPermission    = 0o755
+	lockFilePermission  = 0o600
+)
+
+// Locations configures where pipelines operate and capture their changes.
+type Locations struct {
+	OperationLocation      string
+	ChangeCaptureLocation  string
+	RootFileSystemLocation string
+	// CacheLocation is the folder the changes of steps are cached in.
+	CacheLocation string
+	// MinimumFreeSpace is the number of bytes which have to be available in each location before a pipeline starts.
+	// Zero disables the check.
+	MinimumFreeSpace uint64
+}
+
+// DefaultBaseLocation returns the "chast" folder in the user cache folder ($XDG_CACHE_HOME or ~/.cache), or in the
+// temp folder if there is no user cache folder.
+func DefaultBaseLocation() string {
+	userCacheFolder, userCacheFolderError := os.UserCacheDir()
+	if userCacheFolderError != nil {
+		chastlog.Log.Debugf("No user cache folder, using the temp folder as workspace: %v", userCacheFolderError)
+
+		userCacheFolder = os.TempDir()
+	}
+
+	return filepath.Join(userCacheFolder, "chast")
+}
+
+// NewLocations returns the locations within the base location, or within the default base location if it is empty.
+func NewLocations(baseLocation string) *Locations {
+	if baseLocation == "" {
+		baseLocation = DefaultBaseLocation()
+	}
+
+	return &Locations{
+		OperationLocation:      filepath.Join(baseLocation, operationFolder),
+		ChangeCaptureLocation:  filepath.Join(baseLocation, changeCaptureFolder),
+		RootFileSystemLocation: defaultRootFolder,
+		CacheLocation:          filepath.Join(baseLocation, cacheFolder),
+		MinimumFreeSpace:       DefaultMinimumFreeSpace,
+	}
+}
+
+// CheckFreeSpace creates the operation, change capture and cache locations and fails if less than the minimum free
+// space is available in one of them.
+func (locations *Locations) CheckFreeSpace() error {
+	for _, location := range []string{locations.OperationLocation, locations.ChangeCaptureLocation, locations.CacheLocation} {
+		if location == "" {
+			continue
+		}
+
+		if err := os.MkdirAll(location, folderPermission); err != nil {
+			return errorx.ExternalError.Wrap(err, "Failed to create workspace location %s", location)
+		}
+
+		if locations.MinimumFreeSpace == 0 {
+			continue
+		}
+
+		var stat unix.Statfs_t
+		if err := unix.Statfs(location, &stat); err != nil {
+			return errorx.ExternalError.Wrap(err, "Failed to get free space of %s", location)
+		}
+
+		freeSpace := stat.Bavail * uint64(stat.Bsize) //nolint:unconvert // the type of Bsize depends on the platform
+		if freeSpace < locations.MinimumFreeSpace {
+			return errorx.IllegalState.New(
+				"Only %d MiB are free in %s, but at least %d MiB are required",
+				freeSpace/bytesPerMiB, location, locations.MinimumFreeSpace/bytesPerMiB,
+			)
+		}
+	}
+
+	return nil
+}
+
+const bytesPerMiB = 1024 * 1024
+
+// Lock is an exclusive lock on a pipeline held by a single chast invocation.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock locks the lock file without waiting and fails if another invocation holds the lock.
+func AcquireLock(lockFile string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(lockFile), folderPermission); err != nil {
+		return nil, errorx.ExternalError.Wrap(err, "Failed to create folder of lock file %s", lockFile)
+	}
+
+	file, openError := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, lockFilePermission)
+	if openError != nil {
+		return nil, errorx.ExternalError.Wrap(openError, "Failed to open lock file %s", lockFile)
+	}
+
+	if err := unix.Flock(int(file.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		_ = file.Close()
+
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			return nil, errorx.IllegalState.New("Workspace %s is in use by another chast invocation",
+				filepath.Dir(lockFile))
+		}
+
+		return nil, errorx.ExternalError.Wrap(err, "Failed to lock %s", lockFile)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// IsLocked checks if another invocation holds the lock without acquiring it.
+func IsLocked(lockFile string) (bool, error) {
+	lock, lockError := AcquireLock(lockFile)
+	if lockError != nil {
+		if errorx.IsOfType(lockError, errorx.IllegalState) {
+			return true, nil
+		}
+
+		return false, lockError
+	}
+
+	return false, lock.Release()
+}
+
+func (lock *Lock) Release() error {
+	if err := unix.Flock(int(lock.file.Fd()), unix.LOCK_UN); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to unlock %s", lock.file.Name())
+	}
+
+	if err := lock.file.Close(); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to close lock file %s", lock.file.Name())
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package workspace_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	uut "chast.io/core/internal/pipeline/pkg/workspace"
+)
+
+func TestNewLocations_DefaultHonorsXDGCacheHome(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	locations := uut.NewLocations("")
+
+	expectedOperationLocation := filepath.Join(cacheHome, "chast", "operation")
+	if locations.OperationLocation != expectedOperationLocation {
+		t.Errorf("Expected operation location to be '%s', but was '%s'",
+			expectedOperationLocation, locations.OperationLocation)
+	}
+
+	expectedChangeCaptureLocation := filepath.Join(cacheHome, "chast", "changes")
+	if locations.ChangeCaptureLocation != expectedChangeCaptureLocation {
+		t.Errorf("Expected change capture location to be '%s', but was '%s'",
+			expectedChangeCaptureLocation, locations.ChangeCaptureLocation)
+	}
+
+	if locations.RootFileSystemLocation != "/" {
+		t.Errorf("Expected root file system location to be '/', but was '%s'", locations.RootFileSystemLocation)
+	}
+}
+
+func TestLocations_CheckFreeSpace(t *testing.T) {
+	t.Parallel()
+
+	locations := uut.NewLocations(t.TempDir())
+	locations.MinimumFreeSpace = 1
+
+	if err := locations.CheckFreeSpace(); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if _, err := os.Stat(locations.ChangeCaptureLocation); err != nil {
+		t.Errorf("Expected change capture location to be created, but was '%v'", err)
+	}
+
+	locations.MinimumFreeSpace = math.MaxUint64
+
+	if err := locations.CheckFreeSpace(); err == nil {
+		t.Error("Expected error for insufficient free space, but was nil")
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	t.Parallel()
+
+	lockFile := filepath.Join(t.TempDir(), "pipeline", "lock")
+
+	lock, lockError := uut.AcquireLock(lockFile)
+	if lockError != nil {
+		t.Fatalf("Expected no error, but was '%v'", lockError)
+	}
+
+	if _, err := uut.AcquireLock(lockFile); err == nil {
+		t.Error("Expected error for lock held by another invocation, but was nil")
+	}
+
+	if isLocked, _ := uut.IsLocked(lockFile); !isLocked {
+		t.Error("Expected workspace to be locked, but was not")
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if isLocked, _ := uut.IsLocked(lockFile); isLocked {
+		t.Error("Expected workspace to be unlocked, but was locked")
+	}
+}
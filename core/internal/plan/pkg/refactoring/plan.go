@@ -0,0 +1,560 @@
+package refactoringplan
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"chast.io/core/internal/internal_util/collection"
+	pathreplace "chast.io/core/internal/internal_util/path_replace"
+	treehash "chast.io/core/internal/internal_util/tree_hash"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+// Version is increased whenever the plan format changes incompatibly.
+const Version = 1
+
+// Plan is the serializable form of a resolved refactoring which can be executed later or on another machine.
+type Plan struct {
+	Version    int    `json:"version"`
+	RecipeFile string `json:"recipeFile"`
+	RecipeHash string `json:"recipeHash"`
+	// InputPath is the path the runs were selected for, InputHash the hash of its content.
+	InputPath string `json:"inputPath"`
+	InputHash string `json:"inputHash"`
+	// RecipeRoot and InputRoot are the folders of the recipe and the input. Write writes the paths within them relative
+	// to them, and the roots relative to the folder of the plan, so the plan can be applied in another checkout.
+	RecipeRoot string            `json:"recipeRoot,omitempty"`
+	InputRoot  string            `json:"inputRoot,omitempty"`
+	Variables  map[string]string `json:"variables"`
+	// WorkingDirectory is the directory of the recipe the runs were resolved in.
+	WorkingDirectory    string                           `json:"workingDirectory"`
+	ExecutionGroups     []ExecutionGroup                 `json:"executionGroups"`
+	ClassificationStats *refactoring.ClassificationStats `json:"classificationStats,omitempty"`
+	DependencyDecisions []refactoring.DependencyDecision `json:"dependencyDecisions,omitempty"`
+	// Partial is set if only a selection of the runs of the recipe is planned.
+	Partial bool `json:"partial,omitempty"`
+}
+
+type ExecutionGroup struct {
+	Steps []Step `json:"steps"`
+}
+
+type Step struct {
+	// ID identifies the step within the plan. Dependencies refer to the IDs of previous steps.
+	ID                 string           `json:"id"`
+	RunID              string           `json:"runId,omitempty"`
+	Branch             string           `json:"branch,omitempty"`
+	Dependencies       []string         `json:"dependencies,omitempty"`
+	SupportedLanguages []string         `json:"supportedLanguages,omitempty"`
+	Commands           [][]string       `json:"commands"`
+	WorkingDirectory   string           `json:"workingDirectory"`
+	Docker             *Docker          `json:"docker,omitempty"`
+	Local              *Local           `json:"local,omitempty"`
+	ChangeLocations    *ChangeLocations `json:"changeLocations,omitempty"`
+	// Environment contains the built-in variables passed to the processes of the step.
+	Environment map[string]string `json:"environment,omitempty"`
+	// Timeout limits how long the commands may run, e.g. "1m30s". Empty if they do not time out.
+	Timeout string `json:"timeout,omitempty"`
+	// AllowedExitCodes are exit codes which do not fail a command in addition to zero.
+	AllowedExitCodes []int `json:"allowedExitCodes,omitempty"`
+	// ContinueOnError runs the remaining commands after a command failed and keeps the changes of the step.
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+	// Retry is nil if the step is not attempted again after it failed.
+	Retry *Retry `json:"retry,omitempty"`
+}
+
+type Retry struct {
+	Attempts int `json:"attempts"`
+	// Backoff is waited before each further attempt, e.g. "5s". Empty if further attempts start immediately.
+	Backoff     string `json:"backoff,omitempty"`
+	OnExitCodes []int  `json:"onExitCodes,omitempty"`
+}
+
+type Docker struct {
+	DockerImage string `json:"dockerImage"`
+}
+
+type Local struct {
+	RequiredTools []RequiredTool `json:"requiredTools"`
+}
+
+type RequiredTool struct {
+	Description string `json:"description"`
+	CheckCmd    string `json:"checkCmd"`
+}
+
+type ChangeLocations struct {
+	Include    []string `json:"include,omitempty"`
+	Exclude    []string `json:"exclude,omitempty"`
+	IgnoreRoot string   `json:"ignoreRoot,omitempty"`
+}
+
+// NewPlan creates the plan of the pipeline built for the run model and hashes the recipe and the input.
+func NewPlan(
+	recipeFile string,
+	runModel *refactoring.RunModel,
+	pipeline *refactoringpipelinemodel.Pipeline,
+) (*Plan, error) {
+	recipeHash, recipeHashError := treehash.HashFile(recipeFile)
+	if recipeHashError != nil {
+		return nil, errorx.Decorate(recipeHashError, "Failed to hash recipe")
+	}
+
+	variables := runModel.Variables
+	if variables == nil {
+		variables = runmodel.NewVariables("")
+	}
+
+	inputHash := ""
+
+	if variables.TypeDetectionPath != "" {
+		var inputHashError error
+
+		inputHash, inputHashError = treehash.HashTree(variables.TypeDetectionPath)
+		if inputHashError != nil {
+			return nil, errorx.Decorate(inputHashError, "Failed to hash input")
+		}
+	}
+
+	return &Plan{
+		Version:             Version,
+		RecipeFile:          recipeFile,
+		RecipeHash:          recipeHash,
+		InputPath:           variables.TypeDetectionPath,
+		InputHash:           inputHash,
+		Variables:           variables.Map,
+		WorkingDirectory:    variables.WorkingDirectory,
+		ExecutionGroups:     convertExecutionGroups(pipeline.ExecutionGroups),
+		ClassificationStats: runModel.ClassificationStats,
+		DependencyDecisions: runModel.DependencyDecisions,
+		Partial:             pipeline.Partial,
+	}, nil
+}
+
+// Verify checks that neither the recipe nor the input changed since the plan was created.
+func (plan *Plan) Verify() error {
+	recipeHash, recipeHashError := treehash.HashFile(plan.RecipeFile)
+	if recipeHashError != nil {
+		return errorx.Decorate(recipeHashError, "Failed to hash recipe")
+	}
+
+	if recipeHash != plan.RecipeHash {
+		return errorx.IllegalState.New("Recipe %s changed since the plan was created", plan.RecipeFile)
+	}
+
+	if plan.InputPath == "" {
+		return nil
+	}
+
+	inputHash, inputHashError := treehash.HashTree(plan.InputPath)
+	if inputHashError != nil {
+		return errorx.Decorate(inputHashError, "Failed to hash input")
+	}
+
+	if inputHash != plan.InputHash {
+		return errorx.IllegalState.New("Input %s changed since the plan was created", plan.InputPath)
+	}
+
+	return nil
+}
+
+// ToRunModel restores the run model the plan was created from. The runs get new uuids.
+func (plan *Plan) ToRunModel() (*refactoring.RunModel, error) {
+	return plan.toRunModel(false)
+}
+
+// RestoreRunModel restores the run model the plan was created from, keeping the step ids as uuids of the runs.
+func (plan *Plan) RestoreRunModel() (*refactoring.RunModel, error) {
+	return plan.toRunModel(true)
+}
+
+func (plan *Plan) toRunModel(keepStepIDs bool) (*refactoring.RunModel, error) {
+	runs := make([]*refactoring.Run, 0)
+	runsByStepID := make(map[string]*refactoring.Run)
+
+	for _, executionGroup := range plan.ExecutionGroups {
+		for _, step := range executionGroup.Steps {
+			run, conversionError := convertStep(step, runsByStepID)
+			if conversionError != nil {
+				return nil, conversionError
+			}
+
+			if keepStepIDs {
+				run.SetUUID(step.ID)
+			}
+
+			runsByStepID[step.ID] = run
+			runs = append(runs, run)
+		}
+	}
+
+	variables := runmodel.NewVariables(plan.WorkingDirectory)
+	variables.TypeDetectionPath = plan.InputPath
+
+	for key, value := range plan.Variables {
+		variables.Map[key] = value
+	}
+
+	classificationStats := plan.ClassificationStats
+	if classificationStats == nil {
+		classificationStats = refactoring.NewClassificationStats()
+	}
+
+	return &refactoring.RunModel{
+		Run:                 runs,
+		ClassificationStats: classificationStats,
+		DependencyDecisions: plan.DependencyDecisions,
+		Variables:           variables,
+	}, nil
+}
+
+// Markers replace the roots in the paths of a written plan.
+const (
+	recipeRootMarker = "{{recipeRoot}}"
+	inputRootMarker  = "{{inputRoot}}"
+)
+
+// Write writes the plan as indented JSON. The paths within the folders of the recipe and the input are written
+// relative to these folders, which are written relative to the folder. If the folder is empty, because the location
+// of the plan is not known, the folders are written as they are and the plan can only be applied in the same checkout.
+func Write(plan *Plan, writer io.Writer, folder string) error {
+	recipeRoot := filepath.Dir(plan.RecipeFile)
+	inputRoot := inputRootOf(plan.InputPath)
+
+	// the more specific root is replaced first if one of them is within the other
+	roots := []string{recipeRoot, inputRoot}
+	markers := []string{recipeRootMarker, inputRootMarker}
+
+	if len(inputRoot) > len(recipeRoot) {
+		roots[0], roots[1] = roots[1], roots[0]
+		markers[0], markers[1] = markers[1], markers[0]
+	}
+
+	relativePlan := plan.mapPaths(func(path string) string {
+		for index, root := range roots {
+			if isRelocatable(root) {
+				path = pathreplace.ReplaceFolder(path, root, markers[index])
+			}
+		}
+
+		return path
+	})
+	relativePlan.RecipeRoot = relativePath(recipeRoot, folder)
+	relativePlan.InputRoot = relativePath(inputRoot, folder)
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(relativePlan); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to write plan")
+	}
+
+	return nil
+}
+
+// Read reads a plan written by Write and resolves its paths against the folder the plan was written for.
+func Read(reader io.Reader, folder string) (*Plan, error) {
+	var plan Plan
+
+	if err := json.NewDecoder(reader).Decode(&plan); err != nil {
+		return nil, errorx.IllegalFormat.Wrap(err, "Failed to read plan")
+	}
+
+	if plan.Version != Version {
+		return nil, errorx.IllegalFormat.New("Unsupported plan version %d, expected %d", plan.Version, Version)
+	}
+
+	recipeRoot := resolvePath(plan.RecipeRoot, folder)
+	inputRoot := resolvePath(plan.InputRoot, folder)
+
+	resolvedPlan := plan.mapPaths(func(path string) string {
+		path = strings.ReplaceAll(path, recipeRootMarker, recipeRoot)
+
+		return strings.ReplaceAll(path, inputRootMarker, inputRoot)
+	})
+	resolvedPlan.RecipeRoot = recipeRoot
+	resolvedPlan.InputRoot = inputRoot
+
+	return resolvedPlan, nil
+}
+
+// mapPaths returns a copy of the plan with the values which may contain paths mapped.
+func (plan *Plan) mapPaths(mapPath func(path string) string) *Plan {
+	mappedPlan := *plan
+	mappedPlan.RecipeFile = mapPath(plan.RecipeFile)
+	mappedPlan.InputPath = mapPath(plan.InputPath)
+	mappedPlan.Variables = mapValues(plan.Variables, mapPath)
+	mappedPlan.WorkingDirectory = mapPath(plan.WorkingDirectory)
+	mappedPlan.ExecutionGroups = collection.Map(plan.ExecutionGroups, func(executionGroup ExecutionGroup) ExecutionGroup {
+		return ExecutionGroup{
+			Steps: collection.Map(executionGroup.Steps, func(step Step) Step {
+				return step.mapPaths(mapPath)
+			}),
+		}
+	})
+
+	return &mappedPlan
+}
+
+func (step Step) mapPaths(mapPath func(path string) string) Step {
+	step.Commands = collection.Map(step.Commands, func(command []string) []string {
+		return collection.Map(command, mapPath)
+	})
+	step.WorkingDirectory = mapPath(step.WorkingDirectory)
+	step.Environment = mapValues(step.Environment, mapPath)
+
+	if step.ChangeLocations != nil {
+		step.ChangeLocations = &ChangeLocations{
+			Include:    collection.Map(step.ChangeLocations.Include, mapPath),
+			Exclude:    collection.Map(step.ChangeLocations.Exclude, mapPath),
+			IgnoreRoot: mapPath(step.ChangeLocations.IgnoreRoot),
+		}
+	}
+
+	return step
+}
+
+func mapValues(values map[string]string, mapValue func(value string) string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	mappedValues := make(map[string]string, len(values))
+	for key, value := range values {
+		mappedValues[key] = mapValue(value)
+	}
+
+	return mappedValues
+}
+
+// inputRootOf returns the input path if it is a folder, otherwise the folder containing it.
+func inputRootOf(inputPath string) string {
+	if inputPath == "" {
+		return ""
+	}
+
+	if info, err := os.Stat(inputPath); err == nil && info.IsDir() {
+		return inputPath
+	}
+
+	return filepath.Dir(inputPath)
+}
+
+// isRelocatable reports whether the paths within the root can be written relative to it. Replacing the file system
+// root would replace every path.
+func isRelocatable(root string) bool {
+	return filepath.IsAbs(root) && root != string(filepath.Separator)
+}
+
+// relativePath returns the path relative to the folder. Paths which cannot be made relative, or if the folder is
+// empty, are kept as they are.
+func relativePath(path string, folder string) string {
+	if path == "" || folder == "" || !filepath.IsAbs(path) {
+		return path
+	}
+
+	relative, relativeError := filepath.Rel(folder, path)
+	if relativeError != nil {
+		return path
+	}
+
+	return relative
+}
+
+func resolvePath(path string, folder string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(folder, path)
+}
+
+func convertExecutionGroups(executionGroups []*refactoringpipelinemodel.ExecutionGroup) []ExecutionGroup {
+	convertedExecutionGroups := make([]ExecutionGroup, 0, len(executionGroups))
+
+	for _, executionGroup := range executionGroups {
+		if executionGroup == nil {
+			continue // pipelines without any step contain a single empty group
+		}
+
+		convertedExecutionGroups = append(convertedExecutionGroups, ExecutionGroup{
+			Steps: collection.Map(executionGroup.Steps, convertPipelineStep),
+		})
+	}
+
+	return convertedExecutionGroups
+}
+
+func stepID(step *refactoringpipelinemodel.Step) string {
+	return step.UUID
+}
+
+func convertPipelineStep(step *refactoringpipelinemodel.Step) Step {
+	run := step.RunModel.Run
+
+	return Step{
+		ID:                 step.UUID,
+		RunID:              run.ID,
+		Branch:             run.Branch,
+		Dependencies:       collection.Map(step.Dependencies, stepID),
+		SupportedLanguages: run.SupportedLanguages,
+		Commands:           run.Command.Cmds,
+		WorkingDirectory:   run.Command.WorkingDirectory,
+		Docker:             convertDocker(run.Docker),
+		Local:              convertLocal(run.Local),
+		ChangeLocations:    convertChangeLocations(run.ChangeLocations),
+		Environment:        run.Environment,
+		Timeout:            convertTimeout(run.Command.Timeout),
+		AllowedExitCodes:   run.Command.AllowedExitCodes,
+		ContinueOnError:    run.Command.ContinueOnError,
+		Retry:              convertRetry(run.Command.Retry),
+	}
+}
+
+func convertRetry(retry *refactoring.Retry) *Retry {
+	if retry == nil {
+		return nil
+	}
+
+	return &Retry{
+		Attempts:    retry.Attempts,
+		Backoff:     convertTimeout(retry.Backoff),
+		OnExitCodes: retry.OnExitCodes,
+	}
+}
+
+func convertTimeout(timeout time.Duration) string {
+	if timeout <= 0 {
+		return ""
+	}
+
+	return timeout.String()
+}
+
+func convertDocker(docker *refactoring.Docker) *Docker {
+	if docker == nil {
+		return nil
+	}
+
+	return &Docker{DockerImage: docker.DockerImage}
+}
+
+func convertLocal(local *refactoring.Local) *Local {
+	if local == nil {
+		return nil
+	}
+
+	return &Local{
+		RequiredTools: collection.Map(local.RequiredTools, func(requiredTool refactoring.RequiredTool) RequiredTool {
+			return RequiredTool{Description: requiredTool.Description, CheckCmd: requiredTool.CheckCmd}
+		}),
+	}
+}
+
+func convertChangeLocations(changeLocations *refactoring.ChangeLocations) *ChangeLocations {
+	if changeLocations == nil {
+		return nil
+	}
+
+	return &ChangeLocations{
+		Include:    changeLocations.Include,
+		Exclude:    changeLocations.Exclude,
+		IgnoreRoot: changeLocations.IgnoreRoot,
+	}
+}
+
+func convertStep(step Step, runsByStepID map[string]*refactoring.Run) (*refactoring.Run, error) {
+	dependencies := make([]*refactoring.Run, 0, len(step.Dependencies))
+
+	for _, dependencyID := range step.Dependencies {
+		dependency, isKnown := runsByStepID[dependencyID]
+		if !isKnown {
+			return nil, errorx.IllegalFormat.New("Step %s depends on unknown or later step %s", step.ID, dependencyID)
+		}
+
+		dependencies = append(dependencies, dependency)
+	}
+
+	timeout := time.Duration(0)
+
+	if step.Timeout != "" {
+		var timeoutParseError error
+
+		timeout, timeoutParseError = time.ParseDuration(step.Timeout)
+		if timeoutParseError != nil {
+			return nil, errorx.IllegalFormat.Wrap(timeoutParseError, "Invalid timeout of step %s", step.ID)
+		}
+	}
+
+	run := &refactoring.Run{ //nolint:exhaustruct // uuid is generated on demand
+		ID:                 step.RunID,
+		Dependencies:       dependencies,
+		SupportedLanguages: step.SupportedLanguages,
+		Command: &refactoring.Command{
+			Cmds:             step.Commands,
+			WorkingDirectory: step.WorkingDirectory,
+			Timeout:          timeout,
+			AllowedExitCodes: step.AllowedExitCodes,
+			ContinueOnError:  step.ContinueOnError,
+		},
+		Branch:      step.Branch,
+		Environment: step.Environment,
+	}
+
+	if step.Docker != nil {
+		run.Docker = &refactoring.Docker{DockerImage: step.Docker.DockerImage}
+	}
+
+	if step.Local != nil {
+		run.Local = &refactoring.Local{
+			RequiredTools: collection.Map(step.Local.RequiredTools, func(requiredTool RequiredTool) refactoring.RequiredTool {
+				return refactoring.RequiredTool{Description: requiredTool.Description, CheckCmd: requiredTool.CheckCmd}
+			}),
+		}
+	}
+
+	if step.Retry != nil {
+		retry, retryConvertError := convertStepRetry(step)
+		if retryConvertError != nil {
+			return nil, retryConvertError
+		}
+
+		run.Command.Retry = retry
+	}
+
+	if step.ChangeLocations != nil {
+		run.ChangeLocations = &refactoring.ChangeLocations{
+			Include:    step.ChangeLocations.Include,
+			Exclude:    step.ChangeLocations.Exclude,
+			IgnoreRoot: step.ChangeLocations.IgnoreRoot,
+		}
+	}
+
+	return run, nil
+}
+
+func convertStepRetry(step Step) (*refactoring.Retry, error) {
+	backoff := time.Duration(0)
+
+	if step.Retry.Backoff != "" {
+		var backoffParseError error
+
+		backoff, backoffParseError = time.ParseDuration(step.Retry.Backoff)
+		if backoffParseError != nil {
+			return nil, errorx.IllegalFormat.Wrap(backoffParseError, "Invalid retry backoff of step %s", step.ID)
+		}
+	}
+
+	return &refactoring.Retry{
+		Attempts:    step.Retry.Attempts,
+		Backoff:     backoff,
+		OnExitCodes: step.Retry.OnExitCodes,
+	}, nil
+}
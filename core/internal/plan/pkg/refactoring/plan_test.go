@@ -0,0 +1,310 @@
+package refactoringplan_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	refactoringpipelinebuilder "chast.io/core/internal/pipeline/pkg/builder/refactoring"
+	"chast.io/core/internal/pipeline/pkg/workspace"
+	uut "chast.io/core/internal/plan/pkg/refactoring"
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+)
+
+func createPlan(t *testing.T) (*uut.Plan, string, string) {
+	t.Helper()
+
+	baseDir := t.TempDir()
+	recipeFile := filepath.Join(baseDir, "chast.yml")
+	inputFolder := filepath.Join(baseDir, "src")
+
+	if err := os.WriteFile(recipeFile, []byte("version: 1"), 0o600); err != nil {
+		t.Fatalf("Error writing recipe: %v", err)
+	}
+
+	if err := os.MkdirAll(inputFolder, 0o755); err != nil {
+		t.Fatalf("Error creating input folder: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(inputFolder, "Main.java"), []byte("class Main {}"), 0o600); err != nil {
+		t.Fatalf("Error writing input: %v", err)
+	}
+
+	variables := runmodel.NewVariables(baseDir)
+	variables.TypeDetectionPath = inputFolder
+	variables.Map["inputFolder"] = inputFolder
+
+	format := &refactoring.Run{ //nolint:exhaustruct // not required for test
+		ID:           "format",
+		Dependencies: []*refactoring.Run{},
+		Command: &refactoring.Command{
+			Cmds:             [][]string{{"format", "--in=" + inputFolder}},
+			WorkingDirectory: baseDir,
+		},
+		ChangeLocations: &refactoring.ChangeLocations{Include: []string{inputFolder}}, //nolint:exhaustruct // include only
+		Environment:     map[string]string{"CHAST_INPUT": inputFolder},
+	}
+
+	lint := &refactoring.Run{ //nolint:exhaustruct // not required for test
+		ID:           "lint",
+		Dependencies: []*refactoring.Run{format},
+		Docker:       &refactoring.Docker{DockerImage: "linter"},
+		Command: &refactoring.Command{
+			Cmds:             [][]string{{"lint", "--fix"}},
+			WorkingDirectory: baseDir,
+			Timeout:          90 * time.Second,
+			Retry:            &refactoring.Retry{Attempts: 3, Backoff: 5 * time.Second, OnExitCodes: []int{134}},
+		},
+		ChangeLocations: &refactoring.ChangeLocations{}, //nolint:exhaustruct // not required for test
+	}
+
+	runModel := &refactoring.RunModel{
+		Run:                 []*refactoring.Run{format, lint},
+		ClassificationStats: refactoring.NewClassificationStats(),
+		DependencyDecisions: []refactoring.DependencyDecision{},
+		Variables:           variables,
+	}
+
+	pipeline, pipelineBuildError := refactoringpipelinebuilder.BuildRunPipeline(runModel, workspace.NewLocations("/tmp/chast"))
+	if pipelineBuildError != nil {
+		t.Fatalf("Expected no error, but was '%v'", pipelineBuildError)
+	}
+
+	plan, planBuildError := uut.NewPlan(recipeFile, runModel, pipeline)
+	if planBuildError != nil {
+		t.Fatalf("Expected no error, but was '%v'", planBuildError)
+	}
+
+	return plan, recipeFile, inputFolder
+}
+
+func TestPlan_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	plan, recipeFile, inputFolder := createPlan(t)
+	planFolder := filepath.Dir(recipeFile)
+
+	var buffer bytes.Buffer
+	if err := uut.Write(plan, &buffer, planFolder); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	readPlan, readError := uut.Read(&buffer, planFolder)
+	if readError != nil {
+		t.Fatalf("Expected no error, but was '%v'", readError)
+	}
+
+	if err := readPlan.Verify(); err != nil {
+		t.Fatalf("Expected unchanged plan to verify, but was '%v'", err)
+	}
+
+	runModel, restoreError := readPlan.ToRunModel()
+	if restoreError != nil {
+		t.Fatalf("Expected no error, but was '%v'", restoreError)
+	}
+
+	if len(runModel.Run) != 2 {
+		t.Fatalf("Expected 2 runs, but was %d", len(runModel.Run))
+	}
+
+	format, lint := runModel.Run[0], runModel.Run[1]
+
+	expectedCmds := [][]string{{"format", "--in=" + inputFolder}}
+	if !reflect.DeepEqual(format.Command.Cmds, expectedCmds) {
+		t.Errorf("Expected commands to be '%v', but was '%v'", expectedCmds, format.Command.Cmds)
+	}
+
+	if len(lint.Dependencies) != 1 || lint.Dependencies[0] != format {
+		t.Errorf("Expected lint to depend on format, but was '%v'", lint.Dependencies)
+	}
+
+	if lint.Command.Timeout != 90*time.Second {
+		t.Errorf("Expected timeout to be 1m30s, but was %s", lint.Command.Timeout)
+	}
+
+	expectedRetry := &refactoring.Retry{Attempts: 3, Backoff: 5 * time.Second, OnExitCodes: []int{134}}
+	if !reflect.DeepEqual(lint.Command.Retry, expectedRetry) {
+		t.Errorf("Expected retry to be %+v, but was %+v", expectedRetry, lint.Command.Retry)
+	}
+
+	if format.Command.Retry != nil {
+		t.Errorf("Expected format not to be retried, but was %+v", format.Command.Retry)
+	}
+
+	if lint.Docker == nil || lint.Docker.DockerImage != "linter" {
+		t.Errorf("Expected docker image to be 'linter', but was '%v'", lint.Docker)
+	}
+
+	if runModel.Variables.Map["inputFolder"] != inputFolder {
+		t.Errorf("Expected variable to be '%s', but was '%s'", inputFolder, runModel.Variables.Map["inputFolder"])
+	}
+}
+
+func TestPlan_Relocated(t *testing.T) {
+	t.Parallel()
+
+	plan, recipeFile, _ := createPlan(t)
+	planFolder := filepath.Dir(recipeFile)
+
+	var buffer bytes.Buffer
+	if err := uut.Write(plan, &buffer, planFolder); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if bytes.Contains(buffer.Bytes(), []byte(planFolder)) {
+		t.Errorf("Expected no absolute paths of the checkout, but was '%s'", buffer.String())
+	}
+
+	var writtenPlan uut.Plan
+	if err := json.Unmarshal(buffer.Bytes(), &writtenPlan); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if writtenPlan.RecipeRoot != "." || writtenPlan.InputRoot != "src" {
+		t.Errorf("Expected roots relative to the plan, but were '%s' and '%s'",
+			writtenPlan.RecipeRoot, writtenPlan.InputRoot)
+	}
+
+	relocatedFolder := filepath.Join(t.TempDir(), "checkout")
+	if err := os.Rename(planFolder, relocatedFolder); err != nil {
+		t.Fatalf("Error moving checkout: %v", err)
+	}
+
+	readPlan, readError := uut.Read(&buffer, relocatedFolder)
+	if readError != nil {
+		t.Fatalf("Expected no error, but was '%v'", readError)
+	}
+
+	checkPathsWithin(t, readPlan, relocatedFolder)
+}
+
+// checkPathsWithin checks that the paths of a plan created by createPlan point into the folder.
+func checkPathsWithin(t *testing.T, plan *uut.Plan, folder string) {
+	t.Helper()
+
+	inputFolder := filepath.Join(folder, "src")
+
+	if expectedRecipeFile := filepath.Join(folder, "chast.yml"); plan.RecipeFile != expectedRecipeFile {
+		t.Errorf("Expected recipe file to be '%s', but was '%s'", expectedRecipeFile, plan.RecipeFile)
+	}
+
+	if err := plan.Verify(); err != nil {
+		t.Errorf("Expected relocated plan to verify, but was '%v'", err)
+	}
+
+	runModel, restoreError := plan.ToRunModel()
+	if restoreError != nil {
+		t.Fatalf("Expected no error, but was '%v'", restoreError)
+	}
+
+	format := runModel.Run[0]
+
+	expectedCmds := [][]string{{"format", "--in=" + inputFolder}}
+	if !reflect.DeepEqual(format.Command.Cmds, expectedCmds) {
+		t.Errorf("Expected commands to be '%v', but was '%v'", expectedCmds, format.Command.Cmds)
+	}
+
+	if format.Command.WorkingDirectory != folder {
+		t.Errorf("Expected working directory to be '%s', but was '%s'", folder, format.Command.WorkingDirectory)
+	}
+
+	if expectedInclude := []string{inputFolder}; !reflect.DeepEqual(format.ChangeLocations.Include, expectedInclude) {
+		t.Errorf("Expected change locations to be '%v', but was '%v'", expectedInclude, format.ChangeLocations.Include)
+	}
+
+	if format.Environment["CHAST_INPUT"] != inputFolder {
+		t.Errorf("Expected environment to be '%s', but was '%s'", inputFolder, format.Environment["CHAST_INPUT"])
+	}
+
+	if runModel.Variables.WorkingDirectory != folder || runModel.Variables.Map["inputFolder"] != inputFolder {
+		t.Errorf("Expected variables within '%s', but were '%s' and '%v'",
+			folder, runModel.Variables.WorkingDirectory, runModel.Variables.Map)
+	}
+}
+
+// TestPlan_WrittenToStdout checks plans whose location is not known when they are written, their paths are kept.
+func TestPlan_WrittenToStdout(t *testing.T) {
+	t.Parallel()
+
+	plan, recipeFile, _ := createPlan(t)
+
+	var buffer bytes.Buffer
+	if err := uut.Write(plan, &buffer, ""); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	// the output is saved to a plan file in another folder
+	readPlan, readError := uut.Read(&buffer, filepath.Join(t.TempDir(), "sub"))
+	if readError != nil {
+		t.Fatalf("Expected no error, but was '%v'", readError)
+	}
+
+	checkPathsWithin(t, readPlan, filepath.Dir(recipeFile))
+}
+
+func TestPlan_Verify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should refuse changed recipe", func(t *testing.T) {
+		t.Parallel()
+
+		plan, recipeFile, _ := createPlan(t)
+
+		if err := os.WriteFile(recipeFile, []byte("version: 2"), 0o600); err != nil {
+			t.Fatalf("Error writing recipe: %v", err)
+		}
+
+		if err := plan.Verify(); err == nil {
+			t.Error("Expected error, but was nil")
+		}
+	})
+
+	t.Run("should refuse changed input", func(t *testing.T) {
+		t.Parallel()
+
+		plan, _, inputFolder := createPlan(t)
+
+		if err := os.WriteFile(filepath.Join(inputFolder, "Other.java"), []byte{}, 0o600); err != nil {
+			t.Fatalf("Error writing input: %v", err)
+		}
+
+		if err := plan.Verify(); err == nil {
+			t.Error("Expected error, but was nil")
+		}
+	})
+}
+
+func TestRead_UnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	if _, err := uut.Read(bytes.NewBufferString(`{"version": 0}`), ""); err == nil {
+		t.Error("Expected error, but was nil")
+	}
+}
+
+func TestPlan_RestoreRunModel(t *testing.T) {
+	t.Parallel()
+
+	plan, _, _ := createPlan(t)
+
+	runModel, restoreError := plan.RestoreRunModel()
+	if restoreError != nil {
+		t.Fatalf("Expected no error, but was '%v'", restoreError)
+	}
+
+	for index, step := range plan.ExecutionGroups {
+		if actualUUID := runModel.Run[index].GetUUID(); actualUUID != step.Steps[0].ID {
+			t.Errorf("Expected run uuid to be '%s', but was '%s'", step.Steps[0].ID, actualUUID)
+		}
+	}
+
+	freshRunModel, _ := plan.ToRunModel()
+	if freshUUID := freshRunModel.Run[0].GetUUID(); freshUUID == plan.ExecutionGroups[0].Steps[0].ID {
+		t.Errorf("Expected run uuid to differ from '%s', but was equal", freshUUID)
+	}
+}
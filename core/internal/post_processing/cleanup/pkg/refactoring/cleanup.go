@@ -31,6 +31,25 @@ func CleanupStep(step *refactoringpipelinemodel.Step) error {
 	return cleanupStep(step, false)
 }
 
+// ResetStep removes all locations of the step, including its captured changes, so it can run again.
+func ResetStep(step *refactoringpipelinemodel.Step) error {
+	return cleanupStep(step, true)
+}
+
+// DiscardAttempt removes the changes captured by a failed attempt of the step, so the next attempt starts with a clean
+// isolated layer. The changes of the dependencies of the step are kept.
+func DiscardAttempt(step *refactoringpipelinemodel.Step) error {
+	if err := os.RemoveAll(step.OperationLocation); err != nil {
+		return errorx.ExternalError.Wrap(err, "failed to remove step operation directory")
+	}
+
+	if err := os.RemoveAll(step.ChangeCaptureLocation); err != nil {
+		return errorx.ExternalError.Wrap(err, "failed to remove step change capture directory")
+	}
+
+	return nil
+}
+
 func cleanupStep(step *refactoringpipelinemodel.Step, clearChangeCaptureLocations bool) error {
 	cumulatedErrors := make([]error, 0)
 
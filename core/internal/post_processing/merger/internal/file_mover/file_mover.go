@@ -21,7 +21,8 @@ func MoveFile(
 	osFileSystem afero.Fs,
 	options *mergeoptions.MergeOptions,
 ) error {
-	if exists, err := afero.Exists(osFileSystem, sourcePath); err != nil || !exists {
+	// symbolic links are moved themselves, even if their target does not exist (anymore)
+	if _, err := os.Lstat(sourcePath); err != nil {
 		return nil //nolint:nilerr // If the folder does not exist, ignore it and continue
 	}
 
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"chast.io/core/internal/internal_util/collection"
+	"chast.io/core/internal/internal_util/ignore"
 	wildcardstring "chast.io/core/internal/internal_util/wildcard_string"
 	chastlog "chast.io/core/internal/logger"
 	"chast.io/core/internal/post_processing/merger/internal/dirmerger"
@@ -57,7 +58,8 @@ func MergeFolders(mergeEntities []MergeEntity, targetFolder string, options *mer
 	return nil
 }
 
-func mergeFolders(mergeEntity MergeEntity, targetFolder string, options *mergeoptions.MergeOptions) error {
+// EntityMergeOptions returns the options augmented with the change locations of the merge entity.
+func EntityMergeOptions(mergeEntity MergeEntity, options *mergeoptions.MergeOptions) *mergeoptions.MergeOptions {
 	entityMergeOptions := *options
 	entityMergeOptions.Inclusions = append(
 		entityMergeOptions.Inclusions,
@@ -68,6 +70,16 @@ func mergeFolders(mergeEntity MergeEntity, targetFolder string, options *mergeop
 		collection.Map(mergeEntity.ChangeLocations.Exclude, wildcardstring.NewWildcardString)...,
 	)
 
+	if mergeEntity.ChangeLocations.IgnoreRoot != "" {
+		entityMergeOptions.Ignore = ignore.NewMatcher(mergeEntity.ChangeLocations.IgnoreRoot)
+	}
+
+	return &entityMergeOptions
+}
+
+func mergeFolders(mergeEntity MergeEntity, targetFolder string, options *mergeoptions.MergeOptions) error {
+	entityMergeOptions := *EntityMergeOptions(mergeEntity, options)
+
 	sourceFolder := mergeEntity.SourcePath
 
 	if err := mergeSourceIntoTarget(sourceFolder, targetFolder, &entityMergeOptions); err != nil {
@@ -2,6 +2,7 @@ package dirmerger_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	testhelper "chast.io/core/internal/post_processing/merger/internal/test_helpers"
@@ -560,3 +561,41 @@ func TestAreMergeable(t *testing.T) {
 		}
 	})
 }
+
+func TestMergeFolders_Symlinks(t *testing.T) {
+	t.Parallel()
+
+	sourceFolder := testhelper.FileStructureCreator([]string{"/folder/file"}, "MergeFoldersTestSymlinks")
+	targetFolder := testhelper.FileStructureCreator(make([]string, 0), "MergeFoldersTestSymlinks")
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(sourceFolder)
+		_ = os.RemoveAll(targetFolder)
+	})
+
+	// the target of the first link is moved before the link, the target of the second one does not exist
+	if err := os.Symlink("file", filepath.Join(sourceFolder, "folder", "link")); err != nil {
+		t.Fatalf("Error creating symlink: %v", err)
+	}
+
+	if err := os.Symlink("missing", filepath.Join(sourceFolder, "folder", "missingLink")); err != nil {
+		t.Fatalf("Error creating symlink: %v", err)
+	}
+
+	mergeEntities := dirmerger.NewMergeEntity(sourceFolder, nil)
+
+	if err := dirmerger.MergeFolders(
+		[]dirmerger.MergeEntity{mergeEntities},
+		targetFolder,
+		mergeoptions.NewMergeOptions(),
+	); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	for link, expectedTarget := range map[string]string{"link": "file", "missingLink": "missing"} {
+		linkTarget, err := os.Readlink(filepath.Join(targetFolder, "folder", link))
+		if err != nil || linkTarget != expectedTarget {
+			t.Errorf("Expected %s to be moved and link to '%s', but was '%s' (%v)", link, expectedTarget, linkTarget, err)
+		}
+	}
+}
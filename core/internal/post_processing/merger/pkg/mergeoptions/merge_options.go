@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 
+	"chast.io/core/internal/internal_util/ignore"
 	wildcardstring "chast.io/core/internal/internal_util/wildcard_string"
 )
 
@@ -22,6 +23,8 @@ type MergeOptions struct {
 	FolderPermission          os.FileMode
 	Exclusions                []*wildcardstring.WildcardString
 	Inclusions                []*wildcardstring.WildcardString
+	// Ignore skips paths ignored by .gitignore and .chastignore files. Nil if ignore files are not respected.
+	Ignore *ignore.Matcher
 }
 
 func NewMergeOptions() *MergeOptions {
@@ -39,6 +42,8 @@ func NewMergeOptions() *MergeOptions {
 
 		Exclusions: []*wildcardstring.WildcardString{},
 		Inclusions: []*wildcardstring.WildcardString{},
+
+		Ignore: nil,
 	}
 }
 
@@ -70,5 +75,5 @@ func (o *MergeOptions) ShouldSkip(location string) bool {
 		}
 	}
 
-	return false
+	return o.Ignore != nil && o.Ignore.IsIgnored(cleanedLocation, false)
 }
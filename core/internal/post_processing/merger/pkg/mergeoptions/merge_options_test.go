@@ -1,8 +1,11 @@
 package mergeoptions_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"chast.io/core/internal/internal_util/ignore"
 	wildcardstring "chast.io/core/internal/internal_util/wildcard_string"
 	"chast.io/core/internal/post_processing/merger/pkg/mergeoptions"
 )
@@ -208,3 +211,37 @@ func TestMergeOptions_ShouldSkip(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeOptions_ShouldSkip_Ignored(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("build/\n*.log\n"), 0o600); err != nil {
+		t.Fatalf("Error writing ignore file: %v", err)
+	}
+
+	options := mergeoptions.NewMergeOptions()
+	options.Ignore = ignore.NewMatcher(root)
+
+	tests := []struct {
+		location string
+		want     bool
+	}{
+		{location: filepath.Join(root, "build", "out.txt"), want: true},
+		{location: filepath.Join(root, "debug.log"), want: true},
+		{location: filepath.Join(root, "debug.log_HIDDEN~"), want: true},
+		{location: filepath.Join(root, "src", "main.go"), want: false},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.location, func(t *testing.T) {
+			t.Parallel()
+
+			if got := options.ShouldSkip(testCase.location); got != testCase.want {
+				t.Errorf("Expected ShouldSkip(%s) to be %v, but was %v", testCase.location, testCase.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package pipelinepostprocessor
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"chast.io/core/internal/internal_util/collection"
+	wildcardstring "chast.io/core/internal/internal_util/wildcard_string"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"chast.io/core/internal/post_processing/merger/pkg/dirmerger"
+	"chast.io/core/internal/post_processing/merger/pkg/mergeoptions"
+	"github.com/joomcode/errorx"
+)
+
+// FindConflicts returns all paths which are changed by more than one of the final steps, sorted by path.
+func FindConflicts(finalSteps []*refactoringpipelinemodel.Step) ([]refactoringpipelinemodel.MergeConflict, error) {
+	changingSteps := make(map[string][]string)
+
+	for _, step := range finalSteps {
+		changedPaths, collectError := collectChangedPaths(step)
+		if collectError != nil {
+			return nil, collectError
+		}
+
+		for _, changedPath := range changedPaths {
+			changingSteps[changedPath] = append(changingSteps[changedPath], stepLabel(step))
+		}
+	}
+
+	conflicts := make([]refactoringpipelinemodel.MergeConflict, 0)
+
+	for path, steps := range changingSteps {
+		if len(steps) > 1 {
+			conflicts = append(conflicts, refactoringpipelinemodel.MergeConflict{Path: path, Steps: steps})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+
+	return conflicts, nil
+}
+
+// conflictExclusions excludes the conflicting paths from the merge, so none of the conflicting changes is applied.
+func conflictExclusions(conflicts []refactoringpipelinemodel.MergeConflict) []*wildcardstring.WildcardString {
+	return collection.Map(conflicts, func(conflict refactoringpipelinemodel.MergeConflict) *wildcardstring.WildcardString {
+		return wildcardstring.NewWildcardString(conflict.Path)
+	})
+}
+
+func collectChangedPaths(step *refactoringpipelinemodel.Step) ([]string, error) {
+	options := dirmerger.EntityMergeOptions(
+		dirmerger.NewMergeEntity(step.GetFinalChangesLocation(), step.ChangeFilteringLocations()),
+		mergeoptions.NewMergeOptions(),
+	)
+
+	sourceFolder := step.GetFinalChangesLocation()
+	changedPaths := make([]string, 0)
+
+	if walkError := filepath.WalkDir(sourceFolder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == sourceFolder {
+			return err
+		}
+
+		relativePath := strings.TrimPrefix(path, sourceFolder)
+		isDeletionMarker := strings.HasSuffix(relativePath, options.MetaFilesDeletedExtension)
+
+		if entry.IsDir() && !isDeletionMarker {
+			return nil
+		}
+
+		if !options.ShouldSkip(relativePath) {
+			changedPaths = append(changedPaths, strings.TrimSuffix(relativePath, options.MetaFilesDeletedExtension))
+		}
+
+		if entry.IsDir() {
+			return filepath.SkipDir
+		}
+
+		return nil
+	}); walkError != nil {
+		return nil, errorx.ExternalError.Wrap(walkError, "Failed to collect changes of step %s", step.UUID)
+	}
+
+	return changedPaths, nil
+}
+
+func stepLabel(step *refactoringpipelinemodel.Step) string {
+	run := step.RunModel.Run
+
+	label := run.ID
+	if label == "" {
+		label = step.UUID
+	}
+
+	if run.Branch != "" {
+		label = run.Branch + "/" + label
+	}
+
+	return label
+}
@@ -0,0 +1,100 @@
+package pipelinepostprocessor_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	uut "chast.io/core/internal/post_processing/pipeline_post_processor/pkg/refactoring"
+)
+
+func writeFinalChange(t *testing.T, step *refactoringpipelinemodel.Step, path string) {
+	t.Helper()
+
+	fullPath := filepath.Join(step.GetFinalChangesLocation(), path)
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
+		t.Fatalf("Error creating folder: %v", err)
+	}
+
+	if err := os.WriteFile(fullPath, []byte("change"), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	t.Parallel()
+
+	step1 := dummyStep(1)
+	step1.RunModel.Run.Branch = "batch-1"
+	step2 := dummyStep(1)
+	step2.RunModel.Run.Branch = "batch-2"
+	step3 := dummyStep(3)
+
+	pipeline := dummyPipeline([]*refactoringpipelinemodel.ExecutionGroup{
+		dummyExecutionGroup([]*refactoringpipelinemodel.Step{step1, step2, step3}),
+	})
+	defer os.RemoveAll(filepath.Dir(pipeline.OperationLocation))
+
+	writeFinalChange(t, step1, "/src/A.java")
+	writeFinalChange(t, step1, "/src/Shared.java")
+	writeFinalChange(t, step2, "/src/B.java")
+	writeFinalChange(t, step2, "/src/Shared.java_HIDDEN~")
+	writeFinalChange(t, step3, "/src/C.java")
+
+	conflicts, err := uut.FindConflicts(pipeline.GetFinalSteps())
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	expected := []refactoringpipelinemodel.MergeConflict{
+		{Path: "/src/Shared.java", Steps: []string{"batch-1/runId1", "batch-2/runId1"}},
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("Expected conflicts to be '%v', but was '%v'", expected, conflicts)
+	}
+}
+
+func TestProcess_Conflicts(t *testing.T) {
+	t.Parallel()
+
+	step1 := dummyStep(1)
+	step1.RunModel.Run.Branch = "batch-1"
+	step2 := dummyStep(1)
+	step2.RunModel.Run.Branch = "batch-2"
+
+	pipeline := dummyPipeline([]*refactoringpipelinemodel.ExecutionGroup{
+		dummyExecutionGroup([]*refactoringpipelinemodel.Step{step1, step2}),
+	})
+	defer os.RemoveAll(filepath.Dir(pipeline.OperationLocation))
+
+	writeFinalChange(t, step1, "/src/A.java")
+	writeFinalChange(t, step1, "/src/Shared.java")
+	writeFinalChange(t, step2, "/src/B.java")
+	writeFinalChange(t, step2, "/src/Shared.java")
+
+	if err := uut.Process(pipeline); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	expectedConflicts := []refactoringpipelinemodel.MergeConflict{
+		{Path: "/src/Shared.java", Steps: []string{"batch-1/runId1", "batch-2/runId1"}},
+	}
+	if !reflect.DeepEqual(pipeline.Conflicts, expectedConflicts) {
+		t.Errorf("Expected conflicts to be '%v', but was '%v'", expectedConflicts, pipeline.Conflicts)
+	}
+
+	mergedPaths, err := collectPathsInFolder(pipeline.GetFinalChangeCaptureLocation())
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	sort.Strings(mergedPaths)
+
+	if expectedPaths := []string{"/src/A.java", "/src/B.java"}; !reflect.DeepEqual(mergedPaths, expectedPaths) {
+		t.Errorf("Expected merged paths to be '%v', but was '%v'", expectedPaths, mergedPaths)
+	}
+}
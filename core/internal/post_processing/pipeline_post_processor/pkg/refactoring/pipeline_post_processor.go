@@ -2,6 +2,8 @@ package pipelinepostprocessor
 
 import (
 	"chast.io/core/internal/internal_util/collection"
+	chastlog "chast.io/core/internal/logger"
+	"chast.io/core/internal/pipeline/pkg/event"
 	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
 	refactoringpipelinecleanup "chast.io/core/internal/post_processing/cleanup/pkg/refactoring"
 	"chast.io/core/internal/post_processing/merger/pkg/dirmerger"
@@ -15,15 +17,33 @@ func Process(pipeline *refactoringpipelinemodel.Pipeline) error {
 	}
 
 	// Merge all changes from the final steps into the change capture location of the pipeline.
-	// No overwrites must happen
+	// No overwrites must happen. The changes of the steps are copied, so a failed merge can be resumed.
 	options := mergeoptions.NewMergeOptions()
 	options.BlockOverwrite = true
-	options.CopyMode = false
+	options.CopyMode = true
 	options.MergeMetaFilesFolder = true
 	options.DeleteEmptyFolders = false
 
 	targetFolder := pipeline.GetFinalChangeCaptureLocation()
 
+	conflicts, conflictDetectionError := FindConflicts(pipeline.GetFinalSteps())
+	if conflictDetectionError != nil {
+		return errorx.InternalError.Wrap(conflictDetectionError, "failed to detect conflicting changes")
+	}
+
+	// conflicting paths are reported instead of failing the pipeline, the other changes are merged
+	pipeline.Conflicts = conflicts
+
+	for _, conflict := range conflicts {
+		pipeline.Notify(event.Event{ //nolint:exhaustruct // only the fields of the event type are set
+			Type:          event.MergeConflict,
+			Path:          conflict.Path,
+			ConflictSteps: conflict.Steps,
+		})
+	}
+
+	options.Exclusions = append(options.Exclusions, conflictExclusions(conflicts)...)
+
 	cumulatedErrors := make([]error, 0)
 
 	mergeEntities := collection.Map(pipeline.GetFinalSteps(),
@@ -45,6 +65,18 @@ func Process(pipeline *refactoringpipelinemodel.Pipeline) error {
 			errorx.InternalError, "failed to cleanup pipeline", cumulatedErrors...)
 	}
 
+	if pipeline.Partial {
+		// the changes of the steps are kept for inspection, the pipeline is removed by "chast pipelines rm"
+		for _, executionGroup := range pipeline.ExecutionGroups {
+			for _, step := range executionGroup.Steps {
+				chastlog.Log.Printf("Keeping changes of step %s (%s) in %s",
+					step.RunModel.Run.ID, step.UUID, step.GetFinalChangesLocation())
+			}
+		}
+
+		return nil
+	}
+
 	if err := refactoringpipelinecleanup.CleanupPipeline(pipeline); err != nil {
 		return errorx.InternalError.Wrap(err, "Failed to cleanup pipeline")
 	}
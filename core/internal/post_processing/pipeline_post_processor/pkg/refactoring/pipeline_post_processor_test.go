@@ -265,7 +265,7 @@ func TestProcess(t *testing.T) {
 			},
 		},
 		{
-			name: "should return error if the same file is edited in same stage",
+			name: "should leave out the same file edited in same stage",
 			args: args{
 				getPipeline: func() *refactoringpipelinemodel.Pipeline {
 					return dummyPipeline(
@@ -280,15 +280,15 @@ func TestProcess(t *testing.T) {
 					)
 				},
 			},
-			wantErr: true,
+			wantErr: false,
 			changedPaths: [][]string{
 				{"/folder1/file.go"},
 				{"/folder1/file.go"},
 			},
-			expectedFileStructure: nil,
+			expectedFileStructure: []string{},
 		},
 		{
-			name: "should return error if the same file is edited and deleted in same stage",
+			name: "should leave out the same file edited and deleted in same stage",
 			args: args{
 				getPipeline: func() *refactoringpipelinemodel.Pipeline {
 					return dummyPipeline(
@@ -303,12 +303,12 @@ func TestProcess(t *testing.T) {
 					)
 				},
 			},
-			wantErr: true,
+			wantErr: false,
 			changedPaths: [][]string{
 				{"/folder1/file.go"},
 				{"/folder1/file.go_HIDDEN~"},
 			},
-			expectedFileStructure: nil,
+			expectedFileStructure: []string{},
 		},
 		{
 			name: "should be able to create deleted path",
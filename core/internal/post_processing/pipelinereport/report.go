@@ -1,26 +1,37 @@
 package pipelinereport
 
 import (
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	chastlog "chast.io/core/internal/logger"
+	"chast.io/core/internal/pipeline/pkg/metrics"
 	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
 	"chast.io/core/internal/post_processing/pipelinereport/internal/diff"
 	filetree "chast.io/core/internal/post_processing/pipelinereport/internal/tree"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
 	"github.com/joomcode/errorx"
 	"github.com/spf13/afero"
 )
 
 type Report struct {
-	ChangedPaths []string
-	ChangeDiff   *diff.ChangeDiff
-	Pipeline     *refactoringpipelinemodel.Pipeline
+	ChangedPaths        []string
+	ChangeDiff          *diff.ChangeDiff
+	Pipeline            *refactoringpipelinemodel.Pipeline
+	ClassificationStats *refactoring.ClassificationStats
+	DependencyDecisions []refactoring.DependencyDecision
+	// DiffBuilding is the wall time it took to build the report.
+	DiffBuilding time.Duration
 }
 
 func BuildReport(pipeline *refactoringpipelinemodel.Pipeline) (*Report, error) {
+	startTime := time.Now()
 	changedPaths := make([]string, 0)
 
 	osFileSystem := afero.NewOsFs()
@@ -56,9 +67,12 @@ func BuildReport(pipeline *refactoringpipelinemodel.Pipeline) (*Report, error) {
 	}
 
 	return &Report{
-		ChangedPaths: changedPaths,
-		ChangeDiff:   changeDiff,
-		Pipeline:     pipeline,
+		ChangedPaths:        changedPaths,
+		ChangeDiff:          changeDiff,
+		Pipeline:            pipeline,
+		ClassificationStats: pipeline.ClassificationStats,
+		DependencyDecisions: pipeline.DependencyDecisions,
+		DiffBuilding:        time.Since(startTime),
 	}, nil
 }
 
@@ -98,3 +112,229 @@ func (report *Report) PrintFileTree(colorize bool) {
 func (report *Report) PrintChanges(colorize bool) {
 	chastlog.Log.Println(report.ChangeDiff.ToString(colorize))
 }
+
+func (report *Report) ClassificationStatsToString() string {
+	stats := report.ClassificationStats
+	if stats == nil {
+		return ""
+	}
+
+	var stringBuilder strings.Builder
+
+	stringBuilder.WriteString(fmt.Sprintf("Classified %d files (%d ignored paths)", stats.ScannedFiles, stats.IgnoredPaths))
+	stringBuilder.WriteString(countsToString(stats.Extensions))
+	stringBuilder.WriteString("\nMatching files per run")
+	stringBuilder.WriteString(countsToString(stats.MatchedFiles))
+
+	return stringBuilder.String()
+}
+
+func (report *Report) PrintClassificationStats() {
+	chastlog.Log.Debugln(report.ClassificationStatsToString())
+}
+
+func (report *Report) DependencyDecisionsToString() string {
+	if len(report.DependencyDecisions) == 0 {
+		return ""
+	}
+
+	var stringBuilder strings.Builder
+
+	stringBuilder.WriteString("Dependencies on runs which are not selected")
+
+	for _, decision := range report.DependencyDecisions {
+		runID := decision.RunID
+		if decision.Branch != "" {
+			runID = decision.Branch + "/" + runID
+		}
+
+		outcome := "ordering dropped"
+		if decision.Skipped {
+			outcome = "skipped"
+		}
+
+		stringBuilder.WriteString(fmt.Sprintf("\n  %s (%s '%s'): %s", runID, decision.Kind, decision.DependencyID, outcome))
+	}
+
+	return stringBuilder.String()
+}
+
+func (report *Report) PrintDependencyDecisions() {
+	if decisions := report.DependencyDecisionsToString(); decisions != "" {
+		chastlog.Log.Println(decisions)
+	}
+}
+
+// ConflictsToString lists the paths changed by more than one final step, none of their changes is applied.
+func (report *Report) ConflictsToString() string {
+	if len(report.Pipeline.Conflicts) == 0 {
+		return ""
+	}
+
+	var stringBuilder strings.Builder
+
+	stringBuilder.WriteString("Conflicting changes of multiple final steps, which are not applied")
+
+	for _, conflict := range report.Pipeline.Conflicts {
+		stringBuilder.WriteString(fmt.Sprintf("\n  %s (%s)", conflict.Path, strings.Join(conflict.Steps, ", ")))
+	}
+
+	return stringBuilder.String()
+}
+
+func (report *Report) PrintConflicts() {
+	if conflicts := report.ConflictsToString(); conflicts != "" {
+		chastlog.Log.Warnln(conflicts)
+	}
+}
+
+// FailedCommandsToString lists the failed commands of the runs which continued on errors.
+func (report *Report) FailedCommandsToString() string {
+	var stringBuilder strings.Builder
+
+	for _, executionGroup := range report.Pipeline.ExecutionGroups {
+		for _, step := range executionGroup.Steps {
+			for _, result := range step.CommandResults {
+				if !result.Failed {
+					continue
+				}
+
+				stringBuilder.WriteString(fmt.Sprintf("\n  %s: \"%s\" exited with %d (%s)",
+					step.RunModel.Run.ID, result.CommandString(), result.ExitCode, result.Error))
+			}
+		}
+	}
+
+	if stringBuilder.Len() == 0 {
+		return ""
+	}
+
+	return "Failed commands of runs which continued on errors" + stringBuilder.String()
+}
+
+func (report *Report) PrintFailedCommands() {
+	if failedCommands := report.FailedCommandsToString(); failedCommands != "" {
+		chastlog.Log.Println(failedCommands)
+	}
+}
+
+// LogFilesToString lists the files the output of the commands of the steps was logged to.
+func (report *Report) LogFilesToString() string {
+	var stringBuilder strings.Builder
+
+	for _, executionGroup := range report.Pipeline.ExecutionGroups {
+		for _, step := range executionGroup.Steps {
+			if _, err := os.Stat(step.GetLogFile()); err != nil {
+				continue
+			}
+
+			stringBuilder.WriteString(fmt.Sprintf("\n  %s: %s", step.RunModel.Run.ID, step.GetLogFile()))
+		}
+	}
+
+	if stringBuilder.Len() == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("Logs of the steps, shown with \"chast logs %s [step]\"", report.Pipeline.UUID) +
+		stringBuilder.String()
+}
+
+func (report *Report) PrintLogFiles() {
+	if logFiles := report.LogFilesToString(); logFiles != "" {
+		chastlog.Log.Println(logFiles)
+	}
+}
+
+// Timings returns the timings of the phases of all steps, of the pipeline and of building the report.
+func (report *Report) Timings() []metrics.PhaseTiming {
+	timings := make([]metrics.PhaseTiming, 0)
+
+	for _, executionGroup := range report.Pipeline.ExecutionGroups {
+		for _, step := range executionGroup.Steps {
+			timings = append(timings, step.Timings...)
+		}
+	}
+
+	timings = append(timings, report.Pipeline.Timings...)
+
+	return append(timings, metrics.NewPhaseTiming(metrics.DiffBuilding, report.DiffBuilding))
+}
+
+// TimingsToString summarizes the wall time spent in each phase, summed over all steps, and the timings and resource
+// usage of each step.
+func (report *Report) TimingsToString() string {
+	var stringBuilder strings.Builder
+
+	timings := report.Timings()
+	writer := tabwriter.NewWriter(&stringBuilder, 0, 0, columnPadding, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "Timings")
+
+	for _, phase := range metrics.Phases() {
+		_, _ = fmt.Fprintf(writer, "  %s\t%s\n", phase, formatDuration(metrics.Total(timings, phase)))
+	}
+
+	_, _ = fmt.Fprintln(writer, "Steps")
+	_, _ = fmt.Fprintf(writer, "  RUN\t%s\t%s\t%s\t%s\tCPU\tMAX RSS\n",
+		metrics.IsolationSetup, metrics.Commands, metrics.IsolationCleanup, metrics.StepPostProcessing)
+
+	for _, executionGroup := range report.Pipeline.ExecutionGroups {
+		for _, step := range executionGroup.Steps {
+			cpu, maxRSS := "-", "-"
+			if step.ResourceUsage != nil {
+				cpu = formatDuration(step.ResourceUsage.CPU())
+				maxRSS = fmt.Sprintf("%d MiB", step.ResourceUsage.MaxRSSKiB/kibPerMiB)
+			}
+
+			_, _ = fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				step.RunModel.Run.ID,
+				formatDuration(metrics.Total(step.Timings, metrics.IsolationSetup)),
+				formatDuration(metrics.Total(step.Timings, metrics.Commands)),
+				formatDuration(metrics.Total(step.Timings, metrics.IsolationCleanup)),
+				formatDuration(metrics.Total(step.Timings, metrics.StepPostProcessing)),
+				cpu,
+				maxRSS,
+			)
+		}
+	}
+
+	_ = writer.Flush()
+
+	return strings.TrimSuffix(stringBuilder.String(), "\n")
+}
+
+func (report *Report) PrintTimings() {
+	chastlog.Log.Println(report.TimingsToString())
+}
+
+const (
+	columnPadding = 2
+	kibPerMiB     = 1024
+)
+
+func formatDuration(duration time.Duration) string {
+	return duration.Round(time.Millisecond).String()
+}
+
+func countsToString(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var stringBuilder strings.Builder
+
+	for _, key := range keys {
+		name := key
+		if name == "" {
+			name = "<none>"
+		}
+
+		stringBuilder.WriteString(fmt.Sprintf("\n  %s: %d", name, counts[key]))
+	}
+
+	return stringBuilder.String()
+}
@@ -109,36 +109,11 @@ func mergeChangedWithPreviousChanges(step *refactoringpipelinemodel.Step) error
 }
 
 func publishChangesToDependents(step *refactoringpipelinemodel.Step) error {
-	mergeEntities := []dirmerger.MergeEntity{
-		dirmerger.NewMergeEntity(step.GetFinalChangesLocation(), nil),
-	}
-
 	cumulatedErrors := make([]error, 0)
 
-	// The changes are moved to the dependent location
-	// (if there exist multiple, files are copied for all of them but the last one)
-	// Files should never overwrite existing files, which can happen if the dependent has multiple dependencies.
-	baseOptions := mergeoptions.NewMergeOptions()
-	baseOptions.BlockOverwrite = true
-	baseOptions.DeleteEmptyFolders = false
-
-	for index, dependent := range step.Dependents {
-		options := *baseOptions
-
-		if index == len(step.Dependents)-1 {
-			// last dependent, move changes instead of copying
-			options.CopyMode = false
-		} else {
-			options.CopyMode = true
-		}
-
-		if err := dirmerger.MergeFolders(
-			mergeEntities,
-			dependent.GetMergedPreviousChangesLocation(),
-			&options,
-		); err != nil {
-			cumulatedErrors = append(cumulatedErrors,
-				errorx.InternalError.Wrap(err, "failed to cleanup stage"))
+	for _, dependent := range step.Dependents {
+		if err := PublishChanges(step, dependent); err != nil {
+			cumulatedErrors = append(cumulatedErrors, err)
 		}
 	}
 
@@ -149,3 +124,32 @@ func publishChangesToDependents(step *refactoringpipelinemodel.Step) error {
 
 	return nil
 }
+
+// PublishChanges copies the final changes of the step to the previous changes of the dependent.
+// The final changes are kept until the pipeline is cleaned up, so they can be published again when a failed
+// pipeline is resumed.
+func PublishChanges(step *refactoringpipelinemodel.Step, dependent *refactoringpipelinemodel.Step) error {
+	// Files should never overwrite existing files, which can happen if the dependent has multiple dependencies.
+	options := mergeoptions.NewMergeOptions()
+	options.BlockOverwrite = true
+	options.CopyMode = true
+	options.DeleteEmptyFolders = false
+
+	mergeEntities := []dirmerger.MergeEntity{
+		dirmerger.NewMergeEntity(step.GetFinalChangesLocation(), nil),
+	}
+
+	// dependencies running in parallel may publish to the same dependent at the same time
+	unlock := dependent.LockMergedPreviousChanges()
+	defer unlock()
+
+	if err := dirmerger.MergeFolders(
+		mergeEntities,
+		dependent.GetMergedPreviousChangesLocation(),
+		options,
+	); err != nil {
+		return errorx.InternalError.Wrap(err, "failed to publish changes to dependent %s", dependent.UUID)
+	}
+
+	return nil
+}
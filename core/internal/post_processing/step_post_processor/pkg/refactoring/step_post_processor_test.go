@@ -0,0 +1,106 @@
+package steppostprocessor_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	uut "chast.io/core/internal/post_processing/step_post_processor/pkg/refactoring"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+)
+
+func dummyStep(runID string) *refactoringpipelinemodel.Step {
+	return refactoringpipelinemodel.NewStep(&refactoring.SingleRunModel{
+		Run: &refactoring.Run{ //nolint:exhaustruct // not required for test
+			ID:      runID,
+			Command: &refactoring.Command{Cmds: [][]string{{runID}}}, //nolint:exhaustruct // not required for test
+		},
+	})
+}
+
+func writeFinalChanges(t *testing.T, step *refactoringpipelinemodel.Step, folder string, files int) {
+	t.Helper()
+
+	for index := 0; index < files; index++ {
+		path := filepath.Join(step.GetFinalChangesLocation(), "src", folder, fmt.Sprintf("File%d.java", index))
+
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("Error creating folder: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte(folder), 0o600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+}
+
+func TestPublishChanges_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const files = 50
+
+	baseDir := t.TempDir()
+	pipeline := refactoringpipelinemodel.NewPipeline(
+		filepath.Join(baseDir, "operation"),
+		filepath.Join(baseDir, "changes"),
+		"/",
+	)
+
+	first, second, dependent := dummyStep("first"), dummyStep("second"), dummyStep("dependent")
+	dependent.AddDependency(first)
+	dependent.AddDependency(second)
+
+	independentGroup := refactoringpipelinemodel.NewExecutionGroup()
+	independentGroup.AddStep(first)
+	independentGroup.AddStep(second)
+	pipeline.AddExecutionGroup(independentGroup)
+
+	dependentGroup := refactoringpipelinemodel.NewExecutionGroup()
+	dependentGroup.AddStep(dependent)
+	pipeline.AddExecutionGroup(dependentGroup)
+
+	// both dependencies create the shared folders while publishing
+	writeFinalChanges(t, first, "first", files)
+	writeFinalChanges(t, second, "second", files)
+
+	publishErrors := make([]error, 2)
+
+	var waitGroup sync.WaitGroup
+
+	for index, dependency := range []*refactoringpipelinemodel.Step{first, second} {
+		waitGroup.Add(1)
+
+		go func(index int, dependency *refactoringpipelinemodel.Step) {
+			defer waitGroup.Done()
+
+			publishErrors[index] = uut.PublishChanges(dependency, dependent)
+		}(index, dependency)
+	}
+
+	waitGroup.Wait()
+
+	for _, publishError := range publishErrors {
+		if publishError != nil {
+			t.Fatalf("Expected no error, but was '%v'", publishError)
+		}
+	}
+
+	for _, folder := range []string{"first", "second"} {
+		entries, readError := os.ReadDir(filepath.Join(dependent.GetMergedPreviousChangesLocation(), "src", folder))
+		if readError != nil {
+			t.Fatalf("Expected published changes of %s, but was '%v'", folder, readError)
+		}
+
+		if len(entries) != files {
+			t.Errorf("Expected %d published files of %s, but was %d", files, folder, len(entries))
+		}
+	}
+
+	// the final changes are kept, so they can be published again when the pipeline is resumed
+	if _, err := os.Stat(filepath.Join(first.GetFinalChangesLocation(), "src", "first", "File0.java")); err != nil {
+		t.Errorf("Expected final changes to be kept, but was '%v'", err)
+	}
+}
@@ -17,7 +17,7 @@ func BuildDependencyGraph(runs []recipemodel.Run) *graph.DoubleConnectedGraph[*r
 	}
 
 	for node := range runGraph.Nodes() {
-		for _, dependency := range node.Self.Dependencies {
+		for _, dependency := range node.Self.GetAllDependencies() {
 			dependencyNode := nodesMap[dependency]
 			if dependencyNode == nil {
 				continue // this can happen if the dependency is a run that is not part of the run model due to a filter
@@ -54,6 +54,9 @@ type Parameter struct {
 	RequiredExtension    `yaml:",inline"`
 	TypeExtension        `yaml:",inline"`
 	DescriptionExtension `yaml:",inline"`
+
+	// BatchSize is the number of files matched by a wildcardPath primary parameter which are passed to one branch.
+	BatchSize int `yaml:"batchSize,omitempty"`
 }
 
 type Flag struct {
@@ -1,10 +1,17 @@
 package recipemodel
 
+import (
+	"github.com/joomcode/errorx"
+	"gopkg.in/yaml.v3"
+)
+
 type RefactoringRecipe struct {
 	BaseRecipe       `yaml:",inline"`
 	PrimaryParameter *Parameter `yaml:"primaryParameter"`
 	Runs             []Run      `yaml:"run"`
 	Tests            []Test     `yaml:"tests"`
+	// Timeout is the default timeout of the runs, e.g. "10m". Runs do not time out by default.
+	Timeout string `yaml:"timeout,omitempty"`
 }
 
 func (recipe *RefactoringRecipe) GetRecipeType() ChastOperationType {
@@ -12,25 +19,76 @@ func (recipe *RefactoringRecipe) GetRecipeType() ChastOperationType {
 }
 
 type Run struct {
-	ID                     string   `yaml:"id,omitempty"`
-	Dependencies           []string `yaml:"dependencies,omitempty"`
-	SupportedExtensions    []string `yaml:"supportedExtensions,omitempty"`
-	Flags                  []Flag   `yaml:"flags,omitempty"`
-	Docker                 *Docker  `yaml:"docker"`
-	Local                  *Local   `yaml:"local"`
-	Script                 []string `yaml:"script"`
-	IncludeChangeLocations []string `yaml:"includeChangeLocations,omitempty"`
-	ExcludeChangeLocations []string `yaml:"excludeChangeLocations,omitempty"`
+	ID                     string          `yaml:"id,omitempty"`
+	Dependencies           []string        `yaml:"dependencies,omitempty"`
+	SupportedExtensions    []string        `yaml:"supportedExtensions,omitempty"`
+	SupportedFiles         *SupportedFiles `yaml:"supportedFiles,omitempty"`
+	Flags                  []Flag          `yaml:"flags,omitempty"`
+	Docker                 *Docker         `yaml:"docker"`
+	Local                  *Local          `yaml:"local"`
+	Script                 []string        `yaml:"script"`
+	IncludeChangeLocations []string        `yaml:"includeChangeLocations,omitempty"`
+	ExcludeChangeLocations []string        `yaml:"excludeChangeLocations,omitempty"`
+	// IncludeIgnoredChanges captures changes of paths ignored by .gitignore and .chastignore files.
+	IncludeIgnoredChanges bool `yaml:"includeIgnoredChanges,omitempty"`
+	// Requires lists runs which have to run before this run. The plan fails if one of them is not selected.
+	Requires []string `yaml:"requires,omitempty"`
+	// After lists runs which have to run before this run if they are selected.
+	After []string `yaml:"after,omitempty"`
+	// Shell is the interpreter the script entries are passed to verbatim: bash (default), sh, python3 or none.
+	Shell string `yaml:"shell,omitempty"`
+	// SingleShell runs all script entries in one process so state is shared between them.
+	SingleShell bool `yaml:"singleShell,omitempty"`
+	// WorkingDirectory is relative to the recipe unless it is absolute, e.g. "$CHAST_PROJECT_ROOT/src" or
+	// "$CHAST_INVOCATION_DIR". Defaults to the "run" folder next to the recipe.
+	WorkingDirectory string `yaml:"workingDirectory,omitempty"`
+	// Timeout limits how long the script may run, e.g. "90s". Defaults to the timeout of the recipe.
+	Timeout string `yaml:"timeout,omitempty"`
+	// AllowedExitCodes are exit codes of the script entries which do not fail the run in addition to zero.
+	AllowedExitCodes []int `yaml:"allowedExitCodes,omitempty"`
+	// ContinueOnError runs the remaining script entries after one failed and keeps the changes of the run.
+	ContinueOnError bool `yaml:"continueOnError,omitempty"`
+	// Retry runs the script again if it failed, e.g. because a tool crashed on startup.
+	Retry *Retry `yaml:"retry,omitempty"`
 }
 
 func (run *Run) GetFlags() []Flag {
 	return run.Flags
 }
 
+// GetAllDependencies returns the IDs of the dependencies, the required runs and the runs to run after.
+func (run *Run) GetAllDependencies() []string {
+	allDependencies := make([]string, 0, len(run.Dependencies)+len(run.Requires)+len(run.After))
+	allDependencies = append(allDependencies, run.Dependencies...)
+	allDependencies = append(allDependencies, run.Requires...)
+	allDependencies = append(allDependencies, run.After...)
+
+	return allDependencies
+}
+
 func (run *Run) GetFlagsMap() map[string]*Flag {
 	return flagsToMap(run.Flags)
 }
 
+// Retry configures how often a failed run is attempted.
+type Retry struct {
+	// Attempts is the maximum number of attempts, including the first one.
+	Attempts int `yaml:"attempts"`
+	// Backoff is waited before each further attempt, e.g. "5s". Further attempts start immediately by default.
+	Backoff string `yaml:"backoff,omitempty"`
+	// OnExitCodes restricts the retries to failures with these exit codes. All failures are retried by default.
+	OnExitCodes []int `yaml:"onExitCodes,omitempty"`
+}
+
+// SupportedFiles selects the files a run is applicable to in addition to the supported extensions.
+// A run is selected if any file matches any of the criteria.
+type SupportedFiles struct {
+	Globs     []string `yaml:"globs,omitempty"`     // relative to the primary argument, e.g. "**/*.java"
+	Filenames []string `yaml:"filenames,omitempty"` // e.g. "Dockerfile" or "Makefile*"
+	Shebangs  []string `yaml:"shebangs,omitempty"`  // interpreters, e.g. "python3" or "bash"
+	Content   []string `yaml:"content,omitempty"`   // regular expressions
+}
+
 type Docker struct {
 	DockerImage string `yaml:"dockerImage"`
 }
@@ -50,9 +108,46 @@ type ChangeLocation struct {
 }
 
 type Test struct {
-	ID          string   `yaml:"id"`
-	Description string   `yaml:"description"`
-	Args        []string `yaml:"args"`
-	Flags       []string `yaml:"flags,omitempty"`
-	ExpectError bool     `yaml:"expectError,omitempty"`
+	ID          string               `yaml:"id"`
+	Description string               `yaml:"description"`
+	Args        []TestValue          `yaml:"args"`
+	Flags       map[string]TestValue `yaml:"flags,omitempty"`
+	ExpectError bool                 `yaml:"expectError,omitempty"`
+	Expect      *TestExpectation     `yaml:"expect,omitempty"`
+	Compare     *TestComparison      `yaml:"compare,omitempty"`
+}
+
+// TestValue is a scalar argument or flag value of a test. Numbers and booleans are kept in their textual form.
+type TestValue string
+
+func (value *TestValue) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return errorx.IllegalFormat.New("line %d: test arguments and flags must be scalar values", node.Line)
+	}
+
+	*value = TestValue(node.Value)
+
+	return nil
+}
+
+// TestExpectation declares expectations of a test that cannot be expressed through the "expected" folder.
+// All paths are relative to the "input" folder of the test and may contain wildcards.
+type TestExpectation struct {
+	Deleted   []string `yaml:"deleted,omitempty"`
+	Untouched []string `yaml:"untouched,omitempty"`
+	Ignored   []string `yaml:"ignored,omitempty"`
+}
+
+// TestComparison configures how changed files are compared against the expected files.
+// Rules are evaluated in order and the first rule with a matching glob is used, otherwise the test defaults apply.
+type TestComparison struct {
+	Mode        string               `yaml:"mode,omitempty"` // exact, trimmed, whitespace, lineEndings, json, yaml, binary
+	IgnoreLines []string             `yaml:"ignoreLines,omitempty"`
+	Rules       []TestComparisonRule `yaml:"rules,omitempty"`
+}
+
+type TestComparisonRule struct {
+	Glob        string   `yaml:"glob"`
+	Mode        string   `yaml:"mode,omitempty"`
+	IgnoreLines []string `yaml:"ignoreLines,omitempty"`
 }
@@ -2,9 +2,13 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"chast.io/core/internal/internal_util/collection"
+	"chast.io/core/internal/internal_util/shell"
 	chastlog "chast.io/core/internal/logger"
 	refactroingdependencygraph "chast.io/core/internal/recipe/internal/refactoring/dependency_graph"
 	recipemodel "chast.io/core/internal/recipe/pkg/model"
@@ -14,6 +18,8 @@ import (
 
 type RefactoringParser struct{}
 
+const maxExitCode = 255
+
 func (parser *RefactoringParser) ParseRecipe(data *[]byte) (*recipemodel.Recipe, error) {
 	var refactoringRecipe *recipemodel.RefactoringRecipe
 
@@ -34,6 +40,10 @@ func (parser *RefactoringParser) ParseRecipe(data *[]byte) (*recipemodel.Recipe,
 }
 
 func validateRecipe(recipe *recipemodel.RefactoringRecipe) error {
+	if err := applyDefaultTimeout(recipe); err != nil {
+		return errorx.Decorate(err, "Error validating timeout")
+	}
+
 	if err := validateRuns(recipe.Runs); err != nil {
 		return errorx.Decorate(err, "Error validating primary parameter")
 	}
@@ -42,10 +52,19 @@ func validateRecipe(recipe *recipemodel.RefactoringRecipe) error {
 		return append(acc, run.SupportedExtensions...)
 	}, make([]string, 0))
 
+	if collection.Any(recipe.Runs, func(run recipemodel.Run) bool { return run.SupportedFiles != nil }) {
+		// files selected by other criteria than their extension must not be rejected by the primary parameter
+		supportedExtensionsOfRuns = make([]string, 0)
+	}
+
 	if err := validatePrimaryParameter(recipe.PrimaryParameter, supportedExtensionsOfRuns); err != nil {
 		return errorx.Decorate(err, "Error validating primary parameter")
 	}
 
+	if err := validateTests(recipe); err != nil {
+		return errorx.Decorate(err, "Error validating tests")
+	}
+
 	return nil
 }
 
@@ -89,7 +108,7 @@ func validateID(run *recipemodel.Run, presentRunIds map[string]bool) error {
 
 func validateDependencies(runs []recipemodel.Run, presentRunIds map[string]bool) error {
 	for _, run := range runs {
-		for _, dependency := range run.Dependencies {
+		for _, dependency := range run.GetAllDependencies() {
 			if !presentRunIds[dependency] {
 				return errorx.IllegalArgument.New(fmt.Sprintf("Run '%s' depends on unknown run '%s'", run.ID, dependency))
 			}
@@ -114,11 +133,130 @@ func validateRun(run *recipemodel.Run) error {
 		return errorx.IllegalFormat.New("Run script is required")
 	}
 
+	if err := validateShell(run); err != nil {
+		return err
+	}
+
+	if err := validateTimeout(run.Timeout); err != nil {
+		return errorx.Decorate(err, "Invalid timeout of run '%s'", run.ID)
+	}
+
+	for _, exitCode := range run.AllowedExitCodes {
+		if exitCode < 1 || exitCode > maxExitCode {
+			return errorx.IllegalFormat.New("Allowed exit code %d of run '%s' is not between 1 and %d",
+				exitCode, run.ID, maxExitCode)
+		}
+	}
+
+	if err := validateRetry(run.Retry); err != nil {
+		return errorx.Decorate(err, "Invalid retry of run '%s'", run.ID)
+	}
+
+	if run.SupportedFiles != nil {
+		for _, content := range run.SupportedFiles.Content {
+			if _, err := regexp.Compile(content); err != nil {
+				return errorx.IllegalFormat.Wrap(err, "Invalid supported files content pattern '%s'", content)
+			}
+		}
+	}
+
 	// TODO add change locations
 
 	return nil
 }
 
+func validateShell(run *recipemodel.Run) error {
+	if run.Shell == "" {
+		return nil
+	}
+
+	if !shell.IsSupported(run.Shell) {
+		return errorx.IllegalFormat.New("Unsupported shell '%s' of run '%s', supported are %v",
+			run.Shell, run.ID, shell.Shells())
+	}
+
+	if run.Shell != shell.None {
+		return nil
+	}
+
+	if run.SingleShell {
+		return errorx.IllegalFormat.New("Run '%s' cannot share a single shell without a shell", run.ID)
+	}
+
+	for _, script := range run.Script {
+		if _, err := shell.Invocation(shell.None, script); err != nil {
+			return errorx.Decorate(err, "Invalid script of run '%s'", run.ID)
+		}
+	}
+
+	return nil
+}
+
+// applyDefaultTimeout sets the timeout of the recipe on all runs without their own timeout.
+func applyDefaultTimeout(recipe *recipemodel.RefactoringRecipe) error {
+	if recipe.Timeout == "" {
+		return nil
+	}
+
+	if err := validateTimeout(recipe.Timeout); err != nil {
+		return err
+	}
+
+	for runIndex := range recipe.Runs {
+		if recipe.Runs[runIndex].Timeout == "" {
+			recipe.Runs[runIndex].Timeout = recipe.Timeout
+		}
+	}
+
+	return nil
+}
+
+func validateTimeout(timeout string) error {
+	if timeout == "" {
+		return nil
+	}
+
+	duration, parseError := time.ParseDuration(timeout)
+	if parseError != nil {
+		return errorx.IllegalFormat.Wrap(parseError, "Timeout '%s' is not a duration like \"90s\" or \"10m\"", timeout)
+	}
+
+	if duration <= 0 {
+		return errorx.IllegalFormat.New("Timeout '%s' must be positive", timeout)
+	}
+
+	return nil
+}
+
+func validateRetry(retry *recipemodel.Retry) error {
+	if retry == nil {
+		return nil
+	}
+
+	if retry.Attempts < 1 {
+		return errorx.IllegalFormat.New("Attempts must be at least 1, but was %d", retry.Attempts)
+	}
+
+	if retry.Backoff != "" {
+		backoff, parseError := time.ParseDuration(retry.Backoff)
+		if parseError != nil {
+			return errorx.IllegalFormat.Wrap(parseError, "Backoff '%s' is not a duration like \"5s\"", retry.Backoff)
+		}
+
+		if backoff < 0 {
+			return errorx.IllegalFormat.New("Backoff '%s' must not be negative", retry.Backoff)
+		}
+	}
+
+	for _, exitCode := range retry.OnExitCodes {
+		if exitCode < 1 || exitCode > maxExitCode {
+			return errorx.IllegalFormat.New("Exit code %d to retry on is not between 1 and %d", exitCode, maxExitCode)
+		}
+	}
+
+	return nil
+}
+
 func validatePrimaryParameter(parameter *recipemodel.Parameter, supportedExtensions []string) error {
 	if parameter == nil {
 		return errorx.IllegalFormat.New("Primary parameter is required")
@@ -144,6 +282,14 @@ func validatePrimaryParameter(parameter *recipemodel.Parameter, supportedExtensi
 		return errorx.IllegalFormat.New(fmt.Sprintf("Must be of type %s", options))
 	}
 
+	if parameter.BatchSize < 0 {
+		return errorx.IllegalFormat.New("Primary parameter batch size must not be negative")
+	}
+
+	if parameter.BatchSize > 0 && parameter.TypeExtension.Type != "wildcardPath" {
+		return errorx.IllegalFormat.New("Primary parameter batch size is only supported for the type wildcardPath")
+	}
+
 	if parameter.TypeExtension.Extensions == nil || len(parameter.TypeExtension.Extensions) == 0 {
 		parameter.TypeExtension.Extensions = supportedExtensions
 	} else {
@@ -158,3 +304,73 @@ func validatePrimaryParameter(parameter *recipemodel.Parameter, supportedExtensi
 
 	return nil
 }
+
+func validateTests(recipe *recipemodel.RefactoringRecipe) error {
+	presentTestIds := make(map[string]bool)
+
+	for testIndex := range recipe.Tests {
+		test := &recipe.Tests[testIndex]
+
+		if test.ID == "" {
+			return errorx.IllegalFormat.New("Test ID is required")
+		}
+
+		if presentTestIds[test.ID] {
+			return errorx.IllegalArgument.New("Duplicate test ID '%s'", test.ID)
+		}
+
+		presentTestIds[test.ID] = true
+
+		if err := validateTest(recipe, test); err != nil {
+			return errorx.Decorate(err, "Error validating test '%s'", test.ID)
+		}
+	}
+
+	return nil
+}
+
+func validateTest(recipe *recipemodel.RefactoringRecipe, test *recipemodel.Test) error {
+	parameters := append([]recipemodel.Parameter{*recipe.PrimaryParameter}, recipe.PositionalParameters...)
+
+	if len(test.Args) > len(parameters) {
+		return errorx.IllegalArgument.New(
+			"Test passes %d arguments, but the recipe only declares %d parameters", len(test.Args), len(parameters),
+		)
+	}
+
+	for argIndex, arg := range test.Args {
+		if err := validateTestValue(parameters[argIndex].ID, parameters[argIndex].TypeExtension, arg); err != nil {
+			return err
+		}
+	}
+
+	flagsMap := recipe.GetFlagsMap()
+
+	for flagName, flagValue := range test.Flags {
+		flag := flagsMap[flagName]
+		if flag == nil {
+			return errorx.IllegalArgument.New("Unknown flag '%s'", flagName)
+		}
+
+		if err := validateTestValue(flag.Name, flag.TypeExtension, flagValue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateTestValue(name string, typeExtension recipemodel.TypeExtension, value recipemodel.TestValue) error {
+	switch typeExtension.Type {
+	case "int":
+		if _, err := strconv.Atoi(string(value)); err != nil {
+			return errorx.IllegalArgument.New("'%s' is not an integer. Passed value: %s", name, value)
+		}
+	case "bool", "boolean":
+		if !collection.Include([]string{"true", "yes", "false", "no"}, string(value)) {
+			return errorx.IllegalArgument.New("'%s' is not a boolean. Passed value: %s", name, value)
+		}
+	}
+
+	return nil
+}
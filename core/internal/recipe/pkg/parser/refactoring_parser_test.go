@@ -2,6 +2,7 @@ package parser_test
 
 import (
 	"os"
+	"reflect"
 	"testing"
 
 	recipemodel "chast.io/core/internal/recipe/pkg/model"
@@ -35,6 +36,41 @@ func TestParseRecipe_Refactoring(t *testing.T) {
 		t.Parallel()
 		testSelfReferencingDependencies(t)
 	})
+
+	t.Run("Typed Tests", func(t *testing.T) {
+		t.Parallel()
+		testTypedTests(t)
+	})
+
+	t.Run("Invalid Tests", func(t *testing.T) {
+		t.Parallel()
+		testInvalidTests(t)
+	})
+
+	t.Run("Invalid Shells", func(t *testing.T) {
+		t.Parallel()
+		testInvalidShells(t)
+	})
+
+	t.Run("Timeouts", func(t *testing.T) {
+		t.Parallel()
+		testTimeouts(t)
+	})
+
+	t.Run("Invalid Timeouts", func(t *testing.T) {
+		t.Parallel()
+		testInvalidTimeouts(t)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		t.Parallel()
+		testRetry(t)
+	})
+
+	t.Run("Invalid Retries", func(t *testing.T) {
+		t.Parallel()
+		testInvalidRetries(t)
+	})
 }
 
 func testParseRecipeRefactoringCompleteValid(t *testing.T) {
@@ -163,16 +199,30 @@ func testDuplicateIds(t *testing.T) {
 func testInvalidDependencies(t *testing.T) {
 	t.Helper()
 
-	fileData, err := os.ReadFile("testdata/refactoring_parser/invalid_dependencies_recipe.yml")
-	if err != nil {
-		t.Fatalf("Error reading test recipe: %v", err)
+	tests := []string{
+		"invalid_dependencies_recipe.yml",
+		"invalid_requires_recipe.yml",
+		"invalid_after_recipe.yml",
 	}
 
-	refactoringParser := &parser.RefactoringParser{}
-	_, parseError := refactoringParser.ParseRecipe(&fileData)
+	for _, testCase := range tests {
+		testCase := testCase
 
-	if parseError == nil {
-		t.Fatal("Expected error, but was nil")
+		t.Run(testCase, func(t *testing.T) {
+			t.Parallel()
+
+			fileData, err := os.ReadFile("testdata/refactoring_parser/" + testCase)
+			if err != nil {
+				t.Fatalf("Error reading test recipe: %v", err)
+			}
+
+			refactoringParser := &parser.RefactoringParser{}
+			_, parseError := refactoringParser.ParseRecipe(&fileData)
+
+			if parseError == nil {
+				t.Fatal("Expected error, but was nil")
+			}
+		})
 	}
 }
 
@@ -243,3 +293,209 @@ func testSelfReferencingDependencies(t *testing.T) {
 		t.Fatal("Expected error, but was nil")
 	}
 }
+
+func testTypedTests(t *testing.T) {
+	t.Helper()
+
+	fileData, err := os.ReadFile("testdata/refactoring_parser/typed_tests_recipe.yml")
+	if err != nil {
+		t.Fatalf("Error reading test recipe: %v", err)
+	}
+
+	refactoringParser := &parser.RefactoringParser{}
+
+	genericRecipe, parseError := refactoringParser.ParseRecipe(&fileData)
+	if parseError != nil {
+		t.Fatalf("Expected no error, but was '%v'", parseError)
+	}
+
+	recipe, isRefactoringRecipe := (*genericRecipe).(*recipemodel.RefactoringRecipe)
+	if !isRefactoringRecipe {
+		t.Fatalf("Expected recipe to be of type RefactoringRecipe, but was %T", *genericRecipe)
+	}
+
+	test := recipe.Tests[0]
+
+	expectedArgs := []recipemodel.TestValue{"TestFile.java", "config.yaml"}
+	if !reflect.DeepEqual(test.Args, expectedArgs) {
+		t.Errorf("Expected args to be '%v', but was '%v'", expectedArgs, test.Args)
+	}
+
+	expectedFlags := map[string]recipemodel.TestValue{"depth": "3", "dryRun": "true"}
+	if !reflect.DeepEqual(test.Flags, expectedFlags) {
+		t.Errorf("Expected flags to be '%v', but was '%v'", expectedFlags, test.Flags)
+	}
+}
+
+func testInvalidTests(t *testing.T) {
+	t.Helper()
+
+	tests := []string{
+		"test_too_many_args_recipe.yml",
+		"test_unknown_flag_recipe.yml",
+		"test_invalid_flag_value_recipe.yml",
+		"test_duplicate_ids_recipe.yml",
+		"test_non_scalar_flag_recipe.yml",
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase, func(t *testing.T) {
+			t.Parallel()
+
+			fileData, err := os.ReadFile("testdata/refactoring_parser/" + testCase)
+			if err != nil {
+				t.Fatalf("Error reading test recipe: %v", err)
+			}
+
+			refactoringParser := &parser.RefactoringParser{}
+			_, parseError := refactoringParser.ParseRecipe(&fileData)
+
+			if parseError == nil {
+				t.Fatal("Expected error, but was nil")
+			}
+		})
+	}
+}
+
+func testInvalidShells(t *testing.T) {
+	t.Helper()
+
+	tests := []string{
+		"unsupported_shell_recipe.yml",
+		"single_shell_without_shell_recipe.yml",
+		"unterminated_quote_without_shell_recipe.yml",
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase, func(t *testing.T) {
+			t.Parallel()
+
+			fileData, err := os.ReadFile("testdata/refactoring_parser/" + testCase)
+			if err != nil {
+				t.Fatalf("Error reading test recipe: %v", err)
+			}
+
+			refactoringParser := &parser.RefactoringParser{}
+			_, parseError := refactoringParser.ParseRecipe(&fileData)
+
+			if parseError == nil {
+				t.Fatal("Expected error, but was nil")
+			}
+		})
+	}
+}
+
+func testTimeouts(t *testing.T) {
+	t.Helper()
+
+	fileData, err := os.ReadFile("testdata/refactoring_parser/timeout_recipe.yml")
+	if err != nil {
+		t.Fatalf("Error reading test recipe: %v", err)
+	}
+
+	refactoringParser := &parser.RefactoringParser{}
+
+	recipe, parseError := refactoringParser.ParseRecipe(&fileData)
+	if parseError != nil {
+		t.Fatalf("Expected no error, but was %v", parseError)
+	}
+
+	runs := (*recipe).(*recipemodel.RefactoringRecipe).Runs
+
+	if runs[0].Timeout != "10m" {
+		t.Errorf("Expected run without timeout to use the timeout of the recipe '10m', but was '%s'", runs[0].Timeout)
+	}
+
+	if runs[1].Timeout != "90s" {
+		t.Errorf("Expected run to keep its timeout '90s', but was '%s'", runs[1].Timeout)
+	}
+}
+
+func testInvalidTimeouts(t *testing.T) {
+	t.Helper()
+
+	tests := []string{
+		"invalid_timeout_recipe.yml",
+		"negative_timeout_recipe.yml",
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase, func(t *testing.T) {
+			t.Parallel()
+
+			fileData, err := os.ReadFile("testdata/refactoring_parser/" + testCase)
+			if err != nil {
+				t.Fatalf("Error reading test recipe: %v", err)
+			}
+
+			refactoringParser := &parser.RefactoringParser{}
+			_, parseError := refactoringParser.ParseRecipe(&fileData)
+
+			if parseError == nil {
+				t.Fatal("Expected error, but was nil")
+			}
+		})
+	}
+}
+
+func testRetry(t *testing.T) {
+	t.Helper()
+
+	fileData, err := os.ReadFile("testdata/refactoring_parser/retry_recipe.yml")
+	if err != nil {
+		t.Fatalf("Error reading test recipe: %v", err)
+	}
+
+	refactoringParser := &parser.RefactoringParser{}
+
+	recipe, parseError := refactoringParser.ParseRecipe(&fileData)
+	if parseError != nil {
+		t.Fatalf("Expected no error, but was %v", parseError)
+	}
+
+	retry := (*recipe).(*recipemodel.RefactoringRecipe).Runs[0].Retry
+
+	if retry == nil {
+		t.Fatal("Expected retry to be set, but was nil")
+	}
+
+	if retry.Attempts != 3 || retry.Backoff != "5s" || !reflect.DeepEqual(retry.OnExitCodes, []int{134, 137}) {
+		t.Errorf("Expected 3 attempts with a backoff of 5s on exit codes 134 and 137, but was %+v", retry)
+	}
+}
+
+func testInvalidRetries(t *testing.T) {
+	t.Helper()
+
+	tests := []string{
+		"zero_attempts_retry_recipe.yml",
+		"invalid_backoff_retry_recipe.yml",
+		"invalid_exit_code_retry_recipe.yml",
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase, func(t *testing.T) {
+			t.Parallel()
+
+			fileData, err := os.ReadFile("testdata/refactoring_parser/" + testCase)
+			if err != nil {
+				t.Fatalf("Error reading test recipe: %v", err)
+			}
+
+			refactoringParser := &parser.RefactoringParser{}
+			_, parseError := refactoringParser.ParseRecipe(&fileData)
+
+			if parseError == nil {
+				t.Fatal("Expected error, but was nil")
+			}
+		})
+	}
+}
@@ -35,7 +35,7 @@ func verifyArgument(parameter *recipemodel.Parameter, value string) error {
 	}
 
 	switch parameter.Type {
-	case "string":
+	case "string", "wildcardPath": // wildcard paths are verified once they are expanded
 		break
 	case "bool":
 		if !(value == "true" || value == "yes" || value == "false" || value == "no") {
@@ -0,0 +1,291 @@
+package fileclassification
+
+import (
+	"bufio"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"chast.io/core/internal/internal_util/collection"
+	"chast.io/core/internal/internal_util/glob"
+	"chast.io/core/internal/internal_util/ignore"
+	chastlog "chast.io/core/internal/logger"
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+// maxContentSize limits how much of a file is read to match content patterns.
+const maxContentSize = 1024 * 1024
+
+const maxShebangSize = 256
+
+// Rule decides which files a run is applicable to.
+type Rule struct {
+	Name       string
+	extensions []string
+	globs      []*regexp.Regexp
+	filenames  []*regexp.Regexp
+	shebangs   []*regexp.Regexp
+	content    []*regexp.Regexp
+}
+
+func NewRule(name string, extensions []string, supportedFiles *recipemodel.SupportedFiles) (*Rule, error) {
+	rule := &Rule{
+		Name:       name,
+		extensions: collection.Map(extensions, func(extension string) string { return strings.TrimPrefix(extension, ".") }),
+		globs:      make([]*regexp.Regexp, 0),
+		filenames:  make([]*regexp.Regexp, 0),
+		shebangs:   make([]*regexp.Regexp, 0),
+		content:    make([]*regexp.Regexp, 0),
+	}
+
+	if supportedFiles == nil {
+		return rule, nil
+	}
+
+	var globCompileError error
+
+	if rule.globs, globCompileError = compileGlobs(supportedFiles.Globs); globCompileError != nil {
+		return nil, errorx.Decorate(globCompileError, "Invalid supported files glob of run %s", name)
+	}
+
+	if rule.filenames, globCompileError = compileGlobs(supportedFiles.Filenames); globCompileError != nil {
+		return nil, errorx.Decorate(globCompileError, "Invalid supported files filename of run %s", name)
+	}
+
+	if rule.shebangs, globCompileError = compileGlobs(supportedFiles.Shebangs); globCompileError != nil {
+		return nil, errorx.Decorate(globCompileError, "Invalid supported files shebang of run %s", name)
+	}
+
+	for _, content := range supportedFiles.Content {
+		contentPattern, compileError := regexp.Compile(content)
+		if compileError != nil {
+			return nil, errorx.IllegalFormat.Wrap(compileError, "Invalid content pattern '%s' of run %s", content, name)
+		}
+
+		rule.content = append(rule.content, contentPattern)
+	}
+
+	return rule, nil
+}
+
+// IsUnrestricted checks if the rule has no criteria and therefore matches every file.
+func (r *Rule) IsUnrestricted() bool {
+	return len(r.extensions) == 0 && len(r.globs) == 0 && len(r.filenames) == 0 &&
+		len(r.shebangs) == 0 && len(r.content) == 0
+}
+
+// Matches checks the criteria from the cheapest to the most expensive one, so files are only read if necessary.
+func (r *Rule) Matches(file *File) bool {
+	if r.IsUnrestricted() {
+		return true
+	}
+
+	if collection.Include(r.extensions, file.Extension()) {
+		return true
+	}
+
+	if matchesAny(r.globs, file.RelativePath) || matchesAny(r.filenames, filepath.Base(file.Path)) {
+		return true
+	}
+
+	if len(r.shebangs) > 0 {
+		if interpreter := file.ShebangInterpreter(); interpreter != "" && matchesAny(r.shebangs, interpreter) {
+			return true
+		}
+	}
+
+	if len(r.content) > 0 {
+		content := file.Content()
+
+		return collection.Any(r.content, func(pattern *regexp.Regexp) bool { return pattern.Match(content) })
+	}
+
+	return false
+}
+
+// Classification is the result of classifying all files below the root paths.
+type Classification struct {
+	// Matches contains for each rule (by index) if at least one file matched.
+	Matches []bool
+	Stats   *refactoring.ClassificationStats
+}
+
+// Classify walks all files below the root paths once and matches them against the rules.
+// Paths ignored by .gitignore or .chastignore files are skipped.
+func Classify(rootPaths []string, rules []*Rule) (*Classification, error) {
+	classification := &Classification{
+		Matches: make([]bool, len(rules)),
+		Stats:   refactoring.NewClassificationStats(),
+	}
+
+	for _, rootPath := range rootPaths {
+		chastlog.Log.Tracef("Classifying files in path: %s", rootPath)
+
+		ignoreMatcher := ignore.NewMatcher(ignore.FindRoot(rootPath))
+
+		if err := filepath.WalkDir(rootPath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if path != rootPath && ignoreMatcher.IsIgnored(path, entry.IsDir()) {
+				classification.Stats.IgnoredPaths++
+
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			classification.classifyFile(newFile(path, rootPath), rules)
+
+			return nil
+		}); err != nil {
+			return nil, errorx.ExternalError.Wrap(err, "Could not classify files in %s", rootPath)
+		}
+	}
+
+	return classification, nil
+}
+
+func (c *Classification) classifyFile(file *File, rules []*Rule) {
+	c.Stats.ScannedFiles++
+	c.Stats.Extensions[file.Extension()]++
+
+	for index, rule := range rules {
+		if rule.Matches(file) {
+			c.Matches[index] = true
+			c.Stats.MatchedFiles[rule.Name]++
+		}
+	}
+}
+
+// File is a classified file. Its content is read lazily and at most once.
+type File struct {
+	Path         string
+	RelativePath string
+
+	content     []byte
+	contentRead bool
+}
+
+func newFile(path string, rootPath string) *File {
+	relativePath, relError := filepath.Rel(rootPath, path)
+	if relError != nil || relativePath == "." {
+		relativePath = filepath.Base(path) // the root path itself is a file
+	}
+
+	return &File{
+		Path:         path,
+		RelativePath: filepath.ToSlash(relativePath),
+		content:      nil,
+		contentRead:  false,
+	}
+}
+
+func (f *File) Extension() string {
+	return strings.TrimPrefix(filepath.Ext(f.Path), ".")
+}
+
+// ShebangInterpreter returns the interpreter of the shebang line (e.g. "python3" for "#!/usr/bin/env python3").
+func (f *File) ShebangInterpreter() string {
+	shebang := f.firstLine()
+	if !strings.HasPrefix(shebang, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+
+				break
+			}
+		}
+	}
+
+	return interpreter
+}
+
+func (f *File) firstLine() string {
+	if f.contentRead {
+		firstLine, _, _ := strings.Cut(string(f.content), "\n")
+
+		return firstLine
+	}
+
+	file, openError := os.Open(f.Path)
+	if openError != nil {
+		return ""
+	}
+	defer file.Close()
+
+	firstLine, _, _ := bufio.NewReaderSize(file, maxShebangSize).ReadLine()
+
+	return string(firstLine)
+}
+
+// Content returns the first maxContentSize bytes of the file. Unreadable files have no content.
+func (f *File) Content() []byte {
+	if f.contentRead {
+		return f.content
+	}
+
+	f.contentRead = true
+
+	file, openError := os.Open(f.Path)
+	if openError != nil {
+		chastlog.Log.Tracef("Could not read %s for classification: %v", f.Path, openError)
+
+		return nil
+	}
+	defer file.Close()
+
+	content, readError := io.ReadAll(io.LimitReader(file, maxContentSize))
+	if readError != nil {
+		chastlog.Log.Tracef("Could not read %s for classification: %v", f.Path, readError)
+
+		return nil
+	}
+
+	f.content = content
+
+	return f.content
+}
+
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	compiledPatterns := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		compiledPattern, compileError := regexp.Compile(glob.ToRegexp(pattern))
+		if compileError != nil {
+			return nil, errorx.IllegalFormat.Wrap(compileError, "Invalid pattern '%s'", pattern)
+		}
+
+		compiledPatterns = append(compiledPatterns, compiledPattern)
+	}
+
+	return compiledPatterns, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	return collection.Any(patterns, func(pattern *regexp.Regexp) bool { return pattern.MatchString(value) })
+}
@@ -0,0 +1,176 @@
+package fileclassification_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	uut "chast.io/core/internal/run_model/internal/file_classification"
+)
+
+func prepareFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	baseDir := t.TempDir()
+
+	for file, content := range files {
+		path := filepath.Join(baseDir, file)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Error creating folder: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+
+	return baseDir
+}
+
+func TestClassify(t *testing.T) { //nolint:funlen // table test
+	t.Parallel()
+
+	baseDir := prepareFiles(t, map[string]string{
+		"src/Main.java":      "package main;",
+		"src/Other.java":     "package other;",
+		"Dockerfile":         "FROM alpine",
+		"scripts/run":        "#!/usr/bin/env python3\nprint('run')",
+		"scripts/build":      "#!/bin/bash\necho build",
+		"docs/README.md":     "# Readme",
+		"config/config.yaml": "generated: true",
+	})
+
+	tests := []struct {
+		name           string
+		extensions     []string
+		supportedFiles *recipemodel.SupportedFiles
+		matched        bool
+		matchedFiles   int
+	}{
+		{name: "extension", extensions: []string{"java"}, matched: true, matchedFiles: 2},
+		{name: "dotted extension", extensions: []string{".md"}, matched: true, matchedFiles: 1},
+		{name: "missing extension", extensions: []string{"cs"}, matched: false, matchedFiles: 0},
+		{
+			name:           "glob",
+			supportedFiles: &recipemodel.SupportedFiles{Globs: []string{"src/**/*.java"}}, //nolint:exhaustruct // globs only
+			matched:        true,
+			matchedFiles:   2,
+		},
+		{
+			name:           "filename",
+			supportedFiles: &recipemodel.SupportedFiles{Filenames: []string{"Dockerfile"}}, //nolint:exhaustruct // filenames only
+			matched:        true,
+			matchedFiles:   1,
+		},
+		{
+			name:           "shebang",
+			supportedFiles: &recipemodel.SupportedFiles{Shebangs: []string{"python*"}}, //nolint:exhaustruct // shebangs only
+			matched:        true,
+			matchedFiles:   1,
+		},
+		{
+			name:           "content",
+			supportedFiles: &recipemodel.SupportedFiles{Content: []string{`(?m)^generated: true$`}}, //nolint:exhaustruct,lll // content only
+			matched:        true,
+			matchedFiles:   1,
+		},
+		{
+			name:           "combined",
+			extensions:     []string{"java"},
+			supportedFiles: &recipemodel.SupportedFiles{Filenames: []string{"Dockerfile"}, Shebangs: []string{"bash"}}, //nolint:exhaustruct,lll // not all required
+			matched:        true,
+			matchedFiles:   4,
+		},
+		{name: "unrestricted", matched: true, matchedFiles: 7},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, ruleError := uut.NewRule(testCase.name, testCase.extensions, testCase.supportedFiles)
+			if ruleError != nil {
+				t.Fatalf("Expected no error, but was '%v'", ruleError)
+			}
+
+			classification, err := uut.Classify([]string{baseDir}, []*uut.Rule{rule})
+			if err != nil {
+				t.Fatalf("Expected no error, but was '%v'", err)
+			}
+
+			if classification.Matches[0] != testCase.matched {
+				t.Errorf("Expected match to be %v, but was %v", testCase.matched, classification.Matches[0])
+			}
+
+			if matchedFiles := classification.Stats.MatchedFiles[testCase.name]; matchedFiles != testCase.matchedFiles {
+				t.Errorf("Expected %d matched files, but was %d", testCase.matchedFiles, matchedFiles)
+			}
+		})
+	}
+}
+
+func TestClassify_Stats(t *testing.T) {
+	t.Parallel()
+
+	baseDir := prepareFiles(t, map[string]string{
+		"A.java":     "",
+		"B.java":     "",
+		"Dockerfile": "",
+	})
+
+	classification, err := uut.Classify([]string{baseDir}, make([]*uut.Rule, 0))
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if classification.Stats.ScannedFiles != 3 {
+		t.Errorf("Expected 3 scanned files, but was %d", classification.Stats.ScannedFiles)
+	}
+
+	expectedExtensions := map[string]int{"java": 2, "": 1}
+	if !reflect.DeepEqual(classification.Stats.Extensions, expectedExtensions) {
+		t.Errorf("Expected extensions to be '%v', but was '%v'", expectedExtensions, classification.Stats.Extensions)
+	}
+}
+
+func TestClassify_Ignored(t *testing.T) {
+	t.Parallel()
+
+	baseDir := prepareFiles(t, map[string]string{
+		".gitignore":                "node_modules/\n",
+		".chastignore":              "generated/*.java\n!generated/Keep.java\n",
+		"A.java":                    "",
+		"node_modules/lib/index.js": "",
+		"generated/Gen.java":        "",
+		"generated/Keep.java":       "",
+	})
+
+	classification, err := uut.Classify([]string{baseDir}, make([]*uut.Rule, 0))
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	// .gitignore, .chastignore, A.java and generated/Keep.java
+	if classification.Stats.ScannedFiles != 4 {
+		t.Errorf("Expected 4 scanned files, but was %d", classification.Stats.ScannedFiles)
+	}
+
+	if classification.Stats.IgnoredPaths != 2 {
+		t.Errorf("Expected 2 ignored paths, but was %d", classification.Stats.IgnoredPaths)
+	}
+}
+
+func TestNewRule_InvalidContentPattern(t *testing.T) {
+	t.Parallel()
+
+	supportedFiles := &recipemodel.SupportedFiles{Content: []string{"("}} //nolint:exhaustruct // content only
+
+	if _, err := uut.NewRule("invalid", nil, supportedFiles); err == nil {
+		t.Errorf("Expected an error, but was nil")
+	}
+}
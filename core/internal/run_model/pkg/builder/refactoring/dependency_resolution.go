@@ -0,0 +1,97 @@
+package refactoringrunmodelbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"chast.io/core/internal/internal_util/collection"
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+// resolveDependencies resolves the dependencies of the selected runs on runs which are not selected:
+// runs with such a dependency are skipped, runs requiring such a run fail the plan and runs to run after such a run
+// are kept without the ordering.
+func resolveDependencies(runs []recipemodel.Run) ([]recipemodel.Run, []refactoring.DependencyDecision, error) {
+	selectedRunIds := make(map[string]bool, len(runs))
+
+	for _, run := range runs {
+		if run.ID != "" {
+			selectedRunIds[run.ID] = true
+		}
+	}
+
+	decisions := make([]refactoring.DependencyDecision, 0)
+	skippedRuns := make(map[int]bool)
+
+	// skipping a run can make its dependents skip as well
+	for changed := true; changed; {
+		changed = false
+
+		for index, run := range runs {
+			if skippedRuns[index] {
+				continue
+			}
+
+			for _, dependency := range run.Dependencies {
+				if !selectedRunIds[dependency] {
+					skippedRuns[index] = true
+					delete(selectedRunIds, run.ID)
+					decisions = append(decisions, newDependencyDecision(run.ID, dependency, refactoring.Dependency, true))
+					changed = true
+
+					break
+				}
+			}
+		}
+	}
+
+	resolvedRuns := make([]recipemodel.Run, 0, len(runs))
+	missingRequirements := make([]string, 0)
+
+	for index, run := range runs {
+		if skippedRuns[index] {
+			continue
+		}
+
+		for _, requirement := range run.Requires {
+			if !selectedRunIds[requirement] {
+				missingRequirements = append(missingRequirements,
+					fmt.Sprintf("'%s' requires '%s'", run.ID, requirement))
+			}
+		}
+
+		for _, predecessor := range run.After {
+			if !selectedRunIds[predecessor] {
+				decisions = append(decisions, newDependencyDecision(run.ID, predecessor, refactoring.After, false))
+			}
+		}
+
+		run.After = collection.Filter(run.After, func(predecessor string) bool { return selectedRunIds[predecessor] })
+		resolvedRuns = append(resolvedRuns, run)
+	}
+
+	if len(missingRequirements) > 0 {
+		return nil, nil, errorx.IllegalState.New(
+			"Required runs are not selected for the given input: %s", strings.Join(missingRequirements, ", "),
+		)
+	}
+
+	return resolvedRuns, decisions, nil
+}
+
+func newDependencyDecision(
+	runID string,
+	dependencyID string,
+	kind refactoring.DependencyKind,
+	skipped bool,
+) refactoring.DependencyDecision {
+	return refactoring.DependencyDecision{
+		RunID:        runID,
+		DependencyID: dependencyID,
+		Kind:         kind,
+		Skipped:      skipped,
+		Branch:       "",
+	}
+}
@@ -0,0 +1,186 @@
+package refactoringrunmodelbuilder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"chast.io/core/internal/internal_util/collection"
+	"chast.io/core/internal/internal_util/glob"
+	"chast.io/core/internal/internal_util/ignore"
+	chastlog "chast.io/core/internal/logger"
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	fileclassification "chast.io/core/internal/run_model/internal/file_classification"
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+const wildcardPathType = "wildcardPath"
+
+// buildFanOutRunModel expands the wildcard path of the primary parameter and creates an independent branch of all
+// runs for each batch of matched files. The primary parameter of each branch contains the space separated and shell
+// quoted files of its batch, its change locations are resolved for each file of the batch.
+func buildFanOutRunModel(
+	recipeModel *recipemodel.RefactoringRecipe,
+	variables *runmodel.Variables,
+) (*runmodel.RunModel, error) {
+	primaryParameter := recipeModel.PrimaryParameter
+
+	pattern := variables.Map[primaryParameter.ID]
+
+	matchedFiles, expansionError := glob.Expand(pattern)
+	if expansionError != nil {
+		return nil, errorx.InternalError.Wrap(expansionError, "Failed to expand primary argument")
+	}
+
+	variables.TypeDetectionPath = glob.StaticRoot(pattern)
+
+	if err := setBuiltinVariables(variables); err != nil {
+		return nil, err
+	}
+
+	ignoreMatcher := ignore.NewMatcher(ignore.FindRoot(variables.TypeDetectionPath))
+	matchedFiles = collection.Filter(matchedFiles, func(file string) bool { return !ignoreMatcher.IsIgnored(file, false) })
+
+	matchedFiles, classificationError := filterSupportedFiles(matchedFiles, recipeModel.Runs)
+	if classificationError != nil {
+		return nil, classificationError
+	}
+
+	if len(matchedFiles) == 0 {
+		return nil, errorx.IllegalArgument.New("No supported files match %s", variables.Map[primaryParameter.ID])
+	}
+
+	batches := collection.Chunk(matchedFiles, primaryParameter.BatchSize)
+
+	chastlog.Log.Debugf("Fanning out %d files into %d branches", len(matchedFiles), len(batches))
+
+	runs := make([]*refactoring.Run, 0, len(batches)*len(recipeModel.Runs))
+	classificationStats := refactoring.NewClassificationStats()
+	dependencyDecisions := make([]refactoring.DependencyDecision, 0)
+
+	for batchIndex, batch := range batches {
+		batchVariables := variables.Clone()
+		batchVariables.Map[primaryParameter.ID] = strings.Join(collection.Map(batch, shellQuote), " ")
+
+		filteredRuns, batchClassificationStats, runsFilterError := filterRuns(recipeModel.Runs, batch)
+		if runsFilterError != nil {
+			return nil, errorx.InternalError.Wrap(runsFilterError, "Failed to filter runs")
+		}
+
+		classificationStats.Add(batchClassificationStats)
+
+		branch := fmt.Sprintf("batch-%d", batchIndex+1)
+
+		resolvedRuns, batchDependencyDecisions, dependencyResolutionError := resolveDependencies(filteredRuns)
+		if dependencyResolutionError != nil {
+			return nil, errorx.Decorate(dependencyResolutionError, "Failed to resolve run dependencies of %s", branch)
+		}
+
+		for _, decision := range batchDependencyDecisions {
+			decision.Branch = branch
+			dependencyDecisions = append(dependencyDecisions, decision)
+		}
+		namedRuns := make(map[string]*refactoring.Run) // dependencies are resolved within the branch only
+
+		for _, run := range resolvedRuns {
+			convertedRun, conversionError := convertRun(run, batchVariables, namedRuns)
+			if conversionError != nil {
+				return nil, conversionError
+			}
+
+			convertedRun.Branch = branch
+			convertedRun.ChangeLocations = batchChangeLocations(run, convertedRun, batchVariables, primaryParameter.ID, batch)
+
+			runs = append(runs, convertedRun)
+		}
+	}
+
+	var runModel runmodel.RunModel = refactoring.RunModel{
+		Run:                 runs,
+		ClassificationStats: classificationStats,
+		DependencyDecisions: dependencyDecisions,
+		Variables:           variables,
+	}
+
+	return &runModel, nil
+}
+
+// filterSupportedFiles keeps the files which match at least one of the runs.
+func filterSupportedFiles(files []string, runs []recipemodel.Run) ([]string, error) {
+	rules, rulesBuildError := buildClassificationRules(runs)
+	if rulesBuildError != nil {
+		return nil, rulesBuildError
+	}
+
+	if collection.Any(rules, func(rule *fileclassification.Rule) bool { return rule.IsUnrestricted() }) {
+		return files, nil
+	}
+
+	supportedFiles := make([]string, 0, len(files))
+
+	for _, file := range files {
+		classification, classificationError := fileclassification.Classify([]string{file}, rules)
+		if classificationError != nil {
+			return nil, errorx.InternalError.Wrap(classificationError, "Failed to classify files")
+		}
+
+		if collection.Include(classification.Matches, true) {
+			supportedFiles = append(supportedFiles, file)
+		}
+	}
+
+	return supportedFiles, nil
+}
+
+// shellSafePath matches paths which need no quoting in a shell.
+var shellSafePath = regexp.MustCompile(`^[\w@%+=:,./-]+$`) //nolint:gochecknoglobals // compiled once
+
+// shellQuote quotes the path for a shell, unless it only consists of characters without special meaning.
+func shellQuote(path string) string {
+	if shellSafePath.MatchString(path) {
+		return path
+	}
+
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// batchChangeLocations resolves the change locations of the run once for each file of the batch, as the primary
+// parameter lists all files of the batch in a shell quoted form.
+func batchChangeLocations(
+	run recipemodel.Run,
+	convertedRun *refactoring.Run,
+	variables *runmodel.Variables,
+	primaryParameterID string,
+	batch []string,
+) *refactoring.ChangeLocations {
+	changeLocations := &refactoring.ChangeLocations{
+		Include:    make([]string, 0),
+		Exclude:    make([]string, 0),
+		IgnoreRoot: convertedRun.ChangeLocations.IgnoreRoot,
+	}
+
+	for _, file := range batch {
+		fileVariables := variables.Clone()
+		fileVariables.Map[primaryParameterID] = file
+		fileVariables.Map[runmodel.RunIDVariable] = run.ID
+		fileVariables.Map[runmodel.RunDirVariable] = convertedRun.Command.WorkingDirectory
+
+		fileChangeLocations := convertChangeLocations(run, fileVariables)
+		changeLocations.Include = appendMissing(changeLocations.Include, fileChangeLocations.Include)
+		changeLocations.Exclude = appendMissing(changeLocations.Exclude, fileChangeLocations.Exclude)
+	}
+
+	return changeLocations
+}
+
+func appendMissing(locations []string, additionalLocations []string) []string {
+	for _, location := range additionalLocations {
+		if !collection.Include(locations, location) {
+			locations = append(locations, location)
+		}
+	}
+
+	return locations
+}
@@ -0,0 +1,128 @@
+package refactoringrunmodelbuilder_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	uut "chast.io/core/internal/run_model/pkg/builder/refactoring"
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+)
+
+func TestBuildRunModel_WildcardPathFanOut(t *testing.T) { //nolint:funlen // setup of recipe and files
+	t.Parallel()
+
+	baseDir := t.TempDir()
+
+	for _, file := range []string{"A.java", "sub/B.java", "sub/deep/C.java", "D.py"} {
+		path := filepath.Join(baseDir, file)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Error creating folder: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte{}, 0o600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+
+	var recipe recipemodel.Recipe = &recipemodel.RefactoringRecipe{ //nolint:exhaustruct // tests are not required
+		BaseRecipe: recipemodel.BaseRecipe{}, //nolint:exhaustruct // not required for test
+		PrimaryParameter: &recipemodel.Parameter{ //nolint:exhaustruct // not required for test
+			ID:            "inputFiles",
+			TypeExtension: recipemodel.TypeExtension{Type: "wildcardPath", Extensions: []string{"java"}},
+			BatchSize:     2,
+		},
+		Runs: []recipemodel.Run{
+			{ID: "first", SupportedExtensions: []string{"java"}, Script: []string{"echo $inputFiles"}},                                   //nolint:exhaustruct,lll // not required for test
+			{ID: "second", Dependencies: []string{"first"}, SupportedExtensions: []string{"java"}, Script: []string{"echo $inputFiles"}}, //nolint:exhaustruct,lll // not required for test
+		},
+	}
+
+	builtRunModel, err := uut.NewRunModelBuilder().BuildRunModel(
+		&recipe,
+		runmodel.NewVariables(baseDir),
+		[]string{filepath.Join(baseDir, "**", "*.java")},
+		make([]runmodel.UnparsedFlag, 0),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	runs := (*builtRunModel).(refactoring.RunModel).Run
+
+	if len(runs) != 4 {
+		t.Fatalf("Expected 4 runs (2 runs for 2 batches), but was %d", len(runs))
+	}
+
+	expectedBranches := []string{"batch-1", "batch-1", "batch-2", "batch-2"}
+	expectedCommands := []string{
+		"echo " + filepath.Join(baseDir, "A.java") + " " + filepath.Join(baseDir, "sub", "B.java"),
+		"echo " + filepath.Join(baseDir, "A.java") + " " + filepath.Join(baseDir, "sub", "B.java"),
+		"echo " + filepath.Join(baseDir, "sub", "deep", "C.java"),
+		"echo " + filepath.Join(baseDir, "sub", "deep", "C.java"),
+	}
+
+	for index, run := range runs {
+		if run.Branch != expectedBranches[index] {
+			t.Errorf("Expected run %d to be in branch '%s', but was '%s'", index, expectedBranches[index], run.Branch)
+		}
+
+		if command := run.Command.Cmds[0][2]; command != expectedCommands[index] {
+			t.Errorf("Expected command of run %d to be '%s', but was '%s'", index, expectedCommands[index], command)
+		}
+	}
+
+	if runs[1].Dependencies[0] != runs[0] || runs[3].Dependencies[0] != runs[2] {
+		t.Errorf("Expected dependencies to be resolved within each branch")
+	}
+}
+
+func TestBuildRunModel_WildcardPathFanOutQuotesPaths(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+
+	for _, file := range []string{"My Class.java", "It's.java"} {
+		if err := os.WriteFile(filepath.Join(baseDir, file), []byte{}, 0o600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+
+	var recipe recipemodel.Recipe = &recipemodel.RefactoringRecipe{ //nolint:exhaustruct // tests are not required
+		BaseRecipe: recipemodel.BaseRecipe{}, //nolint:exhaustruct // not required for test
+		PrimaryParameter: &recipemodel.Parameter{ //nolint:exhaustruct // not required for test
+			ID:            "inputFiles",
+			TypeExtension: recipemodel.TypeExtension{Type: "wildcardPath", Extensions: []string{"java"}},
+			BatchSize:     2,
+		},
+		Runs: []recipemodel.Run{
+			{ID: "first", Script: []string{"cat $inputFiles"}, IncludeChangeLocations: []string{"$inputFiles"}}, //nolint:exhaustruct,lll // not required for test
+		},
+	}
+
+	builtRunModel, err := uut.NewRunModelBuilder().BuildRunModel(
+		&recipe,
+		runmodel.NewVariables(baseDir),
+		[]string{filepath.Join(baseDir, "*.java")},
+		make([]runmodel.UnparsedFlag, 0),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	run := (*builtRunModel).(refactoring.RunModel).Run[0]
+
+	expectedCommand := "cat '" + filepath.Join(baseDir, "It'\\''s.java") + "' '" + filepath.Join(baseDir, "My Class.java") + "'"
+	if command := run.Command.Cmds[0][2]; command != expectedCommand {
+		t.Errorf("Expected command to be '%s', but was '%s'", expectedCommand, command)
+	}
+
+	expectedLocations := []string{filepath.Join(baseDir, "It's.java"), filepath.Join(baseDir, "My Class.java")}
+	if !reflect.DeepEqual(run.ChangeLocations.Include, expectedLocations) {
+		t.Errorf("Expected change locations to be '%v', but was '%v'", expectedLocations, run.ChangeLocations.Include)
+	}
+}
@@ -1,13 +1,18 @@
 package refactoringrunmodelbuilder
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"chast.io/core/internal/internal_util/collection"
+	"chast.io/core/internal/internal_util/ignore"
+	"chast.io/core/internal/internal_util/shell"
 	recipemodel "chast.io/core/internal/recipe/pkg/model"
 	"chast.io/core/internal/run_model/internal/builder"
-	extensionsdetection "chast.io/core/internal/run_model/internal/extensions_detection"
+	fileclassification "chast.io/core/internal/run_model/internal/file_classification"
 	runmodel "chast.io/core/internal/run_model/pkg/model"
 	"chast.io/core/internal/run_model/pkg/model/refactoring"
 	"github.com/joomcode/errorx"
@@ -57,67 +62,132 @@ func (parser *RunModelBuilder) buildRunModel(
 		return nil, errorx.InternalError.Wrap(err, "Failed to handle flags")
 	}
 
+	if recipeModel.PrimaryParameter != nil && recipeModel.PrimaryParameter.Type == wildcardPathType {
+		return buildFanOutRunModel(recipeModel, variables)
+	}
+
+	if err := setBuiltinVariables(variables); err != nil {
+		return nil, err
+	}
+
 	var runModel runmodel.RunModel
 
-	filteredRuns, runsFilterError := filterRuns(recipeModel.Runs, variables)
+	filteredRuns, classificationStats, runsFilterError := filterRuns(recipeModel.Runs, []string{variables.TypeDetectionPath})
 	if runsFilterError != nil {
 		return nil, errorx.InternalError.Wrap(runsFilterError, "Failed to filter runs")
 	}
 
+	resolvedRuns, dependencyDecisions, dependencyResolutionError := resolveDependencies(filteredRuns)
+	if dependencyResolutionError != nil {
+		return nil, errorx.Decorate(dependencyResolutionError, "Failed to resolve run dependencies")
+	}
+
 	namedRuns := make(map[string]*refactoring.Run)
-	mappedRuns := collection.Map(filteredRuns,
-		func(run recipemodel.Run) *refactoring.Run { return convertRun(run, variables, namedRuns) },
-	)
+	mappedRuns := make([]*refactoring.Run, 0, len(resolvedRuns))
+
+	for _, run := range resolvedRuns {
+		convertedRun, conversionError := convertRun(run, variables, namedRuns)
+		if conversionError != nil {
+			return nil, conversionError
+		}
+
+		mappedRuns = append(mappedRuns, convertedRun)
+	}
 
 	runModel = refactoring.RunModel{
-		Run: mappedRuns,
+		Run:                 mappedRuns,
+		ClassificationStats: classificationStats,
+		DependencyDecisions: dependencyDecisions,
+		Variables:           variables,
 	}
 
 	return &runModel, nil
 }
 
-func filterRuns(runs []recipemodel.Run, variables *runmodel.Variables) ([]recipemodel.Run, error) {
-	extensions, extensionDetectionError := extensionsdetection.DetectExtensions(variables.TypeDetectionPath)
-	if extensionDetectionError != nil {
-		return nil, errorx.InternalError.Wrap(extensionDetectionError, "Failed to detect extensions")
+// filterRuns selects each run at most once if any file below the type detection paths matches its criteria.
+func filterRuns(
+	runs []recipemodel.Run,
+	typeDetectionPaths []string,
+) ([]recipemodel.Run, *refactoring.ClassificationStats, error) {
+	rules, rulesBuildError := buildClassificationRules(runs)
+	if rulesBuildError != nil {
+		return nil, nil, rulesBuildError
+	}
+
+	classification, classificationError := fileclassification.Classify(typeDetectionPaths, rules)
+	if classificationError != nil {
+		return nil, nil, errorx.InternalError.Wrap(classificationError, "Failed to classify files")
 	}
 
 	filteredRuns := make([]recipemodel.Run, 0)
 
-	for _, run := range runs {
-		if run.SupportedExtensions == nil || len(run.SupportedExtensions) == 0 {
+	for index, run := range runs {
+		if rules[index].IsUnrestricted() || classification.Matches[index] {
 			filteredRuns = append(filteredRuns, run)
+		}
+	}
 
-			continue
+	return filteredRuns, classification.Stats, nil
+}
+
+func buildClassificationRules(runs []recipemodel.Run) ([]*fileclassification.Rule, error) {
+	rules := make([]*fileclassification.Rule, 0, len(runs))
+
+	for index, run := range runs {
+		name := run.ID
+		if name == "" {
+			name = fmt.Sprintf("#%d", index+1)
 		}
 
-		for _, supportedExtension := range run.SupportedExtensions {
-			if extensions[supportedExtension] != nil {
-				filteredRuns = append(filteredRuns, run)
-			}
+		rule, ruleBuildError := fileclassification.NewRule(name, run.SupportedExtensions, run.SupportedFiles)
+		if ruleBuildError != nil {
+			return nil, errorx.InternalError.Wrap(ruleBuildError, "Failed to build file classification rule")
 		}
+
+		rules = append(rules, rule)
 	}
 
-	return filteredRuns, nil
+	return rules, nil
 }
 
 func convertRun(
 	run recipemodel.Run,
 	variables *runmodel.Variables,
 	namedRuns map[string]*refactoring.Run,
-) *refactoring.Run {
-	dependencies := convertDependencies(run.Dependencies, namedRuns)
+) (*refactoring.Run, error) {
+	dependencies := convertDependencies(run.GetAllDependencies(), namedRuns)
 	newRun := getOrComputeRunFromNamedRuns(run.ID, namedRuns)
 
+	variables = variables.Clone()
+	variables.Map[runmodel.RunIDVariable] = run.ID
+	variables.Map[runmodel.RunDirVariable] = convertWorkingDirectory(run, variables)
+
 	newRun.ID = run.ID
 	newRun.Dependencies = dependencies
 	newRun.SupportedLanguages = run.SupportedExtensions
-	newRun.Command = convertCommand(run.Script, variables)
+	command, commandConversionError := convertCommand(run, variables)
+	if commandConversionError != nil {
+		return nil, errorx.Decorate(commandConversionError, "Failed to convert script of run '%s'", run.ID)
+	}
+
+	newRun.Command = command
 	newRun.Docker = convertDocker(run.Docker)
 	newRun.Local = convertLocal(run.Local)
 	newRun.ChangeLocations = convertChangeLocations(run, variables)
+	newRun.Environment = variables.BuiltinVariables()
 
-	return newRun
+	return newRun, nil
+}
+
+func setBuiltinVariables(variables *runmodel.Variables) error {
+	invocationDirectory, getWDError := os.Getwd()
+	if getWDError != nil {
+		return errorx.ExternalError.Wrap(getWDError, "Failed to get invocation directory")
+	}
+
+	variables.SetBuiltinVariables(invocationDirectory)
+
+	return nil
 }
 
 func convertDependencies(dependencies []string, namedRuns map[string]*refactoring.Run) []*refactoring.Run {
@@ -151,22 +221,89 @@ func getOrComputeRunFromNamedRuns(runID string, namedRuns map[string]*refactorin
 	return newRun
 }
 
-func convertCommand(commands []string, variables *runmodel.Variables) *refactoring.Command {
-	cmds := collection.Map(commands, strings.Fields)
-	replaceVariablesWithValuesInCommands(cmds, variables.Map)
+// convertCommand builds the invocations passing each script entry verbatim to the shell of the run, or all entries
+// together if the run uses a single shell.
+func convertCommand(run recipemodel.Run, variables *runmodel.Variables) (*refactoring.Command, error) {
+	scripts := collection.Map(run.Script, func(script string) string {
+		return replaceVariablesWithValues(script, variables.Map)
+	})
+
+	if run.SingleShell {
+		scripts = []string{strings.Join(scripts, "\n")}
+	}
+
+	cmds := make([][]string, 0, len(scripts))
+
+	for _, script := range scripts {
+		invocation, invocationError := shell.Invocation(run.Shell, script)
+		if invocationError != nil {
+			return nil, invocationError
+		}
+
+		cmds = append(cmds, invocation)
+	}
+
+	timeout := time.Duration(0)
+
+	if run.Timeout != "" {
+		var timeoutParseError error
+
+		timeout, timeoutParseError = time.ParseDuration(run.Timeout)
+		if timeoutParseError != nil {
+			return nil, errorx.IllegalFormat.Wrap(timeoutParseError, "Invalid timeout '%s'", run.Timeout)
+		}
+	}
+
+	retry, retryConvertError := convertRetry(run.Retry)
+	if retryConvertError != nil {
+		return nil, retryConvertError
+	}
 
 	return &refactoring.Command{
 		Cmds:             cmds,
-		WorkingDirectory: filepath.Join(variables.WorkingDirectory, "run"),
-	}
+		WorkingDirectory: variables.Map[runmodel.RunDirVariable],
+		Timeout:          timeout,
+		AllowedExitCodes: run.AllowedExitCodes,
+		ContinueOnError:  run.ContinueOnError,
+		Retry:            retry,
+	}, nil
 }
 
-func replaceVariablesWithValuesInCommands(commands [][]string, arguments map[string]string) {
-	for i, cmd := range commands {
-		for j, cmdPart := range cmd {
-			commands[i][j] = replaceVariablesWithValues(cmdPart, arguments)
+func convertRetry(retry *recipemodel.Retry) (*refactoring.Retry, error) {
+	if retry == nil {
+		return nil, nil //nolint:nilnil // runs without retry have no retry
+	}
+
+	backoff := time.Duration(0)
+
+	if retry.Backoff != "" {
+		var backoffParseError error
+
+		backoff, backoffParseError = time.ParseDuration(retry.Backoff)
+		if backoffParseError != nil {
+			return nil, errorx.IllegalFormat.Wrap(backoffParseError, "Invalid backoff '%s'", retry.Backoff)
 		}
 	}
+
+	return &refactoring.Retry{
+		Attempts:    retry.Attempts,
+		Backoff:     backoff,
+		OnExitCodes: retry.OnExitCodes,
+	}, nil
+}
+
+// convertWorkingDirectory resolves the working directory of the run relative to the recipe.
+func convertWorkingDirectory(run recipemodel.Run, variables *runmodel.Variables) string {
+	if run.WorkingDirectory == "" {
+		return filepath.Join(variables.WorkingDirectory, "run")
+	}
+
+	workingDirectory := replaceVariablesWithValues(run.WorkingDirectory, variables.Map)
+	if !filepath.IsAbs(workingDirectory) {
+		workingDirectory = filepath.Join(variables.WorkingDirectory, workingDirectory)
+	}
+
+	return filepath.Clean(workingDirectory)
 }
 
 func replaceVariablesWithValues(value string, arguments map[string]string) string {
@@ -215,8 +352,14 @@ func convertChangeLocations(run recipemodel.Run, variables *runmodel.Variables)
 		return replaceVariablesWithValues(changeLocation, variables.Map)
 	})
 
+	ignoreRoot := ""
+	if !run.IncludeIgnoredChanges {
+		ignoreRoot = ignore.FindRoot(variables.TypeDetectionPath)
+	}
+
 	return &refactoring.ChangeLocations{
-		Include: includeLocations,
-		Exclude: excludeLocations,
+		Include:    includeLocations,
+		Exclude:    excludeLocations,
+		IgnoreRoot: ignoreRoot,
 	}
 }
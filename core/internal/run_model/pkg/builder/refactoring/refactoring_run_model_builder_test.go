@@ -0,0 +1,293 @@
+package refactoringrunmodelbuilder_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	uut "chast.io/core/internal/run_model/pkg/builder/refactoring"
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+)
+
+func TestBuildRunModel_RunSelection(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+
+	for _, file := range []string{"Main.java", "Main.kt", "Dockerfile"} {
+		if err := os.WriteFile(filepath.Join(baseDir, file), []byte{}, 0o600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+
+	var recipe recipemodel.Recipe = &recipemodel.RefactoringRecipe{ //nolint:exhaustruct // tests are not required
+		BaseRecipe: recipemodel.BaseRecipe{}, //nolint:exhaustruct // not required for test
+		PrimaryParameter: &recipemodel.Parameter{ //nolint:exhaustruct // not required for test
+			ID:            "inputFolder",
+			TypeExtension: recipemodel.TypeExtension{Type: "folderPath"}, //nolint:exhaustruct // type only
+		},
+		Runs: []recipemodel.Run{
+			{ID: "jvm", SupportedExtensions: []string{"java", "kt"}, Script: []string{"echo"}},                                       //nolint:exhaustruct,lll // not required for test
+			{ID: "docker", SupportedFiles: &recipemodel.SupportedFiles{Filenames: []string{"Dockerfile"}}, Script: []string{"echo"}}, //nolint:exhaustruct,lll // not required for test
+			{ID: "python", SupportedExtensions: []string{"py"}, Script: []string{"echo"}},                                            //nolint:exhaustruct,lll // not required for test
+		},
+	}
+
+	builtRunModel, err := uut.NewRunModelBuilder().BuildRunModel(
+		&recipe,
+		runmodel.NewVariables(baseDir),
+		[]string{baseDir},
+		make([]runmodel.UnparsedFlag, 0),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	concreteRunModel, _ := (*builtRunModel).(refactoring.RunModel)
+
+	runIDs := make([]string, 0)
+	for _, run := range concreteRunModel.Run {
+		runIDs = append(runIDs, run.ID)
+	}
+
+	if len(runIDs) != 2 || runIDs[0] != "jvm" || runIDs[1] != "docker" {
+		t.Errorf("Expected runs to be '[jvm docker]', but was '%v'", runIDs)
+	}
+
+	if concreteRunModel.ClassificationStats.ScannedFiles != 3 {
+		t.Errorf("Expected 3 scanned files, but was %d", concreteRunModel.ClassificationStats.ScannedFiles)
+	}
+}
+
+func buildDependencyTestRunModel(t *testing.T, runs []recipemodel.Run) (*refactoring.RunModel, error) {
+	t.Helper()
+
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "Main.java"), []byte{}, 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	var recipe recipemodel.Recipe = &recipemodel.RefactoringRecipe{ //nolint:exhaustruct // tests are not required
+		BaseRecipe: recipemodel.BaseRecipe{}, //nolint:exhaustruct // not required for test
+		PrimaryParameter: &recipemodel.Parameter{ //nolint:exhaustruct // not required for test
+			ID:            "inputFolder",
+			TypeExtension: recipemodel.TypeExtension{Type: "folderPath"}, //nolint:exhaustruct // type only
+		},
+		Runs: runs,
+	}
+
+	builtRunModel, err := uut.NewRunModelBuilder().BuildRunModel(
+		&recipe,
+		runmodel.NewVariables(baseDir),
+		[]string{baseDir},
+		make([]runmodel.UnparsedFlag, 0),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	concreteRunModel, _ := (*builtRunModel).(refactoring.RunModel)
+
+	return &concreteRunModel, nil
+}
+
+func TestBuildRunModel_DependencyResolution(t *testing.T) {
+	t.Parallel()
+
+	runModel, err := buildDependencyTestRunModel(t, []recipemodel.Run{
+		{ID: "java", SupportedExtensions: []string{"java"}, Script: []string{"echo"}},        //nolint:exhaustruct,lll // not required for test
+		{ID: "python", SupportedExtensions: []string{"py"}, Script: []string{"echo"}},        //nolint:exhaustruct,lll // not required for test
+		{ID: "formatPython", Dependencies: []string{"python"}, Script: []string{"echo"}},     //nolint:exhaustruct,lll // not required for test
+		{ID: "lintPython", Dependencies: []string{"formatPython"}, Script: []string{"echo"}}, //nolint:exhaustruct,lll // not required for test
+		{ID: "format", After: []string{"java", "python"}, Script: []string{"echo"}},          //nolint:exhaustruct,lll // not required for test
+		{ID: "mvJava", Requires: []string{"java"}, Script: []string{"echo"}},                 //nolint:exhaustruct,lll // not required for test
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	runIDs := make([]string, 0)
+	for _, run := range runModel.Run {
+		runIDs = append(runIDs, run.ID)
+	}
+
+	expectedRunIDs := []string{"java", "format", "mvJava"}
+	if !reflect.DeepEqual(runIDs, expectedRunIDs) {
+		t.Errorf("Expected runs to be '%v', but was '%v'", expectedRunIDs, runIDs)
+	}
+
+	if dependencies := runModel.Run[1].Dependencies; len(dependencies) != 1 || dependencies[0] != runModel.Run[0] {
+		t.Errorf("Expected format to only run after java, but had %d dependencies", len(dependencies))
+	}
+
+	expectedDecisions := []refactoring.DependencyDecision{
+		{RunID: "formatPython", DependencyID: "python", Kind: refactoring.Dependency, Skipped: true, Branch: ""},
+		{RunID: "lintPython", DependencyID: "formatPython", Kind: refactoring.Dependency, Skipped: true, Branch: ""},
+		{RunID: "format", DependencyID: "python", Kind: refactoring.After, Skipped: false, Branch: ""},
+	}
+	if !reflect.DeepEqual(runModel.DependencyDecisions, expectedDecisions) {
+		t.Errorf("Expected decisions to be '%v', but was '%v'", expectedDecisions, runModel.DependencyDecisions)
+	}
+}
+
+func TestBuildRunModel_MissingRequiredRun(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildDependencyTestRunModel(t, []recipemodel.Run{
+		{ID: "python", SupportedExtensions: []string{"py"}, Script: []string{"echo"}}, //nolint:exhaustruct,lll // not required for test
+		{ID: "mvPython", Requires: []string{"python"}, Script: []string{"echo"}},      //nolint:exhaustruct,lll // not required for test
+	})
+	if err == nil {
+		t.Fatal("Expected error, but was nil")
+	}
+
+	if !strings.Contains(err.Error(), "'mvPython' requires 'python'") {
+		t.Errorf("Expected error to name the missing requirement, but was '%v'", err)
+	}
+}
+
+func TestBuildRunModel_BuiltinVariables(t *testing.T) {
+	t.Parallel()
+
+	runModel, err := buildDependencyTestRunModel(t, []recipemodel.Run{
+		{ID: "java", Script: []string{"echo $CHAST_RUN_ID ${CHAST_RECIPE_DIR} $CHAST_STEP_UUID"}}, //nolint:exhaustruct,lll // not required for test
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	run := runModel.Run[0]
+
+	if run.Environment[runmodel.RunIDVariable] != "java" {
+		t.Errorf("Expected run id variable to be 'java', but was '%s'", run.Environment[runmodel.RunIDVariable])
+	}
+
+	for _, name := range []string{runmodel.RecipeDirVariable, runmodel.RunDirVariable, runmodel.InvocationDirVariable, runmodel.ProjectRootVariable} { //nolint:lll // list of variables
+		if run.Environment[name] == "" {
+			t.Errorf("Expected variable %s to be set, but was empty", name)
+		}
+	}
+
+	expectedScript := "echo java " + run.Environment[runmodel.RecipeDirVariable] + " $CHAST_STEP_UUID"
+	expectedCmd := []string{"/bin/bash", "-c", expectedScript}
+	if !reflect.DeepEqual(run.Command.Cmds[0], expectedCmd) {
+		t.Errorf("Expected command to be '%v', but was '%v'", expectedCmd, run.Command.Cmds[0])
+	}
+}
+
+func TestBuildRunModel_Shell(t *testing.T) {
+	t.Parallel()
+
+	runModel, err := buildDependencyTestRunModel(t, []recipemodel.Run{
+		{ID: "none", Shell: "none", Script: []string{`comby 'f(:[a], :[b])' 'g(:[a])' -in-place`}}, //nolint:exhaustruct,lll // not required for test
+		{ID: "single", SingleShell: true, Script: []string{"cd src", "ls"}},                        //nolint:exhaustruct,lll // not required for test
+		{ID: "python", Shell: "python3", Script: []string{"print('a')\nprint('b')"}},               //nolint:exhaustruct,lll // not required for test
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	expectedCmds := [][][]string{
+		{{"comby", "f(:[a], :[b])", "g(:[a])", "-in-place"}},
+		{{"/bin/bash", "-c", "cd src\nls"}},
+		{{"python3", "-c", "print('a')\nprint('b')"}},
+	}
+
+	for index, run := range runModel.Run {
+		if !reflect.DeepEqual(run.Command.Cmds, expectedCmds[index]) {
+			t.Errorf("Expected commands of run %s to be %q, but was %q", run.ID, expectedCmds[index], run.Command.Cmds)
+		}
+	}
+}
+
+func TestBuildRunModel_WorkingDirectory(t *testing.T) {
+	t.Parallel()
+
+	runModel, err := buildDependencyTestRunModel(t, []recipemodel.Run{
+		{ID: "default", Script: []string{"echo $CHAST_RUN_DIR"}},                                    //nolint:exhaustruct,lll // not required for test
+		{ID: "relative", WorkingDirectory: "tools/../scripts", Script: []string{"echo"}},            //nolint:exhaustruct,lll // not required for test
+		{ID: "invocation", WorkingDirectory: "$CHAST_INVOCATION_DIR/sub", Script: []string{"echo"}}, //nolint:exhaustruct,lll // not required for test
+		{ID: "absolute", WorkingDirectory: "/opt/tool", Script: []string{"echo"}},                   //nolint:exhaustruct,lll // not required for test
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	recipeDirectory := runModel.Variables.WorkingDirectory
+	invocationDirectory, _ := os.Getwd()
+
+	expectedWorkingDirectories := []string{
+		filepath.Join(recipeDirectory, "run"),
+		filepath.Join(recipeDirectory, "scripts"),
+		filepath.Join(invocationDirectory, "sub"),
+		"/opt/tool",
+	}
+
+	for index, run := range runModel.Run {
+		if run.Command.WorkingDirectory != expectedWorkingDirectories[index] {
+			t.Errorf("Expected working directory of run %s to be '%s', but was '%s'",
+				run.ID, expectedWorkingDirectories[index], run.Command.WorkingDirectory)
+		}
+
+		if run.Environment[runmodel.RunDirVariable] != expectedWorkingDirectories[index] {
+			t.Errorf("Expected run directory variable of run %s to be '%s', but was '%s'",
+				run.ID, expectedWorkingDirectories[index], run.Environment[runmodel.RunDirVariable])
+		}
+	}
+
+	if script := runModel.Run[0].Command.Cmds[0][2]; script != "echo "+expectedWorkingDirectories[0] {
+		t.Errorf("Expected script to contain the run directory, but was '%s'", script)
+	}
+}
+
+func TestBuildRunModel_FailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	runModel, err := buildDependencyTestRunModel(t, []recipemodel.Run{
+		{ID: "strict", Script: []string{"lint"}},                                                           //nolint:exhaustruct,lll // not required for test
+		{ID: "lenient", AllowedExitCodes: []int{1}, ContinueOnError: true, Script: []string{"lint --fix"}}, //nolint:exhaustruct,lll // not required for test
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	strict, lenient := runModel.Run[0].Command, runModel.Run[1].Command
+
+	if strict.ContinueOnError || len(strict.AllowedExitCodes) != 0 {
+		t.Errorf("Expected run without failure policy to fail on errors, but was %+v", strict)
+	}
+
+	if !lenient.ContinueOnError || !reflect.DeepEqual(lenient.AllowedExitCodes, []int{1}) {
+		t.Errorf("Expected run to continue on errors and allow exit code 1, but was %+v", lenient)
+	}
+}
+
+func TestBuildRunModel_Retry(t *testing.T) {
+	t.Parallel()
+
+	runModel, err := buildDependencyTestRunModel(t, []recipemodel.Run{
+		{ID: "once", Script: []string{"rewrite"}}, //nolint:exhaustruct // not required for test
+		{ //nolint:exhaustruct // not required for test
+			ID:     "flaky",
+			Retry:  &recipemodel.Retry{Attempts: 3, Backoff: "2s", OnExitCodes: []int{137}},
+			Script: []string{"rewrite --jvm"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if runModel.Run[0].Command.Retry != nil {
+		t.Errorf("Expected run without retry not to be retried, but was %+v", runModel.Run[0].Command.Retry)
+	}
+
+	expectedRetry := &refactoring.Retry{Attempts: 3, Backoff: 2 * time.Second, OnExitCodes: []int{137}}
+	if !reflect.DeepEqual(runModel.Run[1].Command.Retry, expectedRetry) {
+		t.Errorf("Expected retry to be %+v, but was %+v", expectedRetry, runModel.Run[1].Command.Retry)
+	}
+}
@@ -0,0 +1,56 @@
+package runmodel
+
+import (
+	"path/filepath"
+
+	"chast.io/core/internal/internal_util/ignore"
+)
+
+// Built-in variables are available to all scripts, both for substitution and in the process environment.
+// The step UUID and the changed files are only known when a step runs, so they are only part of the environment and
+// substituted by the shell.
+const (
+	RecipeDirVariable = "CHAST_RECIPE_DIR"
+	// RunDirVariable is the working directory of the run.
+	RunDirVariable        = "CHAST_RUN_DIR"
+	InvocationDirVariable = "CHAST_INVOCATION_DIR"
+	ProjectRootVariable   = "CHAST_PROJECT_ROOT"
+	RunIDVariable         = "CHAST_RUN_ID"
+	StepUUIDVariable      = "CHAST_STEP_UUID"
+	// ChangedFilesVariable contains the space separated files changed by the dependencies of a step.
+	ChangedFilesVariable = "CHAST_CHANGED_FILES"
+)
+
+func builtinVariableNames() []string {
+	return []string{
+		RecipeDirVariable,
+		RunDirVariable,
+		InvocationDirVariable,
+		ProjectRootVariable,
+		RunIDVariable,
+		StepUUIDVariable,
+		ChangedFilesVariable,
+	}
+}
+
+// SetBuiltinVariables sets the built-in variables which are known before the runs are scheduled.
+// The type detection path has to be set beforehand to find the project root.
+func (v *Variables) SetBuiltinVariables(invocationDirectory string) {
+	v.Map[RecipeDirVariable] = v.WorkingDirectory
+	v.Map[RunDirVariable] = filepath.Join(v.WorkingDirectory, "run")
+	v.Map[InvocationDirVariable] = invocationDirectory
+	v.Map[ProjectRootVariable] = ignore.FindRoot(v.TypeDetectionPath)
+}
+
+// BuiltinVariables returns the built-in variables which are set.
+func (v *Variables) BuiltinVariables() map[string]string {
+	builtinVariables := make(map[string]string)
+
+	for _, name := range builtinVariableNames() {
+		if value, isSet := v.Map[name]; isSet {
+			builtinVariables[name] = value
+		}
+	}
+
+	return builtinVariables
+}
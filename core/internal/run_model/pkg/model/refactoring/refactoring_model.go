@@ -1,9 +1,19 @@
 package refactoring
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+	"github.com/google/uuid"
+)
 
 type RunModel struct {
-	Run []*Run
+	Run                 []*Run
+	ClassificationStats *ClassificationStats
+	// DependencyDecisions records how dependencies on runs which are not selected were resolved.
+	DependencyDecisions []DependencyDecision
+	// Variables are the resolved arguments and flags the runs were built with.
+	Variables *runmodel.Variables
 }
 
 type SingleRunModel struct {
@@ -19,11 +29,18 @@ type Run struct {
 	Local              *Local
 	Command            *Command
 	ChangeLocations    *ChangeLocations
+
+	// Branch identifies the batch of files a run belongs to if the primary parameter is fanned out, empty otherwise.
+	Branch string
+	// Environment contains the built-in variables passed to the processes of the run.
+	Environment map[string]string
 }
 
 type ChangeLocations struct {
 	Include []string
 	Exclude []string
+	// IgnoreRoot is the folder from which on ignore files are respected. Empty if ignored changes are included.
+	IgnoreRoot string
 }
 
 func (run *Run) GetUUID() string {
@@ -39,6 +56,11 @@ func (run *Run) GetUUID() string {
 	return run.uuid
 }
 
+// SetUUID restores the uuid of a run which was created before, e.g. to resume its pipeline.
+func (run *Run) SetUUID(uuid string) {
+	run.uuid = uuid
+}
+
 type Docker struct {
 	DockerImage string
 }
@@ -55,4 +77,99 @@ type RequiredTool struct {
 type Command struct {
 	Cmds             [][]string
 	WorkingDirectory string
+	// Timeout limits how long the commands may run, zero means no limit.
+	Timeout time.Duration
+	// AllowedExitCodes are exit codes which do not fail a command in addition to zero.
+	AllowedExitCodes []int
+	// ContinueOnError runs the remaining commands after a command failed and keeps the changes of the run.
+	ContinueOnError bool
+	// Retry is nil if a failed command fails the run right away.
+	Retry *Retry
+}
+
+// Retry configures how often the commands of a run are attempted.
+type Retry struct {
+	// Attempts is the maximum number of attempts, including the first one.
+	Attempts int
+	// Backoff is waited before each further attempt.
+	Backoff time.Duration
+	// OnExitCodes restricts the retries to failed commands with these exit codes, all failures are retried if empty.
+	OnExitCodes []int
+}
+
+// IsRetryable checks if a failed attempt with the exit code is retried. The exit code is nil if it is unknown, e.g.
+// because the commands timed out.
+func (retry *Retry) IsRetryable(exitCode *int) bool {
+	if len(retry.OnExitCodes) == 0 {
+		return true
+	}
+
+	if exitCode == nil {
+		return false
+	}
+
+	for _, retryExitCode := range retry.OnExitCodes {
+		if *exitCode == retryExitCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DependencyKind describes how a run depends on another run.
+type DependencyKind = string
+
+const (
+	// Dependency skips the dependent run if the dependency is not selected.
+	Dependency DependencyKind = "dependencies"
+	// Requires fails the plan if the dependency is not selected.
+	Requires DependencyKind = "requires"
+	// After only orders the dependent run after the dependency if it is selected.
+	After DependencyKind = "after"
+)
+
+// DependencyDecision records how a dependency on a run which is not selected was resolved.
+type DependencyDecision struct {
+	RunID        string         `json:"runId"`
+	DependencyID string         `json:"dependencyId"`
+	Kind         DependencyKind `json:"kind"`
+	// Skipped is true if the dependent run was skipped, false if only the ordering was dropped.
+	Skipped bool `json:"skipped"`
+	// Branch is the batch of files the decision was made for if the primary parameter is fanned out.
+	Branch string `json:"branch,omitempty"`
+}
+
+// ClassificationStats summarizes the classification of the files the runs were selected for.
+type ClassificationStats struct {
+	ScannedFiles int `json:"scannedFiles"`
+	// IgnoredPaths counts the files and folders skipped due to ignore files.
+	IgnoredPaths int `json:"ignoredPaths"`
+	// Extensions counts the scanned files per extension (without the leading ".").
+	Extensions map[string]int `json:"extensions"`
+	// MatchedFiles counts the matching files per run. Runs without any file criteria match all files.
+	MatchedFiles map[string]int `json:"matchedFiles"`
+}
+
+func NewClassificationStats() *ClassificationStats {
+	return &ClassificationStats{
+		ScannedFiles: 0,
+		IgnoredPaths: 0,
+		Extensions:   make(map[string]int),
+		MatchedFiles: make(map[string]int),
+	}
+}
+
+// Add adds the counts of the other stats, e.g. of another branch, to the stats.
+func (stats *ClassificationStats) Add(other *ClassificationStats) {
+	stats.ScannedFiles += other.ScannedFiles
+	stats.IgnoredPaths += other.IgnoredPaths
+
+	for extension, count := range other.Extensions {
+		stats.Extensions[extension] += count
+	}
+
+	for run, count := range other.MatchedFiles {
+		stats.MatchedFiles[run] += count
+	}
 }
@@ -23,3 +23,18 @@ type UnparsedFlag struct {
 	Name  string
 	Value string
 }
+
+// Clone returns a copy of the variables which can be changed without affecting the original.
+func (v *Variables) Clone() *Variables {
+	clonedMap := make(map[string]string, len(v.Map))
+	for key, value := range v.Map {
+		clonedMap[key] = value
+	}
+
+	return &Variables{
+		WorkingDirectory:  v.WorkingDirectory,
+		Map:               clonedMap,
+		DefaultValueUsed:  v.DefaultValueUsed,
+		TypeDetectionPath: v.TypeDetectionPath,
+	}
+}
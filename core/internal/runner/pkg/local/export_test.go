@@ -0,0 +1,20 @@
+package local
+
+import (
+	"context"
+
+	refactoringPipelineModel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+)
+
+// NewRunnerWithCommands creates a runner which runs the commands of the steps with commands instead of isolating them.
+func NewRunnerWithCommands(
+	jobs int,
+	commands func(ctx context.Context, step *refactoringPipelineModel.Step, outputPrefix string) error,
+) *Runner {
+	return newRunner(commands, jobs, nil)
+}
+
+// ScratchCopySource returns the folder copied for the step if it runs in a scratch copy of the project.
+func ScratchCopySource(step *refactoringPipelineModel.Step) string {
+	return scratchCopySource(step)
+}
@@ -1,83 +1,360 @@
 package local
 
 import (
+	"context"
 	"os"
+	"time"
 
 	changeisolator "chast.io/core/internal/changeisolator/pkg"
 	"chast.io/core/internal/changeisolator/pkg/namespace"
 	"chast.io/core/internal/changeisolator/pkg/strategy"
 	chastlog "chast.io/core/internal/logger"
+	"chast.io/core/internal/pipeline/pkg/event"
+	"chast.io/core/internal/pipeline/pkg/manifest"
+	"chast.io/core/internal/pipeline/pkg/metrics"
 	refactoringPipelineModel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	stepcache "chast.io/core/internal/pipeline/pkg/step_cache"
+	refactoringpipelinecleanup "chast.io/core/internal/post_processing/cleanup/pkg/refactoring"
 	pipelinepostprocessor "chast.io/core/internal/post_processing/pipeline_post_processor/pkg/refactoring"
 	steppostprocessor "chast.io/core/internal/post_processing/step_post_processor/pkg/refactoring"
 	"github.com/joomcode/errorx"
 )
 
 type Runner struct {
-	isolated bool
-	parallel bool
+	// commands runs the commands of a step in an isolated environment or in a scratch copy of the project.
+	commands stepCommands
+	// jobs is the maximum number of steps of an execution group running at the same time.
+	jobs int
+	// stepCache is nil if every step runs, even if its changes are cached.
+	stepCache *stepcache.Cache
 }
 
-func NewRunner(isolated bool, parallel bool) *Runner {
+// stepCommands runs the commands of the step and captures their changes. The output of the commands is prefixed with
+// the output prefix, unless it is empty.
+type stepCommands func(ctx context.Context, step *refactoringPipelineModel.Step, outputPrefix string) error
+
+// NewRunner creates a runner which runs up to jobs independent steps at the same time, at least one. The commands of
+// the steps run in an isolated environment if isolated is set, otherwise in a scratch copy of the project.
+func NewRunner(isolated bool, jobs int, stepCache *stepcache.Cache) *Runner {
+	if !isolated {
+		return newRunner(runInScratchCopy, jobs, stepCache)
+	}
+
+	return newRunner(runIsolated, jobs, stepCache)
+}
+
+func newRunner(commands stepCommands, jobs int, stepCache *stepcache.Cache) *Runner {
+	if jobs < 1 {
+		jobs = 1
+	}
+
 	return &Runner{
-		isolated: isolated,
-		parallel: parallel,
+		commands:  commands,
+		jobs:      jobs,
+		stepCache: stepCache,
 	}
 }
 
-func (r *Runner) Run(pipeline *refactoringPipelineModel.Pipeline) error {
+// Run runs all steps of the pipeline which are not completed in the manifest and records their progress in it.
+// No further steps are started once the context is done, the running steps are stopped.
+func (r *Runner) Run(
+	ctx context.Context,
+	pipeline *refactoringPipelineModel.Pipeline,
+	state *manifest.Manifest,
+) error {
 	chastlog.Log.Printf("Running pipeline %s", pipeline.UUID)
 
-	if r.isolated && !r.parallel {
-		return sequentialRun(pipeline)
+	startTime := time.Now()
+
+	pipeline.Notify(event.Event{ //nolint:exhaustruct // only the fields of the event type are set
+		Type:  event.PipelineStarted,
+		Steps: len(state.Steps),
+	})
+
+	runError := r.runSteps(ctx, pipeline, state)
+
+	pipeline.Notify(event.Event{ //nolint:exhaustruct // only the fields of the event type are set
+		Type:           event.PipelineFinished,
+		Status:         eventStatus(runError, event.Succeeded),
+		DurationMillis: time.Since(startTime).Milliseconds(),
+		Error:          event.ErrorMessage(runError),
+	})
+
+	return runError
+}
+
+func (r *Runner) runSteps(
+	ctx context.Context,
+	pipeline *refactoringPipelineModel.Pipeline,
+	state *manifest.Manifest,
+) error {
+	if err := preparePendingSteps(pipeline, state); err != nil {
+		return recordFailure(state, errorx.InternalError.Wrap(err, "Error preparing steps"))
+	}
+
+	notifyQueuedSteps(pipeline, state)
+
+	scheduler := newStepScheduler(state, r.stepCache, r.jobs, r.commands)
+
+	for _, stage := range pipeline.ExecutionGroups {
+		if err := scheduler.runExecutionGroup(ctx, stage); err != nil {
+			return err
+		}
+	}
+
+	if err := postProcessPipeline(pipeline); err != nil {
+		return recordFailure(state, err)
+	}
+
+	state.Completed()
+
+	return saveState(state, nil)
+}
+
+func postProcessPipeline(pipeline *refactoringPipelineModel.Pipeline) error {
+	chastlog.Log.Printf("Running pipeline post processing")
+
+	pipeline.Notify(event.Event{Type: event.PostProcessingStarted}) //nolint:exhaustruct // no further fields
+
+	startTime := time.Now()
+	postProcessingError := removeFinalChangesAndPostProcess(pipeline)
+
+	pipeline.Notify(event.Event{ //nolint:exhaustruct // only the fields of the event type are set
+		Type:           event.PostProcessingFinished,
+		Status:         eventStatus(postProcessingError, event.Succeeded),
+		DurationMillis: time.Since(startTime).Milliseconds(),
+		Error:          event.ErrorMessage(postProcessingError),
+		Timings:        pipeline.Timings,
+	})
+
+	return postProcessingError
+}
+
+func removeFinalChangesAndPostProcess(pipeline *refactoringPipelineModel.Pipeline) error {
+	if err := os.RemoveAll(pipeline.GetFinalChangeCaptureLocation()); err != nil {
+		return errorx.ExternalError.Wrap(err, "Error removing changes of previous attempt")
 	}
 
-	return errorx.NotImplemented.New("Unisolated and parallel execution is not yet implemented")
+	startTime := time.Now()
+	mergeError := pipelinepostprocessor.Process(pipeline)
+
+	pipeline.Timings = append(pipeline.Timings, metrics.NewPhaseTiming(metrics.Merge, time.Since(startTime)))
+
+	if mergeError != nil {
+		return errorx.InternalError.Wrap(mergeError, "Error running post processing")
+	}
+
+	return nil
 }
 
-func sequentialRun(pipeline *refactoringPipelineModel.Pipeline) error {
+// preparePendingSteps removes anything left by a previous attempt of the steps which are not completed and publishes
+// the changes of their completed dependencies again.
+func preparePendingSteps(pipeline *refactoringPipelineModel.Pipeline, state *manifest.Manifest) error {
+	pendingSteps := make([]*refactoringPipelineModel.Step, 0)
+
 	for _, stage := range pipeline.ExecutionGroups {
 		for _, step := range stage.Steps {
-			chastlog.Log.Printf("Running step %s", step.UUID)
+			if state.IsCompleted(step.UUID) {
+				continue
+			}
 
-			if err := runIsolated(step); err != nil {
-				return errorx.InternalError.Wrap(err, "Error running isolated")
+			if err := refactoringpipelinecleanup.ResetStep(step); err != nil {
+				return errorx.InternalError.Wrap(err, "Failed to reset step %s", step.UUID)
 			}
+
+			pendingSteps = append(pendingSteps, step)
 		}
 	}
 
-	chastlog.Log.Printf("Running pipeline post processing")
+	for _, step := range pendingSteps {
+		for _, dependency := range step.Dependencies {
+			if !state.IsCompleted(dependency.UUID) {
+				continue
+			}
 
-	if err := pipelinepostprocessor.Process(pipeline); err != nil {
-		return errorx.InternalError.Wrap(err, "Error running post processing")
+			if err := steppostprocessor.PublishChanges(dependency, step); err != nil {
+				return errorx.InternalError.Wrap(err, "Failed to restore changes of step %s", dependency.UUID)
+			}
+		}
 	}
 
 	return nil
 }
 
-func runIsolated(
+func recordFailure(state *manifest.Manifest, err error) error {
+	state.Failed(err)
+
+	return saveState(state, err)
+}
+
+// saveState saves the state and returns the given error, which takes precedence over an error saving the state.
+func saveState(state *manifest.Manifest, err error) error {
+	if saveError := state.Save(); saveError != nil {
+		if err != nil {
+			chastlog.Log.Errorf("Failed to save pipeline state: %v", saveError)
+
+			return err
+		}
+
+		return errorx.InternalError.Wrap(saveError, "Error saving pipeline state")
+	}
+
+	return err
+}
+
+// runStep runs the step with run, or restores its changes from the cache if they are cached.
+// It returns event.Cached if the changes were restored.
+func runStep(
+	ctx context.Context,
 	step *refactoringPipelineModel.Step,
-) error {
+	state *manifest.Manifest,
+	stepCache *stepcache.Cache,
+	run func(ctx context.Context) error,
+) (event.Status, error) {
+	step.Timings = make([]metrics.PhaseTiming, 0)
+	step.ResourceUsage = nil
+
 	if err := os.MkdirAll(step.GetMergedPreviousChangesLocation(), os.ModePerm); err != nil {
-		return errorx.ExternalError.Wrap(err, "Failed to create previous changes directory")
+		return event.Failed, errorx.ExternalError.Wrap(err, "Failed to create previous changes directory")
+	}
+
+	cacheKey := stepCacheKey(step, state, stepCache)
+
+	if cacheKey != "" {
+		restored, restoreError := stepCache.Restore(cacheKey, step)
+		if restoreError != nil {
+			chastlog.Log.Warnf("Failed to restore cached changes of step %s: %v", step.UUID, restoreError)
+		}
+
+		if restored {
+			chastlog.Log.Printf("Reusing cached changes of step %s", step.UUID)
+
+			return finishedStep(postProcessStep(step), event.Cached)
+		}
 	}
 
-	var nsContext = namespace.NewContext(
+	if err := runAttempts(ctx, step, run); err != nil {
+		return event.Failed, err
+	}
+
+	// the changes of steps which continued after a failed command are partial, running the step again may complete them
+	if cacheKey != "" && namespace.FirstFailure(step.CommandResults) == nil {
+		if err := stepCache.Store(cacheKey, step); err != nil {
+			chastlog.Log.Warnf("Failed to cache changes of step %s: %v", step.UUID, err)
+		}
+	}
+
+	return finishedStep(postProcessStep(step), event.Succeeded)
+}
+
+// stepCacheKey returns the cache key of the step, or an empty key if the step is not cacheable.
+func stepCacheKey(step *refactoringPipelineModel.Step, state *manifest.Manifest, stepCache *stepcache.Cache) string {
+	if stepCache == nil || state.Plan == nil {
+		return ""
+	}
+
+	key, keyError := stepcache.Key(step, state.Plan.RecipeHash, state.Plan.InputHash)
+	if keyError != nil {
+		chastlog.Log.Warnf("Failed to determine cache key of step %s: %v", step.UUID, keyError)
+
+		return ""
+	}
+
+	return key
+}
+
+// commandRunner runs the commands of a namespace context and captures their changes.
+type commandRunner func(
+	ctx context.Context,
+	nsContext *namespace.Context,
+) ([]namespace.CommandResult, namespace.RunMetrics, error)
+
+// runIsolated runs the commands of the step in an isolated environment and stops them once the timeout of the step
+// elapsed.
+func runIsolated(
+	ctx context.Context,
+	step *refactoringPipelineModel.Step,
+	outputPrefix string,
+) error {
+	nsContext, contextError := buildNamespaceContext(
+		step,
 		step.Pipeline.RootFileSystemLocation,
 		step.GetPreviousChangeCaptureLocations(),
+		outputPrefix,
+	)
+	if contextError != nil {
+		return contextError
+	}
+
+	return runCommands(ctx, step, nsContext, changeisolator.RunCommandInIsolatedEnvironment)
+}
+
+func buildNamespaceContext(
+	step *refactoringPipelineModel.Step,
+	rootFolder string,
+	mergeFolders []string,
+	outputPrefix string,
+) (*namespace.Context, error) {
+	environment, environmentBuildError := buildStepEnvironment(step)
+	if environmentBuildError != nil {
+		return nil, errorx.InternalError.Wrap(environmentBuildError, "Failed to build step environment")
+	}
+
+	return namespace.NewContext(
+		rootFolder,
+		mergeFolders,
 		step.ChangeCaptureLocation,
 		step.OperationLocation,
 		step.RunModel.Run.Command.WorkingDirectory,
 		step.RunModel.Run.Command.Cmds,
+		environment,
+		step.RunModel.Run.Command.AllowedExitCodes,
+		step.RunModel.Run.Command.ContinueOnError,
+		step.GetLogFile(),
+		outputPrefix,
 		strategy.UnionFS,
-	)
+	), nil
+}
 
-	if err := changeisolator.RunCommandInIsolatedEnvironment(nsContext); err != nil {
-		return errorx.InternalError.New("Error running command in isolated environment - %s", err)
+// runCommands runs the commands of the step with the runner and stops them once the timeout of the step elapsed.
+func runCommands(
+	ctx context.Context,
+	step *refactoringPipelineModel.Step,
+	nsContext *namespace.Context,
+	run commandRunner,
+) error {
+	if timeout := step.RunModel.Run.Command.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	if err := steppostprocessor.Process(step); err != nil {
-		return errorx.InternalError.Wrap(err, "Error running post processing")
+	results, runMetrics, runError := run(ctx, nsContext)
+	step.CommandResults = results
+	step.Timings = append(step.Timings, metrics.IsolatedRunTimings(runMetrics)...)
+	step.ResourceUsage = metrics.AddIsolatedRun(step.ResourceUsage, runMetrics)
+
+	if runError != nil {
+		return errorx.InternalError.Wrap(runError, "Error running commands of step")
+	}
+
+	if failure := namespace.FirstFailure(results); failure != nil {
+		chastlog.Log.Warnf("Continuing after command \"%s\" of step %s failed with exit code %d",
+			failure.CommandString(), step.UUID, failure.ExitCode)
+	}
+
+	return nil
+}
+
+func postProcessStep(step *refactoringPipelineModel.Step) error {
+	startTime := time.Now()
+	postProcessingError := steppostprocessor.Process(step)
+
+	step.Timings = append(step.Timings, metrics.NewPhaseTiming(metrics.StepPostProcessing, time.Since(startTime)))
+
+	if postProcessingError != nil {
+		return errorx.InternalError.Wrap(postProcessingError, "Error running post processing")
 	}
 
 	return nil
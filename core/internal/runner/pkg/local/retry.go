@@ -0,0 +1,65 @@
+package local
+
+import (
+	"context"
+	"time"
+
+	chastlog "chast.io/core/internal/logger"
+	"chast.io/core/internal/pipeline/pkg/event"
+	refactoringPipelineModel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	refactoringpipelinecleanup "chast.io/core/internal/post_processing/cleanup/pkg/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+// runAttempts runs the step with run until an attempt succeeds or the retry policy of its run does not allow another
+// attempt. The changes captured by a failed attempt are discarded before the next attempt starts.
+func runAttempts(ctx context.Context, step *refactoringPipelineModel.Step, run func(ctx context.Context) error) error {
+	retry := step.RunModel.Run.Command.Retry
+
+	for attempt := 1; ; attempt++ {
+		runError := run(ctx)
+		if runError == nil || retry == nil || attempt >= retry.Attempts || ctx.Err() != nil {
+			return runError
+		}
+
+		failedExitCode := exitCode(runError)
+		if !retry.IsRetryable(failedExitCode) {
+			return runError
+		}
+
+		chastlog.Log.Warnf("Attempt %d of %d of step %s failed, retrying: %v", attempt, retry.Attempts, step.UUID, runError)
+
+		retryingEvent := stepEvent(event.StepRetrying, step)
+		retryingEvent.Attempt = attempt + 1
+		retryingEvent.Error = event.ErrorMessage(runError)
+		retryingEvent.ExitCode = failedExitCode
+		step.Pipeline.Notify(retryingEvent)
+
+		if err := refactoringpipelinecleanup.DiscardAttempt(step); err != nil {
+			chastlog.Log.Errorf("Failed to discard failed attempt of step %s: %v", step.UUID, err)
+
+			return runError
+		}
+
+		if err := waitForBackoff(ctx, retry.Backoff); err != nil {
+			return errorx.Interrupted.Wrap(err, "Pipeline was cancelled before retrying step %s", step.UUID)
+		}
+	}
+}
+
+// waitForBackoff waits for the backoff unless the context is done before.
+func waitForBackoff(ctx context.Context, backoff time.Duration) error {
+	if backoff <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // wrapped by the caller
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	changeisolator "chast.io/core/internal/changeisolator/pkg"
+	"chast.io/core/internal/internal_util/glob"
+	refactoringPipelineModel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+)
+
+// runInScratchCopy runs the commands of the step without isolation in a scratch copy of the project root, which
+// contains the changes of its dependencies, and stops them once the timeout of the step elapsed.
+func runInScratchCopy(
+	ctx context.Context,
+	step *refactoringPipelineModel.Step,
+	outputPrefix string,
+) error {
+	// the copy is prepared from the flattened changes of the dependencies instead of their change capture folders
+	nsContext, contextError := buildNamespaceContext(
+		step,
+		scratchCopySource(step),
+		[]string{step.GetMergedPreviousChangesLocation()},
+		outputPrefix,
+	)
+	if contextError != nil {
+		return contextError
+	}
+
+	return runCommands(ctx, step, nsContext, changeisolator.RunCommandInScratchCopy)
+}
+
+// scratchCopySource is the folder copied for the step. Within the project root only the common folder of the change
+// locations of the step and its working directory is copied, as the step changes nothing else. Without change
+// locations the project root is copied, or the working directory if the project root is unknown.
+func scratchCopySource(step *refactoringPipelineModel.Step) string {
+	workingDirectory := step.RunModel.Run.Command.WorkingDirectory
+
+	projectRoot := step.RunModel.Run.Environment[runmodel.ProjectRootVariable]
+	if projectRoot == "" {
+		return workingDirectory
+	}
+
+	roots, bounded := changeLocationRoots(step)
+	if !bounded {
+		return projectRoot
+	}
+
+	// relative paths of the commands have to point into the copy
+	if isWithinFolder(workingDirectory, projectRoot) {
+		roots = append(roots, workingDirectory)
+	}
+
+	source := existingFolder(commonFolder(roots))
+	if !isWithinFolder(source, projectRoot) {
+		return projectRoot
+	}
+
+	return source
+}
+
+// changeLocationRoots returns the static roots of the included change locations of the step. It returns false if the
+// step has none or if they cannot be bounded.
+func changeLocationRoots(step *refactoringPipelineModel.Step) ([]string, bool) {
+	changeLocations := step.ChangeFilteringLocations()
+	if changeLocations == nil || len(changeLocations.Include) == 0 {
+		return nil, false
+	}
+
+	roots := make([]string, 0, len(changeLocations.Include)+1)
+
+	for _, include := range changeLocations.Include {
+		root := glob.StaticRoot(include)
+		if !filepath.IsAbs(root) {
+			return nil, false
+		}
+
+		roots = append(roots, filepath.Clean(root))
+	}
+
+	return roots, true
+}
+
+func commonFolder(paths []string) string {
+	common := paths[0]
+
+	for _, path := range paths[1:] {
+		for !isWithinFolder(path, common) {
+			common = filepath.Dir(common)
+		}
+	}
+
+	return common
+}
+
+func isWithinFolder(path string, folder string) bool {
+	relativePath, relError := filepath.Rel(folder, path)
+
+	return relError == nil && relativePath != ".." && !strings.HasPrefix(relativePath, "../")
+}
+
+// existingFolder returns the path if it is an existing folder, otherwise its closest existing parent folder. Change
+// locations may name single files or folders which are created by the step.
+func existingFolder(path string) string {
+	for {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+
+		path = parent
+	}
+}
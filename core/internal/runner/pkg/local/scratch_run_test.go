@@ -0,0 +1,123 @@
+package local_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+	uut "chast.io/core/internal/runner/pkg/local"
+)
+
+func TestScratchCopySource(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+	projectRoot := filepath.Join(baseDir, "project")
+	runFolder := filepath.Join(baseDir, "recipe", "run")
+
+	for _, folder := range []string{"src/main", "src/test", "docs"} {
+		if err := os.MkdirAll(filepath.Join(projectRoot, folder), 0o755); err != nil {
+			t.Fatalf("Error creating folder: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(projectRoot, "src/main/Main.java"), []byte("class Main {}"), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		projectRoot      string
+		workingDirectory string
+		includes         []string
+		expected         string
+	}{
+		{
+			name:             "should copy the project without change locations",
+			projectRoot:      projectRoot,
+			workingDirectory: runFolder,
+			includes:         nil,
+			expected:         projectRoot,
+		},
+		{
+			name:             "should copy the root of the change location",
+			projectRoot:      projectRoot,
+			workingDirectory: runFolder,
+			includes:         []string{projectRoot + "/src/main/**/*.java"},
+			expected:         filepath.Join(projectRoot, "src/main"),
+		},
+		{
+			name:             "should copy the common folder of the change locations",
+			projectRoot:      projectRoot,
+			workingDirectory: runFolder,
+			includes:         []string{projectRoot + "/src/main/**", projectRoot + "/src/test/**"},
+			expected:         filepath.Join(projectRoot, "src"),
+		},
+		{
+			name:             "should copy the working directory within the project",
+			projectRoot:      projectRoot,
+			workingDirectory: filepath.Join(projectRoot, "docs"),
+			includes:         []string{projectRoot + "/src/main/**"},
+			expected:         projectRoot,
+		},
+		{
+			name:             "should copy the folder of a single file",
+			projectRoot:      projectRoot,
+			workingDirectory: runFolder,
+			includes:         []string{projectRoot + "/src/main/Main.java"},
+			expected:         filepath.Join(projectRoot, "src/main"),
+		},
+		{
+			name:             "should copy the existing parent of a created folder",
+			projectRoot:      projectRoot,
+			workingDirectory: runFolder,
+			includes:         []string{projectRoot + "/src/generated/**"},
+			expected:         filepath.Join(projectRoot, "src"),
+		},
+		{
+			name:             "should copy the project if the change locations are outside of it",
+			projectRoot:      projectRoot,
+			workingDirectory: runFolder,
+			includes:         []string{baseDir + "/other/**"},
+			expected:         projectRoot,
+		},
+		{
+			name:             "should copy the project if the change locations cannot be bounded",
+			projectRoot:      projectRoot,
+			workingDirectory: runFolder,
+			includes:         []string{"**/*.java"},
+			expected:         projectRoot,
+		},
+		{
+			name:             "should copy the working directory without project root",
+			projectRoot:      "",
+			workingDirectory: runFolder,
+			includes:         []string{projectRoot + "/src/main/**"},
+			expected:         runFolder,
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			command := &refactoring.Command{WorkingDirectory: testCase.workingDirectory} //nolint:exhaustruct // not required
+			step := refactoringpipelinemodel.NewStep(&refactoring.SingleRunModel{
+				Run: &refactoring.Run{ //nolint:exhaustruct // not required for test
+					ID:              "format",
+					Command:         command,
+					ChangeLocations: &refactoring.ChangeLocations{Include: testCase.includes}, //nolint:exhaustruct // include only
+					Environment:     map[string]string{runmodel.ProjectRootVariable: testCase.projectRoot},
+				},
+			})
+
+			if source := uut.ScratchCopySource(step); source != testCase.expected {
+				t.Errorf("Expected source to be '%s', but was '%s'", testCase.expected, source)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package local
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	refactoringPipelineModel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"chast.io/core/internal/post_processing/merger/pkg/mergeoptions"
+	runmodel "chast.io/core/internal/run_model/pkg/model"
+	"github.com/joomcode/errorx"
+)
+
+// buildStepEnvironment returns the built-in variables of the run together with the ones only known when the step
+// runs as sorted "KEY=value" entries.
+func buildStepEnvironment(step *refactoringPipelineModel.Step) ([]string, error) {
+	changedFiles, changedFilesError := changedFilesOfDependencies(step)
+	if changedFilesError != nil {
+		return nil, changedFilesError
+	}
+
+	variables := make(map[string]string)
+	for name, value := range step.RunModel.Run.Environment {
+		variables[name] = value
+	}
+
+	variables[runmodel.StepUUIDVariable] = step.UUID
+	variables[runmodel.ChangedFilesVariable] = strings.Join(changedFiles, " ")
+
+	environment := make([]string, 0, len(variables))
+	for name, value := range variables {
+		environment = append(environment, name+"="+value)
+	}
+
+	sort.Strings(environment)
+
+	return environment, nil
+}
+
+// changedFilesOfDependencies lists the original paths of the files the dependencies published to the step,
+// including the files they deleted.
+func changedFilesOfDependencies(step *refactoringPipelineModel.Step) ([]string, error) {
+	previousChangesLocation := step.GetMergedPreviousChangesLocation()
+	deletedExtension := mergeoptions.NewMergeOptions().MetaFilesDeletedExtension
+	changedFiles := make([]string, 0)
+
+	if walkError := filepath.WalkDir(previousChangesLocation, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		originalPath := "/" + strings.TrimPrefix(path, previousChangesLocation+string(filepath.Separator))
+		changedFiles = append(changedFiles, strings.TrimSuffix(originalPath, deletedExtension))
+
+		return nil
+	}); walkError != nil {
+		return nil, errorx.ExternalError.Wrap(walkError, "Failed to list changed files of dependencies")
+	}
+
+	return changedFiles, nil
+}
@@ -0,0 +1,100 @@
+package local
+
+import (
+	"errors"
+	"os/exec"
+	"time"
+
+	"chast.io/core/internal/changeisolator/pkg/namespace"
+	"chast.io/core/internal/pipeline/pkg/event"
+	"chast.io/core/internal/pipeline/pkg/manifest"
+	refactoringPipelineModel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+func stepEvent(eventType event.Type, step *refactoringPipelineModel.Step) event.Event {
+	return event.Event{ //nolint:exhaustruct // the remaining fields depend on the event type
+		Type:     eventType,
+		StepUUID: step.UUID,
+		RunID:    step.RunModel.Run.ID,
+	}
+}
+
+func notifyQueuedSteps(pipeline *refactoringPipelineModel.Pipeline, state *manifest.Manifest) {
+	for _, stage := range pipeline.ExecutionGroups {
+		for _, step := range stage.Steps {
+			if !state.IsCompleted(step.UUID) {
+				pipeline.Notify(stepEvent(event.StepQueued, step))
+			}
+		}
+	}
+}
+
+func notifyStepFinished(
+	step *refactoringPipelineModel.Step,
+	status event.Status,
+	duration time.Duration,
+	err error,
+) {
+	stepFinishedEvent := stepEvent(event.StepFinished, step)
+	stepFinishedEvent.Status = status
+	stepFinishedEvent.DurationMillis = duration.Milliseconds()
+	stepFinishedEvent.Error = event.ErrorMessage(err)
+	stepFinishedEvent.ExitCode = exitCode(err)
+	stepFinishedEvent.Timings = step.Timings
+	stepFinishedEvent.ResourceUsage = step.ResourceUsage
+
+	// the output of cached steps was logged by the run their changes were cached in
+	if status != event.Cached {
+		stepFinishedEvent.LogFile = step.GetLogFile()
+	}
+
+	step.Pipeline.Notify(stepFinishedEvent)
+}
+
+// eventStatus returns event.Failed if there is an error, the status of success otherwise.
+func eventStatus(err error, success event.Status) event.Status {
+	if err != nil {
+		return event.Failed
+	}
+
+	return success
+}
+
+// finishedStep returns the status of a step which finished with the error.
+func finishedStep(err error, success event.Status) (event.Status, error) {
+	return eventStatus(err, success), err
+}
+
+// exitCode returns the exit code of the command or process which caused the error, 0 without error and nil if it is unknown.
+func exitCode(err error) *int {
+	if err == nil {
+		code := 0
+
+		return &code
+	}
+
+	for err != nil {
+		var commandError *namespace.CommandError
+		if errors.As(err, &commandError) {
+			code := commandError.Result.ExitCode
+
+			return &code
+		}
+
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			code := exitError.ExitCode()
+
+			return &code
+		}
+
+		if cause := errorx.Cast(err); cause != nil {
+			err = cause.Cause()
+		} else {
+			err = errors.Unwrap(err)
+		}
+	}
+
+	return nil
+}
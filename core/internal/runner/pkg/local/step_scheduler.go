@@ -0,0 +1,200 @@
+package local
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	chastlog "chast.io/core/internal/logger"
+	"chast.io/core/internal/pipeline/pkg/event"
+	"chast.io/core/internal/pipeline/pkg/manifest"
+	refactoringPipelineModel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	stepcache "chast.io/core/internal/pipeline/pkg/step_cache"
+	refactoringpipelinecleanup "chast.io/core/internal/post_processing/cleanup/pkg/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+// stepScheduler runs the steps of an execution group in a pool of workers and records their progress in the
+// manifest. With a single job the steps run sequentially in their order.
+type stepScheduler struct {
+	state     *manifest.Manifest
+	stepCache *stepcache.Cache
+	jobs      int
+	commands  stepCommands
+	// stateLock serializes the access to the manifest of the steps running at the same time.
+	stateLock sync.Mutex
+	// failed is set once a step failed, no further steps are started then.
+	failed bool
+}
+
+func newStepScheduler(
+	state *manifest.Manifest,
+	stepCache *stepcache.Cache,
+	jobs int,
+	commands stepCommands,
+) *stepScheduler {
+	return &stepScheduler{
+		state:     state,
+		stepCache: stepCache,
+		jobs:      jobs,
+		commands:  commands,
+		stateLock: sync.Mutex{},
+		failed:    false,
+	}
+}
+
+// runExecutionGroup runs the pending steps of the group and waits for all of them. It returns the error of the first
+// failed step of the group. No further steps are started once a step failed or the context is done.
+func (scheduler *stepScheduler) runExecutionGroup(
+	ctx context.Context,
+	executionGroup *refactoringPipelineModel.ExecutionGroup,
+) error {
+	workers := make(chan struct{}, scheduler.jobs)
+	stepErrors := make([]error, len(executionGroup.Steps))
+
+	var waitGroup sync.WaitGroup
+
+	for index, step := range executionGroup.Steps {
+		if scheduler.isCompleted(step) {
+			chastlog.Log.Printf("Skipping completed step %s", step.UUID)
+
+			continue
+		}
+
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil || scheduler.hasFailed() {
+			break
+		}
+
+		waitGroup.Add(1)
+
+		go func(index int, step *refactoringPipelineModel.Step) {
+			defer waitGroup.Done()
+			defer func() { <-workers }()
+
+			stepErrors[index] = scheduler.runAndRecordStep(ctx, step)
+		}(index, step)
+	}
+
+	waitGroup.Wait()
+
+	for _, stepError := range stepErrors {
+		if stepError != nil {
+			return stepError
+		}
+	}
+
+	if ctx.Err() != nil {
+		return recordFailure(scheduler.state, errorx.Interrupted.Wrap(ctx.Err(), "Pipeline was cancelled"))
+	}
+
+	return nil
+}
+
+func (scheduler *stepScheduler) runAndRecordStep(ctx context.Context, step *refactoringPipelineModel.Step) error {
+	started, startError := scheduler.recordStepStarted(step)
+	if startError != nil || !started {
+		return startError
+	}
+
+	step.Pipeline.Notify(stepEvent(event.StepStarted, step))
+	startTime := time.Now()
+
+	status, stepError := runStep(ctx, step, scheduler.state, scheduler.stepCache, scheduler.commandsOf(step))
+	notifyStepFinished(step, status, time.Since(startTime), stepError)
+
+	if stepError != nil {
+		// the captured changes are kept for inspection until the step is reset by resuming the pipeline
+		if err := refactoringpipelinecleanup.CleanupStep(step); err != nil {
+			chastlog.Log.Errorf("Failed to clean up step %s: %v", step.UUID, err)
+		}
+
+		return scheduler.recordStepFailed(step, errorx.InternalError.Wrap(stepError, "Error running step"))
+	}
+
+	return scheduler.recordStepCompleted(step)
+}
+
+// commandsOf returns the function running the commands of the step.
+func (scheduler *stepScheduler) commandsOf(step *refactoringPipelineModel.Step) func(ctx context.Context) error {
+	outputPrefix := scheduler.outputPrefix(step)
+
+	return func(ctx context.Context) error { return scheduler.commands(ctx, step, outputPrefix) }
+}
+
+// outputPrefix tells the output of the steps apart if several of them run at the same time.
+func (scheduler *stepScheduler) outputPrefix(step *refactoringPipelineModel.Step) string {
+	if scheduler.jobs == 1 {
+		return ""
+	}
+
+	return "[" + step.RunModel.Run.ID + "] "
+}
+
+func (scheduler *stepScheduler) isCompleted(step *refactoringPipelineModel.Step) bool {
+	scheduler.stateLock.Lock()
+	defer scheduler.stateLock.Unlock()
+
+	return scheduler.state.IsCompleted(step.UUID)
+}
+
+func (scheduler *stepScheduler) hasFailed() bool {
+	scheduler.stateLock.Lock()
+	defer scheduler.stateLock.Unlock()
+
+	return scheduler.failed
+}
+
+// recordStepStarted records the step as running. It returns false if another step failed in the meantime, the step
+// must not start then.
+func (scheduler *stepScheduler) recordStepStarted(step *refactoringPipelineModel.Step) (bool, error) {
+	scheduler.stateLock.Lock()
+	defer scheduler.stateLock.Unlock()
+
+	if scheduler.failed {
+		chastlog.Log.Debugf("Not starting step %s after another step failed", step.UUID)
+
+		return false, nil
+	}
+
+	chastlog.Log.Printf("Running step %s", step.UUID)
+
+	scheduler.state.StepStarted(step.UUID)
+
+	if err := scheduler.state.Save(); err != nil {
+		scheduler.failed = true
+
+		return false, errorx.InternalError.Wrap(err, "Error saving pipeline state")
+	}
+
+	return true, nil
+}
+
+func (scheduler *stepScheduler) recordStepFailed(step *refactoringPipelineModel.Step, runError error) error {
+	scheduler.stateLock.Lock()
+	defer scheduler.stateLock.Unlock()
+
+	scheduler.failed = true
+	scheduler.state.StepFailed(step.UUID, runError)
+
+	return saveState(scheduler.state, runError)
+}
+
+func (scheduler *stepScheduler) recordStepCompleted(step *refactoringPipelineModel.Step) error {
+	scheduler.stateLock.Lock()
+	defer scheduler.stateLock.Unlock()
+
+	scheduler.state.StepCompleted(step.UUID)
+
+	if err := scheduler.state.Save(); err != nil {
+		scheduler.failed = true
+
+		return errorx.InternalError.Wrap(err, "Error saving pipeline state")
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package local_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"chast.io/core/internal/pipeline/pkg/manifest"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"chast.io/core/internal/run_model/pkg/model/refactoring"
+	uut "chast.io/core/internal/runner/pkg/local"
+	"github.com/joomcode/errorx"
+)
+
+// dummyPipeline returns a pipeline with a group of independent steps and a final step depending on all of them.
+func dummyPipeline(t *testing.T, independentSteps int) (*refactoringpipelinemodel.Pipeline, *manifest.Manifest) {
+	t.Helper()
+
+	baseDir := t.TempDir()
+	pipeline := refactoringpipelinemodel.NewPipeline(
+		filepath.Join(baseDir, "operation"),
+		filepath.Join(baseDir, "changes"),
+		"/",
+	)
+
+	independentGroup := refactoringpipelinemodel.NewExecutionGroup()
+	finalStep := dummyStep("final")
+
+	for index := 0; index < independentSteps; index++ {
+		step := dummyStep(fmt.Sprintf("step%d", index))
+		finalStep.AddDependency(step)
+		independentGroup.AddStep(step)
+	}
+
+	finalGroup := refactoringpipelinemodel.NewExecutionGroup()
+	finalGroup.AddStep(finalStep)
+
+	pipeline.AddExecutionGroup(independentGroup)
+	pipeline.AddExecutionGroup(finalGroup)
+
+	return pipeline, manifest.New(pipeline, nil)
+}
+
+func dummyStep(runID string) *refactoringpipelinemodel.Step {
+	return refactoringpipelinemodel.NewStep(&refactoring.SingleRunModel{
+		Run: &refactoring.Run{ //nolint:exhaustruct // not required for test
+			ID:      runID,
+			Command: &refactoring.Command{Cmds: [][]string{{runID}}}, //nolint:exhaustruct // not required for test
+		},
+	})
+}
+
+// stubCommands records the steps whose commands ran and how many of them ran at the same time.
+type stubCommands struct {
+	lock       sync.Mutex
+	started    []string
+	running    int
+	maxRunning int
+	duration   time.Duration
+	// failingRunID is the id of the run whose commands fail.
+	failingRunID string
+}
+
+func (stub *stubCommands) run(_ context.Context, step *refactoringpipelinemodel.Step, _ string) error {
+	stub.lock.Lock()
+	stub.started = append(stub.started, step.RunModel.Run.ID)
+	stub.running++
+
+	if stub.running > stub.maxRunning {
+		stub.maxRunning = stub.running
+	}
+	stub.lock.Unlock()
+
+	defer func() {
+		stub.lock.Lock()
+		stub.running--
+		stub.lock.Unlock()
+	}()
+
+	if step.RunModel.Run.ID == stub.failingRunID {
+		return errorx.ExternalError.New("Command of %s failed", step.RunModel.Run.ID)
+	}
+
+	time.Sleep(stub.duration)
+
+	return nil
+}
+
+func readManifest(t *testing.T, pipeline *refactoringpipelinemodel.Pipeline) *manifest.Manifest {
+	t.Helper()
+
+	state, readError := manifest.Read(filepath.Dir(pipeline.ChangeCaptureLocation), pipeline.UUID)
+	if readError != nil {
+		t.Fatalf("Expected no error, but was '%v'", readError)
+	}
+
+	return state
+}
+
+func TestRunner_Jobs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		jobs               int
+		expectedMaxRunning int
+	}{
+		{name: "should run steps sequentially with a single job", jobs: 1, expectedMaxRunning: 1},
+		{name: "should run up to jobs steps at the same time", jobs: 3, expectedMaxRunning: 3},
+		{name: "should run at least one step", jobs: 0, expectedMaxRunning: 1},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline, state := dummyPipeline(t, 6)
+			stub := &stubCommands{duration: 50 * time.Millisecond} //nolint:exhaustruct // recorded while running
+
+			runner := uut.NewRunnerWithCommands(testCase.jobs, stub.run)
+			if err := runner.Run(context.Background(), pipeline, state); err != nil {
+				t.Fatalf("Expected no error, but was '%v'", err)
+			}
+
+			if stub.maxRunning != testCase.expectedMaxRunning {
+				t.Errorf("Expected %d steps running at the same time, but was %d",
+					testCase.expectedMaxRunning, stub.maxRunning)
+			}
+
+			if len(stub.started) != 7 || stub.started[6] != "final" {
+				t.Errorf("Expected 7 steps to run, the final step last, but was '%v'", stub.started)
+			}
+
+			savedState := readManifest(t, pipeline)
+
+			if savedState.Status != manifest.Completed {
+				t.Errorf("Expected pipeline to be completed, but was '%s'", savedState.Status)
+			}
+
+			for _, step := range savedState.Steps {
+				if step.Status != manifest.Completed {
+					t.Errorf("Expected step %s to be completed, but was '%s'", step.RunID, step.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_FailFast(t *testing.T) {
+	t.Parallel()
+
+	pipeline, state := dummyPipeline(t, 6)
+	stub := &stubCommands{ //nolint:exhaustruct // recorded while running
+		duration:     100 * time.Millisecond,
+		failingRunID: "step0",
+	}
+
+	if err := uut.NewRunnerWithCommands(2, stub.run).Run(context.Background(), pipeline, state); err == nil {
+		t.Fatal("Expected error, but was nil")
+	}
+
+	// only the second step may start together with the failing one, no further step starts after the failure
+	for _, runID := range stub.started {
+		if runID != "step0" && runID != "step1" {
+			t.Errorf("Expected no step to start after the failure, but was '%v'", stub.started)
+		}
+	}
+
+	savedState := readManifest(t, pipeline)
+
+	if savedState.Status != manifest.Failed {
+		t.Errorf("Expected pipeline to be failed, but was '%s'", savedState.Status)
+	}
+
+	expectedStatuses := map[string]manifest.Status{
+		"step0": manifest.Failed,
+		"step2": manifest.Pending,
+		"final": manifest.Pending,
+	}
+
+	for _, step := range savedState.Steps {
+		if expectedStatus, isChecked := expectedStatuses[step.RunID]; isChecked && step.Status != expectedStatus {
+			t.Errorf("Expected step %s to be '%s', but was '%s'", step.RunID, expectedStatus, step.Status)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package refactoringservice
+
+import (
+	"time"
+
+	stepcache "chast.io/core/internal/pipeline/pkg/step_cache"
+	"chast.io/core/internal/pipeline/pkg/workspace"
+	"github.com/joomcode/errorx"
+)
+
+func CacheStats(locations *workspace.Locations) (*stepcache.Stats, error) {
+	stats, statsError := stepcache.NewCache(locations.CacheLocation, 0).Stats()
+	if statsError != nil {
+		return nil, errorx.Decorate(statsError, "Failed to get cache stats")
+	}
+
+	return stats, nil
+}
+
+// PruneCache removes the least recently used steps from the cache until it is at most maxSize bytes large, and all
+// steps not used for longer than maxAge. It returns the number of removed steps.
+func PruneCache(locations *workspace.Locations, maxSize int64, maxAge time.Duration) (int, error) {
+	removedEntries, pruneError := stepcache.NewCache(locations.CacheLocation, 0).Prune(maxSize, maxAge)
+	if pruneError != nil {
+		return removedEntries, errorx.Decorate(pruneError, "Failed to prune cache")
+	}
+
+	return removedEntries, nil
+}
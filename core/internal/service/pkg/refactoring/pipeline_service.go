@@ -0,0 +1,196 @@
+package refactoringservice
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	chastlog "chast.io/core/internal/logger"
+	refactoringPipelineBuilder "chast.io/core/internal/pipeline/pkg/builder/refactoring"
+	"chast.io/core/internal/pipeline/pkg/manifest"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"chast.io/core/internal/pipeline/pkg/workspace"
+	"github.com/joomcode/errorx"
+)
+
+// PipelineInfo describes a pipeline left in the workspace.
+type PipelineInfo struct {
+	Manifest *manifest.Manifest
+	// InUse is set if another invocation currently runs the pipeline.
+	InUse bool
+}
+
+// Resume reruns the failed steps of the pipeline and all steps which did not run yet, reusing the changes of the
+// completed steps.
+func Resume(ctx context.Context, pipelineUUID string, options *RunOptions) (*refactoringpipelinemodel.Pipeline, error) {
+	pipelineUUID, uuidError := normalizePipelineUUID(pipelineUUID)
+	if uuidError != nil {
+		return nil, uuidError
+	}
+
+	state, readError := manifest.Read(options.Locations.ChangeCaptureLocation, pipelineUUID)
+	if readError != nil {
+		return nil, errorx.Decorate(readError, "Failed to read pipeline state")
+	}
+
+	if state.Plan == nil {
+		return nil, errorx.IllegalFormat.New("Pipeline %s has no plan to resume", state.PipelineUUID)
+	}
+
+	if state.Status == manifest.Completed {
+		return nil, errorx.IllegalState.New("Pipeline %s is already completed", state.PipelineUUID)
+	}
+
+	if err := state.Plan.Verify(); err != nil {
+		return nil, errorx.Decorate(err, "Refusing to resume outdated pipeline")
+	}
+
+	runModel, runModelRestoreError := state.Plan.RestoreRunModel()
+	if runModelRestoreError != nil {
+		return nil, errorx.InternalError.Wrap(runModelRestoreError, "Failed to restore run model of pipeline")
+	}
+
+	pipelineLocations := state.Locations()
+	pipelineLocations.CacheLocation = options.Locations.CacheLocation
+	pipelineLocations.MinimumFreeSpace = options.Locations.MinimumFreeSpace
+
+	if err := pipelineLocations.CheckFreeSpace(); err != nil {
+		return nil, errorx.Decorate(err, "Workspace is not usable")
+	}
+
+	pipeline, pipelineBuildError := refactoringPipelineBuilder.RestoreRunPipeline(
+		runModel,
+		pipelineLocations,
+		state.PipelineUUID,
+	)
+	if pipelineBuildError != nil {
+		return nil, errorx.InternalError.Wrap(pipelineBuildError, "Failed to build pipeline")
+	}
+
+	pipeline.Partial = state.Plan.Partial
+
+	chastlog.Log.Printf("Resuming pipeline %s with %d of %d completed steps",
+		state.PipelineUUID, state.CompletedSteps(), len(state.Steps))
+
+	return executePipeline(ctx, pipeline, state, options)
+}
+
+// ListPipelines returns all pipelines left in the workspace.
+func ListPipelines(locations *workspace.Locations) ([]PipelineInfo, error) {
+	manifests, listError := manifest.List(locations.ChangeCaptureLocation)
+	if listError != nil {
+		return nil, errorx.Decorate(listError, "Failed to list pipelines")
+	}
+
+	pipelines := make([]PipelineInfo, 0, len(manifests))
+
+	for _, state := range manifests {
+		inUse, lockCheckError := workspace.IsLocked(
+			refactoringpipelinemodel.LockFile(locations.ChangeCaptureLocation, state.PipelineUUID),
+		)
+		if lockCheckError != nil {
+			return nil, errorx.Decorate(lockCheckError, "Failed to check if pipeline %s is in use", state.PipelineUUID)
+		}
+
+		pipelines = append(pipelines, PipelineInfo{Manifest: state, InUse: inUse})
+	}
+
+	return pipelines, nil
+}
+
+// RemovePipeline removes the changes and operation folders of the pipeline unless another invocation runs it.
+func RemovePipeline(pipelineUUID string, locations *workspace.Locations) error {
+	pipelineUUID, uuidError := normalizePipelineUUID(pipelineUUID)
+	if uuidError != nil {
+		return uuidError
+	}
+
+	pipelineLocation := filepath.Join(locations.ChangeCaptureLocation, pipelineUUID)
+
+	if _, err := os.Stat(pipelineLocation); err != nil {
+		return errorx.DataUnavailable.New("No pipeline %s in %s", pipelineUUID, locations.ChangeCaptureLocation)
+	}
+
+	lock, lockError := workspace.AcquireLock(refactoringpipelinemodel.LockFile(locations.ChangeCaptureLocation, pipelineUUID))
+	if lockError != nil {
+		return errorx.Decorate(lockError, "Failed to lock pipeline %s", pipelineUUID)
+	}
+
+	defer func() {
+		if err := lock.Release(); err != nil {
+			chastlog.Log.Errorf("Failed to release lock of pipeline %s: %v", pipelineUUID, err)
+		}
+	}()
+
+	if state, readError := manifest.Read(locations.ChangeCaptureLocation, pipelineUUID); readError == nil {
+		for _, step := range state.Steps {
+			if err := os.RemoveAll(filepath.Join(state.OperationLocation, step.UUID)); err != nil {
+				return errorx.ExternalError.Wrap(err, "Failed to remove operation folder of step %s", step.UUID)
+			}
+		}
+	}
+
+	if err := os.RemoveAll(pipelineLocation); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to remove pipeline %s", pipelineUUID)
+	}
+
+	return nil
+}
+
+// StepLogs returns the steps of the pipeline which logged the output of their commands, only the steps with the uuid
+// or run id if a step is given.
+func StepLogs(pipelineUUID string, step string, locations *workspace.Locations) ([]*manifest.StepState, error) {
+	pipelineUUID, uuidError := normalizePipelineUUID(pipelineUUID)
+	if uuidError != nil {
+		return nil, uuidError
+	}
+
+	state, readError := manifest.Read(locations.ChangeCaptureLocation, pipelineUUID)
+	if readError != nil {
+		return nil, errorx.Decorate(readError, "Failed to read pipeline state")
+	}
+
+	steps := make([]*manifest.StepState, 0)
+
+	for _, stepState := range state.Steps {
+		if step != "" && stepState.UUID != step && stepState.RunID != step {
+			continue
+		}
+
+		if stepState.LogFile == "" {
+			continue
+		}
+
+		if _, err := os.Stat(stepState.LogFile); err != nil {
+			continue
+		}
+
+		steps = append(steps, stepState)
+	}
+
+	if len(steps) == 0 {
+		if step != "" {
+			return nil, errorx.DataUnavailable.New("No logs of step %s in pipeline %s", step, state.PipelineUUID)
+		}
+
+		return nil, errorx.DataUnavailable.New("No logs in pipeline %s", state.PipelineUUID)
+	}
+
+	return steps, nil
+}
+
+// normalizePipelineUUID adds the prefix to the uuid if it is missing. It fails if the uuid is not a single path
+// element, it must not point outside of the pipeline folder.
+func normalizePipelineUUID(pipelineUUID string) (string, error) {
+	if pipelineUUID == "" || pipelineUUID == "." || strings.Contains(pipelineUUID, "..") ||
+		strings.ContainsRune(pipelineUUID, '/') || strings.ContainsRune(pipelineUUID, filepath.Separator) {
+		return "", errorx.IllegalArgument.New("Invalid pipeline uuid \"%s\"", pipelineUUID)
+	}
+
+	if strings.HasPrefix(pipelineUUID, refactoringpipelinemodel.UUIDPrefix) {
+		return pipelineUUID, nil
+	}
+
+	return refactoringpipelinemodel.UUIDPrefix + pipelineUUID, nil
+}
@@ -0,0 +1,62 @@
+package refactoringservice_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chast.io/core/internal/pipeline/pkg/workspace"
+	uut "chast.io/core/internal/service/pkg/refactoring"
+)
+
+func TestPipelineCommands_RejectInvalidUUID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		pipelineUUID string
+	}{
+		{name: "should reject parent folder", pipelineUUID: "x/../.."},
+		{name: "should reject parent folder with prefix", pipelineUUID: "PIPELINE-/../.."},
+		{name: "should reject nested folder", pipelineUUID: "PIPELINE-1/logs"},
+		{name: "should reject dots", pipelineUUID: ".."},
+		{name: "should reject empty uuid", pipelineUUID: ""},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			baseLocation := t.TempDir()
+			locations := workspace.NewLocations(baseLocation)
+			keptFile := filepath.Join(baseLocation, "kept")
+
+			if err := os.MkdirAll(locations.ChangeCaptureLocation, 0o755); err != nil {
+				t.Fatalf("Error creating change capture location: %v", err)
+			}
+
+			if err := os.WriteFile(keptFile, []byte("kept"), 0o600); err != nil {
+				t.Fatalf("Error writing file: %v", err)
+			}
+
+			if err := uut.RemovePipeline(testCase.pipelineUUID, locations); err == nil {
+				t.Error("Expected remove to fail, but was nil")
+			}
+
+			if _, err := uut.StepLogs(testCase.pipelineUUID, "", locations); err == nil {
+				t.Error("Expected logs to fail, but was nil")
+			}
+
+			options := &uut.RunOptions{Locations: locations} //nolint:exhaustruct // only the locations are used
+			if _, err := uut.Resume(context.Background(), testCase.pipelineUUID, options); err == nil {
+				t.Error("Expected resume to fail, but was nil")
+			}
+
+			if _, err := os.Stat(keptFile); err != nil {
+				t.Errorf("Expected file outside of the pipelines to be kept, but was '%v'", err)
+			}
+		})
+	}
+}
@@ -1,9 +1,19 @@
 package refactoringservice
 
 import (
+	"context"
+
 	"chast.io/core/internal/internal_util/collection"
+	chastlog "chast.io/core/internal/logger"
 	refactoringPipelineBuilder "chast.io/core/internal/pipeline/pkg/builder/refactoring"
+	"chast.io/core/internal/pipeline/pkg/event"
+	"chast.io/core/internal/pipeline/pkg/manifest"
+	"chast.io/core/internal/pipeline/pkg/metrics"
 	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	runselection "chast.io/core/internal/pipeline/pkg/run_selection"
+	stepcache "chast.io/core/internal/pipeline/pkg/step_cache"
+	"chast.io/core/internal/pipeline/pkg/workspace"
+	refactoringplan "chast.io/core/internal/plan/pkg/refactoring"
 	"chast.io/core/internal/post_processing/merger/pkg/dirmerger"
 	"chast.io/core/internal/post_processing/merger/pkg/mergeoptions"
 	"chast.io/core/internal/post_processing/pipelinereport"
@@ -21,14 +31,104 @@ type FlagParameter struct {
 	Value string
 }
 
+// RunOptions configures how pipelines run.
+type RunOptions struct {
+	Locations *workspace.Locations
+	// UseCache reuses the cached changes of steps whose inputs did not change since they ran before.
+	UseCache bool
+	// Observers are notified about the progress of the pipeline.
+	Observers []event.Observer
+	// Selection prunes the runs of the pipeline.
+	Selection *runselection.Selection
+	// Jobs is the maximum number of independent steps running at the same time.
+	Jobs int
+	// Isolated runs the commands in an isolated environment, otherwise they run in a scratch copy of the project.
+	Isolated bool
+}
+
+func NewRunOptions() *RunOptions {
+	return &RunOptions{
+		Locations: workspace.NewLocations(""),
+		UseCache:  true,
+		Observers: make([]event.Observer, 0),
+		Selection: runselection.NewSelection(),
+		Jobs:      1,
+		Isolated:  true,
+	}
+}
+
 func Run(
+	ctx context.Context,
 	recipeFile *util.File,
 	args []string,
 	flags []FlagParameter,
+	options *RunOptions,
 ) (*refactoringpipelinemodel.Pipeline, error) {
+	runModel, runModelBuildError := buildRunModel(recipeFile, args, flags)
+	if runModelBuildError != nil {
+		return nil, runModelBuildError
+	}
+
+	return runPipeline(ctx, recipeFile.AbsolutePath, runModel, false, options)
+}
+
+// Plan resolves the recipe for the arguments and flags into a plan of the selected runs which can be executed later
+// using RunPlan.
+func Plan(
+	recipeFile *util.File,
+	args []string,
+	flags []FlagParameter,
+	selection *runselection.Selection,
+) (*refactoringplan.Plan, error) {
+	runModel, runModelBuildError := buildRunModel(recipeFile, args, flags)
+	if runModelBuildError != nil {
+		return nil, runModelBuildError
+	}
+
+	// the locations of a plan are never used, applying it builds a new pipeline in the workspace of the invocation
+	pipeline, pipelineBuildError := refactoringPipelineBuilder.BuildSelectedRunPipeline(
+		runModel,
+		workspace.NewLocations(""),
+		selection,
+	)
+	if pipelineBuildError != nil {
+		return nil, errorx.InternalError.Wrap(pipelineBuildError, "Failed to build pipeline")
+	}
+
+	plan, planBuildError := refactoringplan.NewPlan(recipeFile.AbsolutePath, runModel, pipeline)
+	if planBuildError != nil {
+		return nil, errorx.InternalError.Wrap(planBuildError, "Failed to build plan")
+	}
+
+	return plan, nil
+}
+
+// RunPlan runs the plan if neither the recipe nor the input changed since the plan was created.
+func RunPlan(
+	ctx context.Context,
+	plan *refactoringplan.Plan,
+	options *RunOptions,
+) (*refactoringpipelinemodel.Pipeline, error) {
+	if err := plan.Verify(); err != nil {
+		return nil, errorx.Decorate(err, "Refusing to run outdated plan")
+	}
+
+	runModel, runModelRestoreError := plan.ToRunModel()
+	if runModelRestoreError != nil {
+		return nil, errorx.InternalError.Wrap(runModelRestoreError, "Failed to restore run model from plan")
+	}
+
+	return runPipeline(ctx, plan.RecipeFile, runModel, plan.Partial, options)
+}
+
+func buildRunModel(
+	recipeFile *util.File,
+	args []string,
+	flags []FlagParameter,
+) (*refactoring.RunModel, error) {
 	parsedRecipe, recipeParseError := parser.ParseRecipe(recipeFile)
 	if recipeParseError != nil {
-		panic(recipeParseError)
+		return nil, errorx.InternalError.Wrap(recipeParseError, "Failed to parse recipe")
 	}
 
 	runModel, runModelBuildError := builder.BuildRunModel(parsedRecipe, args, mapFlags(flags), recipeFile.ParentDirectory)
@@ -36,36 +136,105 @@ func Run(
 		return nil, errorx.InternalError.Wrap(runModelBuildError, "Failed to build run model")
 	}
 
-	var pipeline *refactoringpipelinemodel.Pipeline
-
-	var pipelineBuildError error
-
 	switch m := (*runModel).(type) {
 	case refactoring.RunModel:
-		pipeline, pipelineBuildError = refactoringPipelineBuilder.BuildRunPipeline(&m)
+		return &m, nil
 	default:
 		return nil, errorx.InternalError.New("Provided recipe is not a refactoring recipe")
 	}
+}
+
+// runPipeline runs the selected runs of the run model. The pipeline is partial if the run model is partial already.
+func runPipeline(
+	ctx context.Context,
+	recipeFile string,
+	runModel *refactoring.RunModel,
+	partial bool,
+	options *RunOptions,
+) (*refactoringpipelinemodel.Pipeline, error) {
+	if err := options.Locations.CheckFreeSpace(); err != nil {
+		return nil, errorx.Decorate(err, "Workspace is not usable")
+	}
 
+	pipeline, pipelineBuildError := refactoringPipelineBuilder.BuildSelectedRunPipeline(
+		runModel,
+		options.Locations,
+		options.Selection,
+	)
 	if pipelineBuildError != nil {
 		return nil, errorx.InternalError.Wrap(pipelineBuildError, "Failed to build pipeline")
 	}
 
-	if err := local.NewRunner(true, false).Run(pipeline); err != nil {
-		return nil, errorx.InternalError.Wrap(err, "Failed to run pipeline")
+	pipeline.Partial = pipeline.Partial || partial
+
+	// the plan persists the inputs of the pipeline, so it can be resumed
+	plan, planBuildError := refactoringplan.NewPlan(recipeFile, runModel, pipeline)
+	if planBuildError != nil {
+		return nil, errorx.InternalError.Wrap(planBuildError, "Failed to build plan")
+	}
+
+	return executePipeline(ctx, pipeline, manifest.New(pipeline, plan), options)
+}
+
+func executePipeline(
+	ctx context.Context,
+	pipeline *refactoringpipelinemodel.Pipeline,
+	state *manifest.Manifest,
+	options *RunOptions,
+) (*refactoringpipelinemodel.Pipeline, error) {
+	// pipelines only use folders of their own within the workspace, so other pipelines can run at the same time
+	lock, lockError := workspace.AcquireLock(pipeline.GetLockFile())
+	if lockError != nil {
+		return nil, errorx.Decorate(lockError, "Failed to lock pipeline %s", pipeline.UUID)
+	}
+
+	for _, observer := range options.Observers {
+		pipeline.AddObserver(observer)
+	}
+
+	var stepCache *stepcache.Cache
+	if options.UseCache {
+		stepCache = stepcache.NewCache(options.Locations.CacheLocation, stepcache.DefaultMaxSize)
+	}
+
+	runError := local.NewRunner(options.Isolated, options.Jobs, stepCache).Run(ctx, pipeline, state)
+
+	if err := lock.Release(); err != nil {
+		chastlog.Log.Errorf("Failed to release lock of pipeline %s: %v", pipeline.UUID, err)
+	}
+
+	if runError != nil {
+		return nil, errorx.InternalError.Wrap(runError, "Failed to run pipeline %s, it can be resumed", pipeline.UUID)
 	}
 
 	return pipeline, nil
 }
 
-func ShowReport(pipeline *refactoringpipelinemodel.Pipeline) error {
+// ShowReport prints the report of the changes of the pipeline, including a summary of its timings if showTimings is
+// set.
+func ShowReport(pipeline *refactoringpipelinemodel.Pipeline, showTimings bool) error {
 	report, reportError := pipelinereport.BuildReport(pipeline)
 	if reportError != nil {
 		return errorx.InternalError.Wrap(reportError, "Failed to generate report")
 	}
 
+	pipeline.Notify(event.Event{ //nolint:exhaustruct // only the fields of the event type are set
+		Type:           event.ReportBuilt,
+		DurationMillis: report.DiffBuilding.Milliseconds(),
+		Timings:        []metrics.PhaseTiming{metrics.NewPhaseTiming(metrics.DiffBuilding, report.DiffBuilding)},
+	})
+
+	report.PrintClassificationStats()
+	report.PrintDependencyDecisions()
+	report.PrintFailedCommands()
+	report.PrintLogFiles()
 	report.PrintFileTree(true)
 	report.PrintChanges(true)
+	report.PrintConflicts()
+
+	if showTimings {
+		report.PrintTimings()
+	}
 
 	return nil
 }
@@ -94,6 +263,11 @@ func ApplyChanges(pipeline *refactoringpipelinemodel.Pipeline) error {
 		return errorx.InternalError.Wrap(err, "failed to remove marked as deleted paths")
 	}
 
+	pipeline.Notify(event.Event{ //nolint:exhaustruct // only the fields of the event type are set
+		Type:         event.ChangesApplied,
+		ChangedPaths: len(report.ChangedPaths),
+	})
+
 	return nil
 }
 
@@ -0,0 +1,107 @@
+package refactoringservice_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	"chast.io/core/internal/pipeline/pkg/workspace"
+	uut "chast.io/core/internal/service/pkg/refactoring"
+	util "chast.io/core/pkg/util/fs/file"
+)
+
+const slowRecipe = `version: 1
+type: refactoring
+name: Slow
+maintainer: chast
+
+primaryParameter:
+  id: inputFile
+  type: filePath
+  description: The file to be refactored.
+
+run:
+  - id: rewrite
+    script:
+      - sleep 0.5
+      - sed -i s/before/after/ $inputFile
+    includeChangeLocations:
+      - $inputFile
+`
+
+func TestRun_ConcurrentPipelines(t *testing.T) {
+	t.Parallel()
+
+	recipeFolder := t.TempDir()
+	recipePath := filepath.Join(recipeFolder, "slow.chast.yml")
+
+	// the commands run in the run folder of the recipe
+	if err := os.Mkdir(filepath.Join(recipeFolder, "run"), 0o755); err != nil {
+		t.Fatalf("Error creating run folder: %v", err)
+	}
+
+	if err := os.WriteFile(recipePath, []byte(slowRecipe), 0o600); err != nil {
+		t.Fatalf("Error writing recipe: %v", err)
+	}
+
+	recipeFile, recipeFileError := util.NewFile(recipePath)
+	if recipeFileError != nil {
+		t.Fatalf("Error loading recipe: %v", recipeFileError)
+	}
+
+	// both pipelines run in the same workspace
+	options := uut.NewRunOptions()
+	options.Locations = workspace.NewLocations(t.TempDir())
+	options.Locations.MinimumFreeSpace = 0
+	options.UseCache = false
+	options.Isolated = false
+
+	inputFiles := make([]string, 2)
+	pipelines := make([]*refactoringpipelinemodel.Pipeline, 2)
+	runErrors := make([]error, 2)
+
+	var waitGroup sync.WaitGroup
+
+	for index := range inputFiles {
+		inputFiles[index] = filepath.Join(t.TempDir(), "Input.java")
+		if err := os.WriteFile(inputFiles[index], []byte("before"), 0o600); err != nil {
+			t.Fatalf("Error writing input: %v", err)
+		}
+
+		waitGroup.Add(1)
+
+		go func(index int) {
+			defer waitGroup.Done()
+
+			pipelines[index], runErrors[index] = uut.Run(
+				context.Background(),
+				recipeFile,
+				[]string{inputFiles[index]},
+				make([]uut.FlagParameter, 0),
+				options,
+			)
+		}(index)
+	}
+
+	waitGroup.Wait()
+
+	for index, runError := range runErrors {
+		if runError != nil {
+			t.Fatalf("Expected no error, but was '%v'", runError)
+		}
+
+		changedFile := filepath.Join(pipelines[index].GetFinalChangeCaptureLocation(), inputFiles[index])
+
+		content, readError := os.ReadFile(changedFile)
+		if readError != nil || string(content) != "after" {
+			t.Errorf("Expected captured change of %s, but was '%s' (%v)", inputFiles[index], content, readError)
+		}
+	}
+
+	if pipelines[0].UUID == pipelines[1].UUID {
+		t.Errorf("Expected pipelines to have different uuids, but both were %s", pipelines[0].UUID)
+	}
+}
@@ -0,0 +1,84 @@
+package capturedpaths
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joomcode/errorx"
+	"github.com/spf13/afero"
+)
+
+const unionFsHiddenPathSuffix = "_HIDDEN~"
+
+// Collect returns all files and empty folders inside the target folder relative to it.
+// Empty folders are suffixed with a "/". Deletion markers are always returned as plain paths (without the trailing "/"),
+// no matter if the deleted entry was a file or a folder.
+func Collect(targetFolder string) ([]string, error) {
+	collectedPaths := make([]string, 0)
+
+	if err := filepath.Walk(targetFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath := strings.TrimPrefix(path, targetFolder)
+
+		if !info.IsDir() {
+			collectedPaths = append(collectedPaths, relativePath)
+
+			return nil
+		}
+
+		if IsDeletionMarker(relativePath) {
+			collectedPaths = append(collectedPaths, relativePath)
+
+			return filepath.SkipDir
+		}
+
+		if empty, _ := afero.IsEmpty(afero.NewOsFs(), path); empty {
+			folder := relativePath + "/"
+			if folder != "/" {
+				collectedPaths = append(collectedPaths, folder)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, errorx.ExternalError.Wrap(err, "Could not walk folder %s", targetFolder)
+	}
+
+	return collectedPaths, nil
+}
+
+// IsDeletionMarker checks if the path marks a deleted file or folder.
+func IsDeletionMarker(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, "/"), unionFsHiddenPathSuffix)
+}
+
+// WithoutDeletionMarker returns the path of the entry a deletion marker stands for.
+func WithoutDeletionMarker(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(path, "/"), unionFsHiddenPathSuffix)
+}
+
+// IsFolder checks if the collected path represents an (empty) folder.
+func IsFolder(path string) bool {
+	return strings.HasSuffix(path, "/")
+}
+
+// Normalize converts a declared path to the format of the collected paths (leading "/", no "./").
+func Normalize(path string) string {
+	isFolder := strings.HasSuffix(path, "/")
+
+	path = filepath.Clean("/" + path)
+	if isFolder && path != "/" {
+		path += "/"
+	}
+
+	return path
+}
+
+// RelativeToInput strips the input folder prefix from a path collected in a change capture location.
+func RelativeToInput(path string, inputFolderPath string) string {
+	return strings.TrimPrefix(path, inputFolderPath)
+}
@@ -1,139 +1,142 @@
 package comparer
 
 import (
-	"bytes"
-	"io"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
 
-	chastlog "chast.io/core/internal/logger"
 	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
 	recipemodel "chast.io/core/internal/recipe/pkg/model"
-	"github.com/joomcode/errorx"
-	"github.com/spf13/afero"
+	capturedpaths "chast.io/core/internal/tester/internal/captured_paths"
+	comparisonstrategy "chast.io/core/internal/tester/internal/comparison_strategy"
+	"chast.io/core/internal/tester/internal/expectation"
 )
 
-func CompareResults(test *recipemodel.Test, pipeline *refactoringpipelinemodel.Pipeline, workingDir string) {
+// CompareResults compares the final change capture of the pipeline against the expectations of the test.
+func CompareResults(
+	test *recipemodel.Test,
+	pipeline *refactoringpipelinemodel.Pipeline,
+	workingDir string,
+) (*Result, error) {
 	expectedOutputFolderPath, _ := filepath.Abs(filepath.Join(workingDir, "tests", test.ID, "expected"))
 	inputFolderPath, _ := filepath.Abs(filepath.Join(workingDir, "tests", test.ID, "input"))
 
-	if !checkFolderEquality(pipeline.GetFinalChangeCaptureLocation(), expectedOutputFolderPath, inputFolderPath) {
-		chastlog.Log.Errorf("Test %s failed", test.ID)
-	} else {
-		chastlog.Log.Infof("Test %s passed", test.ID)
+	selector, selectorError := comparisonstrategy.NewSelector(test.Compare)
+	if selectorError != nil {
+		return nil, selectorError
 	}
-}
-
-func checkFolderEquality(checkFolder string, expectedOutputFolder string, inputFolderPath string) bool {
-	expectedFileStructure, expectedPathCollectionError := collectPathsInFolder(expectedOutputFolder)
-	if expectedPathCollectionError != nil {
-		chastlog.Log.Errorf("Could not collect paths in folder %s: %v", expectedOutputFolder, expectedPathCollectionError)
 
-		return false
+	result, comparisonError := CompareFolders(
+		pipeline.GetFinalChangeCaptureLocation(),
+		expectedOutputFolderPath,
+		inputFolderPath,
+		expectation.New(test),
+		selector,
+	)
+	if comparisonError != nil {
+		return nil, comparisonError
 	}
 
-	actualFileStructure, actualPathCollectionError := collectPathsInFolder(checkFolder)
-	if actualPathCollectionError != nil {
-		chastlog.Log.Errorf("Could not collect paths in folder %s: %v", checkFolder, actualPathCollectionError)
+	result.TestID = test.ID
 
-		return false
-	}
+	return result, nil
+}
 
-	if len(expectedFileStructure) != len(actualFileStructure) {
-		chastlog.Log.Errorf("Expected %v files, got %v", len(expectedFileStructure), len(actualFileStructure))
+// CompareFolders compares the change capture folder against the expected output folder and the declared expectations.
+func CompareFolders(
+	checkFolder string,
+	expectedOutputFolder string,
+	inputFolderPath string,
+	testExpectation *expectation.Expectation,
+	selector *comparisonstrategy.Selector,
+) (*Result, error) {
+	result := newResult("")
+
+	expectedFileStructure, expectedPathCollectionError := collectExpectedPaths(expectedOutputFolder)
+	if expectedPathCollectionError != nil {
+		return nil, expectedPathCollectionError
+	}
 
-		return false
+	actualFileStructure, actualPathCollectionError := capturedpaths.Collect(checkFolder)
+	if actualPathCollectionError != nil {
+		return nil, actualPathCollectionError
 	}
 
 	sort.Strings(expectedFileStructure)
 	sort.Strings(actualFileStructure)
 
-	for index := range expectedFileStructure {
-		if expectedFileStructure[index] != strings.TrimPrefix(actualFileStructure[index], inputFolderPath) {
-			chastlog.Log.Errorf("Expected %v, got %v", expectedFileStructure[index], actualFileStructure[index])
-
-			return false
-		}
-
-		if !compareFiles(
-			filepath.Join(checkFolder, actualFileStructure[index]),
-			filepath.Join(expectedOutputFolder, expectedFileStructure[index]),
-		) {
-			chastlog.Log.Errorf("File does not match the expected.")
-			return false
-		}
-	}
+	actualPaths := make(map[string]string) // relative path -> collected path
+	relativeActualPaths := make([]string, 0, len(actualFileStructure))
 
-	return true
-}
+	for _, actualPath := range actualFileStructure {
+		relativePath := capturedpaths.RelativeToInput(actualPath, inputFolderPath)
 
-func collectPathsInFolder(targetFolder string) ([]string, error) {
-	actualFileStructure := make([]string, 0)
+		if testExpectation.IsIgnored(relativePath) {
+			result.Ignored = append(result.Ignored, relativePath)
 
-	if err := filepath.Walk(targetFolder, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+			continue
 		}
 
-		if !info.IsDir() {
-			actualFileStructure = append(actualFileStructure, strings.TrimPrefix(path, targetFolder))
-		} else {
-			if empty, _ := afero.IsEmpty(afero.NewOsFs(), path); empty {
-				folder := strings.TrimPrefix(path, targetFolder) + "/"
-				if folder != "/" {
-					actualFileStructure = append(actualFileStructure, folder)
-				}
-			}
-		}
-
-		return nil
-	}); err != nil {
-		return nil, errorx.ExternalError.Wrap(err, "Could not walk folder %s", targetFolder)
+		actualPaths[relativePath] = actualPath
+		relativeActualPaths = append(relativeActualPaths, relativePath)
 	}
 
-	return actualFileStructure, nil
-}
+	result.MissingDeletions = testExpectation.UnfulfilledDeletions(relativeActualPaths)
 
-func compareFiles(actualFilePath string, expectedFilePath string) bool {
-	actualFile, actualFileOpenError := os.Open(actualFilePath)
-	if actualFileOpenError != nil {
-		chastlog.Log.Errorf("Could not open file %s: %v", actualFilePath, actualFileOpenError)
+	for _, expectedPath := range expectedFileStructure {
+		if testExpectation.IsIgnored(expectedPath) {
+			continue
+		}
 
-		return false
-	}
+		actualPath, isPresent := actualPaths[expectedPath]
+		if !isPresent {
+			result.Missing = append(result.Missing, expectedPath)
 
-	expectedFile, expectedFileOpenError := os.Open(expectedFilePath)
-	if expectedFileOpenError != nil {
-		chastlog.Log.Errorf("Could not open file %s: %v", expectedFilePath, expectedFileOpenError)
+			continue
+		}
 
-		return false
-	}
+		delete(actualPaths, expectedPath)
 
-	actualFileContent, actualFileReadError := io.ReadAll(actualFile)
-	if actualFileReadError != nil {
-		chastlog.Log.Errorf("Could not read file %s: %v", actualFilePath, actualFileReadError)
+		if capturedpaths.IsDeletionMarker(expectedPath) || capturedpaths.IsFolder(expectedPath) {
+			continue
+		}
 
-		return false
-	}
+		comparison, fileComparisonError := selector.ForPath(expectedPath).Compare(
+			filepath.Join(checkFolder, actualPath),
+			filepath.Join(expectedOutputFolder, expectedPath),
+		)
+		if fileComparisonError != nil {
+			return nil, fileComparisonError
+		}
 
-	actualFileContent = bytes.TrimSpace(actualFileContent)
+		if !comparison.Equal {
+			result.Mismatched = append(result.Mismatched, expectedPath)
+			result.Diffs[expectedPath] = comparison.Diff
+		}
+	}
 
-	expectedFileContent, expectedFileReadError := io.ReadAll(expectedFile)
-	if expectedFileReadError != nil {
-		chastlog.Log.Errorf("Could not read file %s: %v", expectedFilePath, expectedFileReadError)
+	for _, relativePath := range relativeActualPaths {
+		if _, isUnhandled := actualPaths[relativePath]; !isUnhandled {
+			continue
+		}
 
-		return false
+		switch {
+		case testExpectation.IsUntouched(relativePath):
+			result.TouchedUntouched = append(result.TouchedUntouched, relativePath)
+		case testExpectation.IsDeclaredDeletion(relativePath):
+			result.Deleted = append(result.Deleted, relativePath)
+		default:
+			result.Unexpected = append(result.Unexpected, relativePath)
+		}
 	}
 
-	expectedFileContent = bytes.TrimSpace(expectedFileContent)
-
-	isEqual := bytes.Equal(actualFileContent, expectedFileContent)
+	return result, nil
+}
 
-	if !isEqual {
-		chastlog.Log.Debugf("Expected: \n%s\n\nGot: \n%s\n", expectedFileContent, actualFileContent)
+func collectExpectedPaths(expectedOutputFolder string) ([]string, error) {
+	if _, err := os.Stat(expectedOutputFolder); os.IsNotExist(err) {
+		return make([]string, 0), nil // tests only asserting deletions or untouched files do not need an expected folder
 	}
 
-	return isEqual
+	return capturedpaths.Collect(expectedOutputFolder)
 }
@@ -0,0 +1,145 @@
+package comparer_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	uut "chast.io/core/internal/tester/internal/comparer"
+	comparisonstrategy "chast.io/core/internal/tester/internal/comparison_strategy"
+	"chast.io/core/internal/tester/internal/expectation"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Error creating folder: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+func newDefaultSelector(t *testing.T) *comparisonstrategy.Selector {
+	t.Helper()
+
+	selector, err := comparisonstrategy.NewSelector(nil)
+	if err != nil {
+		t.Fatalf("Error creating comparison selector: %v", err)
+	}
+
+	return selector
+}
+
+func TestCompareFolders(t *testing.T) { //nolint:funlen // nested tests
+	t.Parallel()
+
+	baseDir := t.TempDir()
+	inputFolder := filepath.Join(baseDir, "input")
+	expectedFolder := filepath.Join(baseDir, "expected")
+	changeCaptureFolder := filepath.Join(baseDir, "capture")
+	capturedInputFolder := filepath.Join(changeCaptureFolder, inputFolder)
+
+	writeTestFile(t, filepath.Join(expectedFolder, "matching.java"), "content")
+	writeTestFile(t, filepath.Join(expectedFolder, "mismatching.java"), "expected content")
+	writeTestFile(t, filepath.Join(expectedFolder, "missing.java"), "content")
+
+	writeTestFile(t, filepath.Join(capturedInputFolder, "matching.java"), "content")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "mismatching.java"), "actual content")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "unexpected.java"), "content")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "deleted.java_HIDDEN~"), "")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "config.yaml"), "changed")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "build", "output.log"), "ignored")
+
+	testExpectation := expectation.New(&recipemodel.Test{ //nolint:exhaustruct // only expectations are relevant
+		Expect: &recipemodel.TestExpectation{
+			Deleted:   []string{"deleted.java", "./notDeleted.java"},
+			Untouched: []string{"config.yaml"},
+			Ignored:   []string{"build/"},
+		},
+	})
+
+	result, err := uut.CompareFolders(changeCaptureFolder, expectedFolder, inputFolder, testExpectation, newDefaultSelector(t))
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	tests := []struct {
+		name     string
+		actual   []string
+		expected []string
+	}{
+		{name: "Mismatched", actual: result.Mismatched, expected: []string{"/mismatching.java"}},
+		{name: "Missing", actual: result.Missing, expected: []string{"/missing.java"}},
+		{name: "Unexpected", actual: result.Unexpected, expected: []string{"/unexpected.java"}},
+		{name: "MissingDeletions", actual: result.MissingDeletions, expected: []string{"/notDeleted.java"}},
+		{name: "TouchedUntouched", actual: result.TouchedUntouched, expected: []string{"/config.yaml"}},
+		{name: "Deleted", actual: result.Deleted, expected: []string{"/deleted.java_HIDDEN~"}},
+		{name: "Ignored", actual: result.Ignored, expected: []string{"/build/output.log"}},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			if !reflect.DeepEqual(testCase.actual, testCase.expected) {
+				t.Errorf("Expected '%v', but was '%v'", testCase.expected, testCase.actual)
+			}
+		})
+	}
+
+	t.Run("should contain diff of mismatched files", func(t *testing.T) {
+		t.Parallel()
+
+		if diff := result.Diffs["/mismatching.java"]; diff == "" {
+			t.Errorf("Expected a diff for '/mismatching.java', but was empty")
+		}
+	})
+
+	t.Run("should fail", func(t *testing.T) {
+		t.Parallel()
+
+		if result.Passed() {
+			t.Errorf("Expected result to fail")
+		}
+	})
+}
+
+func TestCompareFolders_OnlyDeclaredExpectations(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+	inputFolder := filepath.Join(baseDir, "input")
+	changeCaptureFolder := filepath.Join(baseDir, "capture")
+
+	writeTestFile(t, filepath.Join(changeCaptureFolder, inputFolder, "deleted.java_HIDDEN~"), "")
+
+	testExpectation := expectation.New(&recipemodel.Test{ //nolint:exhaustruct // only expectations are relevant
+		Expect: &recipemodel.TestExpectation{
+			Deleted:   []string{"*.java"},
+			Untouched: []string{"other.java"},
+			Ignored:   make([]string, 0),
+		},
+	})
+
+	result, err := uut.CompareFolders(
+		changeCaptureFolder,
+		filepath.Join(baseDir, "expected"),
+		inputFolder,
+		testExpectation,
+		newDefaultSelector(t),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if !result.Passed() {
+		t.Errorf("Expected result to pass, but was '%+v'", result)
+	}
+}
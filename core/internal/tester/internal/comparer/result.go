@@ -0,0 +1,83 @@
+package comparer
+
+import (
+	"strings"
+
+	chastlog "chast.io/core/internal/logger"
+)
+
+type Result struct {
+	TestID string
+
+	// Mismatched contains changed files whose content does not match the expected content.
+	Mismatched []string
+	// Diffs contains the difference of each mismatched file to its expected content.
+	Diffs map[string]string
+	// Missing contains paths of the expected output which were not changed.
+	Missing []string
+	// Unexpected contains changed paths which are neither expected nor declared.
+	Unexpected []string
+	// MissingDeletions contains declared deletions which did not take place.
+	MissingDeletions []string
+	// TouchedUntouched contains changed paths which were declared as untouched.
+	TouchedUntouched []string
+	// Deleted contains deletions which were declared and took place.
+	Deleted []string
+	// Ignored contains changed paths which were ignored during the comparison.
+	Ignored []string
+}
+
+func newResult(testID string) *Result {
+	return &Result{
+		TestID:           testID,
+		Mismatched:       make([]string, 0),
+		Diffs:            make(map[string]string),
+		Missing:          make([]string, 0),
+		Unexpected:       make([]string, 0),
+		MissingDeletions: make([]string, 0),
+		TouchedUntouched: make([]string, 0),
+		Deleted:          make([]string, 0),
+		Ignored:          make([]string, 0),
+	}
+}
+
+func (r *Result) Passed() bool {
+	return len(r.Mismatched) == 0 &&
+		len(r.Missing) == 0 &&
+		len(r.Unexpected) == 0 &&
+		len(r.MissingDeletions) == 0 &&
+		len(r.TouchedUntouched) == 0
+}
+
+func (r *Result) Log() {
+	if r.Passed() {
+		chastlog.Log.Infof("Test %s passed", r.TestID)
+	} else {
+		chastlog.Log.Errorf("Test %s failed", r.TestID)
+	}
+
+	logCategory(chastlog.Log.Errorf, "Files not matching the expected content", r.Mismatched)
+	r.logDiffs()
+	logCategory(chastlog.Log.Errorf, "Expected changes which did not happen", r.Missing)
+	logCategory(chastlog.Log.Errorf, "Unexpected changes", r.Unexpected)
+	logCategory(chastlog.Log.Errorf, "Expected deletions which did not happen", r.MissingDeletions)
+	logCategory(chastlog.Log.Errorf, "Changes to files declared as untouched", r.TouchedUntouched)
+	logCategory(chastlog.Log.Debugf, "Expected deletions", r.Deleted)
+	logCategory(chastlog.Log.Debugf, "Ignored changes", r.Ignored)
+}
+
+func logCategory(logFunction func(format string, args ...interface{}), title string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	logFunction("  %s:\n    %s", title, strings.Join(paths, "\n    "))
+}
+
+func (r *Result) logDiffs() {
+	for _, path := range r.Mismatched {
+		if diff := r.Diffs[path]; diff != "" {
+			chastlog.Log.Errorf("  Difference of %s:\n%s", path, diff)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package comparisonstrategy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joomcode/errorx"
+)
+
+const chunkSize = 32 * 1024
+
+type binaryStrategy struct{}
+
+func newBinaryStrategy() *binaryStrategy {
+	return &binaryStrategy{}
+}
+
+func (strat *binaryStrategy) GetComparisonMode() ComparisonMode {
+	return Binary
+}
+
+func (strat *binaryStrategy) Compare(actualFilePath string, expectedFilePath string) (*Comparison, error) {
+	equal, err := filesEqual(actualFilePath, expectedFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if equal {
+		return &Comparison{Equal: true, Diff: ""}, nil
+	}
+
+	return &Comparison{
+		Equal: false,
+		Diff:  fmt.Sprintf("Binary files %s and %s differ", expectedFilePath, actualFilePath),
+	}, nil
+}
+
+// filesEqual compares two files chunk by chunk without loading them into memory.
+func filesEqual(actualFilePath string, expectedFilePath string) (bool, error) {
+	actualInfo, actualStatError := os.Stat(actualFilePath)
+	if actualStatError != nil {
+		return false, errorx.ExternalError.Wrap(actualStatError, "Could not stat file %s", actualFilePath)
+	}
+
+	expectedInfo, expectedStatError := os.Stat(expectedFilePath)
+	if expectedStatError != nil {
+		return false, errorx.ExternalError.Wrap(expectedStatError, "Could not stat file %s", expectedFilePath)
+	}
+
+	if actualInfo.Size() != expectedInfo.Size() {
+		return false, nil
+	}
+
+	actualFile, actualOpenError := os.Open(actualFilePath)
+	if actualOpenError != nil {
+		return false, errorx.ExternalError.Wrap(actualOpenError, "Could not open file %s", actualFilePath)
+	}
+	defer actualFile.Close()
+
+	expectedFile, expectedOpenError := os.Open(expectedFilePath)
+	if expectedOpenError != nil {
+		return false, errorx.ExternalError.Wrap(expectedOpenError, "Could not open file %s", expectedFilePath)
+	}
+	defer expectedFile.Close()
+
+	actualChunk := make([]byte, chunkSize)
+	expectedChunk := make([]byte, chunkSize)
+
+	for {
+		actualRead, actualReadError := io.ReadFull(actualFile, actualChunk)
+		expectedRead, expectedReadError := io.ReadFull(expectedFile, expectedChunk)
+
+		if !bytes.Equal(actualChunk[:actualRead], expectedChunk[:expectedRead]) {
+			return false, nil
+		}
+
+		actualDone, actualErr := isEndOfFile(actualReadError)
+		if actualErr != nil {
+			return false, errorx.ExternalError.Wrap(actualErr, "Could not read file %s", actualFilePath)
+		}
+
+		expectedDone, expectedErr := isEndOfFile(expectedReadError)
+		if expectedErr != nil {
+			return false, errorx.ExternalError.Wrap(expectedErr, "Could not read file %s", expectedFilePath)
+		}
+
+		if actualDone || expectedDone {
+			return actualDone == expectedDone, nil
+		}
+	}
+}
+
+func isEndOfFile(readError error) (bool, error) {
+	if readError == nil {
+		return false, nil
+	}
+
+	if errors.Is(readError, io.EOF) || errors.Is(readError, io.ErrUnexpectedEOF) {
+		return true, nil
+	}
+
+	return false, readError
+}
@@ -0,0 +1,83 @@
+package comparisonstrategy
+
+import (
+	"regexp"
+
+	"chast.io/core/internal/internal_util/glob"
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	capturedpaths "chast.io/core/internal/tester/internal/captured_paths"
+	"github.com/joomcode/errorx"
+)
+
+// Selector chooses the comparison strategy of a path based on the comparison configuration of a test.
+type Selector struct {
+	defaultStrategy Strategy
+	rules           []selectorRule
+}
+
+type selectorRule struct {
+	// pattern matches the normalized paths, "*" and "?" do not match across folders, "**" does.
+	pattern  *regexp.Regexp
+	strategy Strategy
+}
+
+func NewSelector(comparison *recipemodel.TestComparison) (*Selector, error) {
+	if comparison == nil {
+		comparison = &recipemodel.TestComparison{
+			Mode:        DefaultMode,
+			IgnoreLines: make([]string, 0),
+			Rules:       make([]recipemodel.TestComparisonRule, 0),
+		}
+	}
+
+	defaultStrategy, defaultStrategyError := New(comparison.Mode, comparison.IgnoreLines)
+	if defaultStrategyError != nil {
+		return nil, errorx.Decorate(defaultStrategyError, "Invalid comparison configuration")
+	}
+
+	rules := make([]selectorRule, 0, len(comparison.Rules))
+
+	for _, rule := range comparison.Rules {
+		if rule.Glob == "" {
+			return nil, errorx.IllegalFormat.New("Comparison rule requires a glob")
+		}
+
+		mode := rule.Mode
+		if mode == "" {
+			mode = defaultStrategy.GetComparisonMode()
+		}
+
+		strategy, strategyError := New(mode, rule.IgnoreLines)
+		if strategyError != nil {
+			return nil, errorx.Decorate(strategyError, "Invalid comparison rule for glob \"%s\"", rule.Glob)
+		}
+
+		pattern, patternError := regexp.Compile(glob.ToRegexp(capturedpaths.Normalize(rule.Glob)))
+		if patternError != nil {
+			return nil, errorx.IllegalFormat.Wrap(patternError, "Invalid glob \"%s\" of comparison rule", rule.Glob)
+		}
+
+		rules = append(rules, selectorRule{
+			pattern:  pattern,
+			strategy: strategy,
+		})
+	}
+
+	return &Selector{
+		defaultStrategy: defaultStrategy,
+		rules:           rules,
+	}, nil
+}
+
+// ForPath returns the strategy of the first rule matching the path (relative to the input folder) or the default.
+func (s *Selector) ForPath(path string) Strategy { //nolint:ireturn // strategies are only known by their interface
+	normalizedPath := capturedpaths.Normalize(path)
+
+	for _, rule := range s.rules {
+		if rule.pattern.MatchString(normalizedPath) {
+			return rule.strategy
+		}
+	}
+
+	return s.defaultStrategy
+}
@@ -0,0 +1,69 @@
+package comparisonstrategy
+
+import (
+	"regexp"
+
+	"chast.io/core/internal/internal_util/collection"
+	"github.com/joomcode/errorx"
+)
+
+type ComparisonMode = string
+
+const (
+	Exact       ComparisonMode = "exact"
+	Trimmed     ComparisonMode = "trimmed"
+	Whitespace  ComparisonMode = "whitespace"
+	LineEndings ComparisonMode = "lineEndings"
+	JSON        ComparisonMode = "json"
+	YAML        ComparisonMode = "yaml"
+	Binary      ComparisonMode = "binary"
+
+	DefaultMode = Trimmed
+)
+
+func Modes() []ComparisonMode {
+	return []ComparisonMode{Exact, Trimmed, Whitespace, LineEndings, JSON, YAML, Binary}
+}
+
+type Comparison struct {
+	Equal bool
+	// Diff describes the difference between the expected and the actual file, e.g. as unified diff.
+	Diff string
+}
+
+type Strategy interface {
+	Compare(actualFilePath string, expectedFilePath string) (*Comparison, error)
+	GetComparisonMode() ComparisonMode
+}
+
+func New(mode ComparisonMode, ignoreLines []string) (Strategy, error) { //nolint:ireturn // Factory method
+	if mode == "" {
+		mode = DefaultMode
+	}
+
+	ignoreLinePatterns := make([]*regexp.Regexp, 0, len(ignoreLines))
+
+	for _, ignoreLine := range ignoreLines {
+		pattern, compileError := regexp.Compile(ignoreLine)
+		if compileError != nil {
+			return nil, errorx.IllegalFormat.Wrap(compileError, "Invalid ignoreLines pattern \"%s\"", ignoreLine)
+		}
+
+		ignoreLinePatterns = append(ignoreLinePatterns, pattern)
+	}
+
+	if len(ignoreLinePatterns) > 0 && collection.Include([]ComparisonMode{JSON, YAML, Binary}, mode) {
+		return nil, errorx.IllegalFormat.New("ignoreLines can not be combined with comparison mode %s", mode)
+	}
+
+	switch mode {
+	case Exact, Trimmed, Whitespace, LineEndings:
+		return newTextStrategy(mode, ignoreLinePatterns), nil
+	case JSON, YAML:
+		return newStructuredStrategy(mode), nil
+	case Binary:
+		return newBinaryStrategy(), nil
+	default:
+		return nil, errorx.IllegalFormat.New("Unknown comparison mode \"%s\". Options: %s", mode, Modes())
+	}
+}
@@ -0,0 +1,231 @@
+package comparisonstrategy_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	uut "chast.io/core/internal/tester/internal/comparison_strategy"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+func TestStrategy_Compare(t *testing.T) { //nolint:funlen // table test
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		mode        uut.ComparisonMode
+		ignoreLines []string
+		actual      string
+		expected    string
+		equal       bool
+	}{
+		{name: "exact equal", mode: uut.Exact, actual: "a\nb\n", expected: "a\nb\n", equal: true},
+		{name: "exact trailing newline", mode: uut.Exact, actual: "a\nb", expected: "a\nb\n", equal: false},
+		{name: "trimmed trailing newline", mode: uut.Trimmed, actual: "a\nb", expected: "a\nb\n", equal: true},
+		{name: "trimmed inner change", mode: uut.Trimmed, actual: "a\nc", expected: "a\nb", equal: false},
+		{name: "default mode", mode: "", actual: " a ", expected: "a", equal: true},
+		{name: "whitespace", mode: uut.Whitespace, actual: "a  b\n\n\tc", expected: "a b\nc\n", equal: true},
+		{name: "whitespace content change", mode: uut.Whitespace, actual: "a b", expected: "a c", equal: false},
+		{name: "line endings", mode: uut.LineEndings, actual: "a\r\nb\r\n", expected: "a\nb\n", equal: true},
+		{name: "line endings whitespace", mode: uut.LineEndings, actual: "a \nb\n", expected: "a\nb\n", equal: false},
+		{name: "json key order", mode: uut.JSON, actual: `{"b": 1, "a": [1, 2]}`, expected: "{\n  \"a\": [1, 2],\n  \"b\": 1\n}", equal: true},
+		{name: "json value change", mode: uut.JSON, actual: `{"a": 1}`, expected: `{"a": 2}`, equal: false},
+		{name: "json invalid", mode: uut.JSON, actual: `{"a": `, expected: `{"a": 2}`, equal: false},
+		{name: "yaml formatting", mode: uut.YAML, actual: "a: [1, 2]\nb: x\n", expected: "b: \"x\"\na:\n  - 1\n  - 2\n", equal: true},
+		{name: "yaml documents", mode: uut.YAML, actual: "a: 1\n---\nb: 2\n", expected: "a: 1\n", equal: false},
+		{name: "binary equal", mode: uut.Binary, actual: "\x00\x01\x02", expected: "\x00\x01\x02", equal: true},
+		{name: "binary change", mode: uut.Binary, actual: "\x00\x01\x03", expected: "\x00\x01\x02", equal: false},
+		{
+			name:     "line endings split between chunks",
+			mode:     uut.LineEndings,
+			actual:   strings.Repeat("a", 32*1024-1) + "\r\nb",
+			expected: strings.Repeat("a", 32*1024-1) + "\nb",
+			equal:    true,
+		},
+		{
+			name:     "trimmed whitespace over several chunks",
+			mode:     uut.Trimmed,
+			actual:   strings.Repeat(" \n", 40000) + "a\n \nb" + strings.Repeat(" \n", 40000),
+			expected: "a\n \nb",
+			equal:    true,
+		},
+		{
+			name:     "trimmed inner whitespace over several chunks",
+			mode:     uut.Trimmed,
+			actual:   "a" + strings.Repeat(" ", 40000) + "b",
+			expected: "a" + strings.Repeat(" ", 39999) + "b",
+			equal:    false,
+		},
+		{
+			name:     "whitespace in long lines",
+			mode:     uut.Whitespace,
+			actual:   strings.Repeat("a b ", 40000),
+			expected: strings.Repeat("ab", 40000),
+			equal:    true,
+		},
+		{
+			name:     "exact change at the end of a long line",
+			mode:     uut.Exact,
+			actual:   strings.Repeat("a", 100000) + "b",
+			expected: strings.Repeat("a", 100000) + "c",
+			equal:    false,
+		},
+		{
+			name:     "exact longer text",
+			mode:     uut.Exact,
+			actual:   "a\nb\n",
+			expected: "a\n",
+			equal:    false,
+		},
+		{
+			name:        "ignore lines",
+			mode:        uut.Exact,
+			ignoreLines: []string{`^// generated at .*$`},
+			actual:      "// generated at 12:00\ncode\n",
+			expected:    "// generated at 11:00\ncode\n",
+			equal:       true,
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			baseDir := t.TempDir()
+			actualFilePath := filepath.Join(baseDir, "actual")
+			expectedFilePath := filepath.Join(baseDir, "expected")
+
+			writeTestFile(t, actualFilePath, testCase.actual)
+			writeTestFile(t, expectedFilePath, testCase.expected)
+
+			strategy, strategyError := uut.New(testCase.mode, testCase.ignoreLines)
+			if strategyError != nil {
+				t.Fatalf("Expected no error, but was '%v'", strategyError)
+			}
+
+			comparison, err := strategy.Compare(actualFilePath, expectedFilePath)
+			if err != nil {
+				t.Fatalf("Expected no error, but was '%v'", err)
+			}
+
+			if comparison.Equal != testCase.equal {
+				t.Errorf("Expected equal to be %v, but was %v", testCase.equal, comparison.Equal)
+			}
+
+			if !comparison.Equal && comparison.Diff == "" {
+				t.Errorf("Expected a diff for differing files, but was empty")
+			}
+		})
+	}
+}
+
+func TestStrategy_CompareLargeFiles(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+	actualFilePath := filepath.Join(baseDir, "actual")
+	expectedFilePath := filepath.Join(baseDir, "expected")
+
+	writeTestFile(t, actualFilePath, strings.Repeat("line\n", 500000)+"actual\n")
+	writeTestFile(t, expectedFilePath, strings.Repeat("line\n", 500000)+"expected\n")
+
+	strategy, strategyError := uut.New(uut.Trimmed, nil)
+	if strategyError != nil {
+		t.Fatalf("Expected no error, but was '%v'", strategyError)
+	}
+
+	comparison, err := strategy.Compare(actualFilePath, expectedFilePath)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if comparison.Equal {
+		t.Error("Expected files to differ, but were equal")
+	}
+
+	if !strings.Contains(comparison.Diff, "too large") {
+		t.Errorf("Expected no diff of large files, but was '%.100s'", comparison.Diff)
+	}
+}
+
+func TestNew_InvalidConfiguration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		mode        uut.ComparisonMode
+		ignoreLines []string
+	}{
+		{name: "unknown mode", mode: "fuzzy", ignoreLines: nil},
+		{name: "invalid pattern", mode: uut.Exact, ignoreLines: []string{"("}},
+		{name: "ignore lines with binary", mode: uut.Binary, ignoreLines: []string{"x"}},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := uut.New(testCase.mode, testCase.ignoreLines); err == nil {
+				t.Errorf("Expected an error, but was nil")
+			}
+		})
+	}
+}
+
+func TestSelector_ForPath(t *testing.T) {
+	t.Parallel()
+
+	selector, err := uut.NewSelector(&recipemodel.TestComparison{
+		Mode:        uut.LineEndings,
+		IgnoreLines: make([]string, 0),
+		Rules: []recipemodel.TestComparisonRule{
+			{Glob: "/**/*.json", Mode: uut.JSON, IgnoreLines: make([]string, 0)},
+			{Glob: "/assets/*", Mode: uut.Binary, IgnoreLines: make([]string, 0)},
+			{Glob: "/*.yaml", Mode: "", IgnoreLines: make([]string, 0)},
+			{Glob: "docs/**", Mode: uut.Trimmed, IgnoreLines: make([]string, 0)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	tests := []struct {
+		path string
+		mode uut.ComparisonMode
+	}{
+		{path: "/config/settings.json", mode: uut.JSON},
+		{path: "/settings.json", mode: uut.JSON},
+		{path: "/assets/logo.png", mode: uut.Binary},
+		{path: "/assets/icons/logo.png", mode: uut.LineEndings},
+		{path: "/config.yaml", mode: uut.LineEndings},
+		{path: "/src/Main.java", mode: uut.LineEndings},
+		{path: "docs/guide/index.md", mode: uut.Trimmed},
+		{path: "./assets/logo.png", mode: uut.Binary},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.path, func(t *testing.T) {
+			t.Parallel()
+
+			if mode := selector.ForPath(testCase.path).GetComparisonMode(); mode != testCase.mode {
+				t.Errorf("Expected mode %s, but was %s", testCase.mode, mode)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package comparisonstrategy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	unifieddiff "chast.io/core/internal/tester/internal/unified_diff"
+	"github.com/joomcode/errorx"
+	"gopkg.in/yaml.v3"
+)
+
+// structuredStrategy compares JSON or YAML files semantically, so formatting and key order are irrelevant.
+type structuredStrategy struct {
+	mode ComparisonMode
+}
+
+func newStructuredStrategy(mode ComparisonMode) *structuredStrategy {
+	return &structuredStrategy{
+		mode: mode,
+	}
+}
+
+func (strat *structuredStrategy) GetComparisonMode() ComparisonMode {
+	return strat.mode
+}
+
+func (strat *structuredStrategy) Compare(actualFilePath string, expectedFilePath string) (*Comparison, error) {
+	actualContent, actualReadError := os.ReadFile(actualFilePath)
+	if actualReadError != nil {
+		return nil, errorx.ExternalError.Wrap(actualReadError, "Could not read file %s", actualFilePath)
+	}
+
+	expectedContent, expectedReadError := os.ReadFile(expectedFilePath)
+	if expectedReadError != nil {
+		return nil, errorx.ExternalError.Wrap(expectedReadError, "Could not read file %s", expectedFilePath)
+	}
+
+	textDiff := unifieddiff.Diff(expectedFilePath, actualFilePath, string(expectedContent), string(actualContent))
+
+	actualDocuments, actualDecodeError := strat.decode(actualContent)
+	if actualDecodeError != nil {
+		return &Comparison{
+			Equal: false,
+			Diff:  fmt.Sprintf("File %s is not valid %s: %v\n%s", actualFilePath, strat.mode, actualDecodeError, textDiff),
+		}, nil
+	}
+
+	expectedDocuments, expectedDecodeError := strat.decode(expectedContent)
+	if expectedDecodeError != nil {
+		return nil, errorx.IllegalFormat.Wrap(expectedDecodeError, "Expected file %s is not valid %s", expectedFilePath, strat.mode)
+	}
+
+	if reflect.DeepEqual(actualDocuments, expectedDocuments) {
+		return &Comparison{Equal: true, Diff: ""}, nil
+	}
+
+	return &Comparison{Equal: false, Diff: textDiff}, nil
+}
+
+func (strat *structuredStrategy) decode(content []byte) ([]interface{}, error) {
+	documents := make([]interface{}, 0)
+
+	if strat.mode == JSON {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+
+		for {
+			var document interface{}
+			if err := decoder.Decode(&document); err != nil {
+				if errors.Is(err, io.EOF) {
+					return documents, nil
+				}
+
+				return nil, errorx.IllegalFormat.Wrap(err, "Could not decode json")
+			}
+
+			documents = append(documents, document)
+		}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		var document interface{}
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return documents, nil
+			}
+
+			return nil, errorx.IllegalFormat.Wrap(err, "Could not decode yaml")
+		}
+
+		documents = append(documents, document)
+	}
+}
@@ -0,0 +1,318 @@
+package comparisonstrategy
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	unifieddiff "chast.io/core/internal/tester/internal/unified_diff"
+	"github.com/joomcode/errorx"
+)
+
+// maxDiffSize is the size of the largest files a diff is built for, building it requires both files in memory.
+const maxDiffSize = 1024 * 1024
+
+type textStrategy struct {
+	mode        ComparisonMode
+	ignoreLines []*regexp.Regexp
+}
+
+func newTextStrategy(mode ComparisonMode, ignoreLines []*regexp.Regexp) *textStrategy {
+	return &textStrategy{
+		mode:        mode,
+		ignoreLines: ignoreLines,
+	}
+}
+
+func (strat *textStrategy) GetComparisonMode() ComparisonMode {
+	return strat.mode
+}
+
+// Compare compares the files chunk by chunk without loading them into memory. Only if they differ, a diff is built.
+func (strat *textStrategy) Compare(actualFilePath string, expectedFilePath string) (*Comparison, error) {
+	equal, compareError := strat.textsEqual(actualFilePath, expectedFilePath)
+	if compareError != nil {
+		return nil, compareError
+	}
+
+	if equal {
+		return &Comparison{Equal: true, Diff: ""}, nil
+	}
+
+	diff, diffError := strat.diff(actualFilePath, expectedFilePath)
+	if diffError != nil {
+		return nil, diffError
+	}
+
+	return &Comparison{Equal: false, Diff: diff}, nil
+}
+
+func (strat *textStrategy) textsEqual(actualFilePath string, expectedFilePath string) (bool, error) {
+	actualFile, actualOpenError := os.Open(actualFilePath)
+	if actualOpenError != nil {
+		return false, errorx.ExternalError.Wrap(actualOpenError, "Could not open file %s", actualFilePath)
+	}
+	defer actualFile.Close()
+
+	expectedFile, expectedOpenError := os.Open(expectedFilePath)
+	if expectedOpenError != nil {
+		return false, errorx.ExternalError.Wrap(expectedOpenError, "Could not open file %s", expectedFilePath)
+	}
+	defer expectedFile.Close()
+
+	actualText := strat.newTextReader(actualFile)
+	expectedText := strat.newTextReader(expectedFile)
+
+	var actualChunk, expectedChunk []byte
+
+	for {
+		if len(actualChunk) == 0 && !actualText.done {
+			var readError error
+			if actualChunk, readError = actualText.next(); readError != nil {
+				return false, errorx.ExternalError.Wrap(readError, "Could not read file %s", actualFilePath)
+			}
+
+			continue
+		}
+
+		if len(expectedChunk) == 0 && !expectedText.done {
+			var readError error
+			if expectedChunk, readError = expectedText.next(); readError != nil {
+				return false, errorx.ExternalError.Wrap(readError, "Could not read file %s", expectedFilePath)
+			}
+
+			continue
+		}
+
+		// a chunk is only empty at the end of its text
+		if len(actualChunk) == 0 || len(expectedChunk) == 0 {
+			return len(actualChunk) == len(expectedChunk), nil
+		}
+
+		length := len(actualChunk)
+		if len(expectedChunk) < length {
+			length = len(expectedChunk)
+		}
+
+		if !bytes.Equal(actualChunk[:length], expectedChunk[:length]) {
+			return false, nil
+		}
+
+		actualChunk = actualChunk[length:]
+		expectedChunk = expectedChunk[length:]
+	}
+}
+
+// diff returns the unified diff of the files without the ignored lines, unless they are too large.
+func (strat *textStrategy) diff(actualFilePath string, expectedFilePath string) (string, error) {
+	for _, filePath := range []string{actualFilePath, expectedFilePath} {
+		info, statError := os.Stat(filePath)
+		if statError != nil {
+			return "", errorx.ExternalError.Wrap(statError, "Could not stat file %s", filePath)
+		}
+
+		if info.Size() > maxDiffSize {
+			return fmt.Sprintf("Files %s and %s differ, they are too large to show the differences",
+				expectedFilePath, actualFilePath), nil
+		}
+	}
+
+	actualContent, actualReadError := os.ReadFile(actualFilePath)
+	if actualReadError != nil {
+		return "", errorx.ExternalError.Wrap(actualReadError, "Could not read file %s", actualFilePath)
+	}
+
+	expectedContent, expectedReadError := os.ReadFile(expectedFilePath)
+	if expectedReadError != nil {
+		return "", errorx.ExternalError.Wrap(expectedReadError, "Could not read file %s", expectedFilePath)
+	}
+
+	actualText := strat.removeIgnoredLines(string(actualContent))
+	expectedText := strat.removeIgnoredLines(string(expectedContent))
+
+	return unifieddiff.Diff(expectedFilePath, actualFilePath, expectedText, actualText), nil
+}
+
+func (strat *textStrategy) removeIgnoredLines(text string) string {
+	if len(strat.ignoreLines) == 0 {
+		return text
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	keptLines := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if !strat.isIgnored(strings.TrimRight(line, "\r\n")) {
+			keptLines = append(keptLines, line)
+		}
+	}
+
+	return strings.Join(keptLines, "")
+}
+
+func (strat *textStrategy) isIgnored(line string) bool {
+	for _, ignoreLine := range strat.ignoreLines {
+		if ignoreLine.MatchString(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// textReader reads a file in chunks of at most one line, without the ignored lines and normalized according to the
+// comparison mode.
+type textReader struct {
+	strat  *textStrategy
+	reader *bufio.Reader
+	done   bool
+	// started is set once content which is not whitespace was read, leading whitespace is trimmed before.
+	started bool
+	// trailingWhitespace is trimmed unless further content follows it.
+	trailingWhitespace []byte
+}
+
+func (strat *textStrategy) newTextReader(file io.Reader) *textReader {
+	return &textReader{
+		strat:              strat,
+		reader:             bufio.NewReaderSize(file, chunkSize),
+		done:               false,
+		started:            false,
+		trailingWhitespace: make([]byte, 0),
+	}
+}
+
+// next returns the next normalized chunk of the text. It is only empty at the end of the text.
+func (text *textReader) next() ([]byte, error) {
+	for !text.done {
+		chunk, readError := text.readChunk()
+		if readError != nil {
+			return nil, readError
+		}
+
+		if normalizedChunk := text.normalize(chunk); len(normalizedChunk) > 0 {
+			return normalizedChunk, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// readChunk reads the next line which is not ignored. Without ignored lines, long lines are read in several chunks.
+func (text *textReader) readChunk() ([]byte, error) {
+	for {
+		var chunk []byte
+
+		var readError error
+
+		if len(text.strat.ignoreLines) > 0 {
+			chunk, readError = text.reader.ReadBytes('\n')
+		} else {
+			chunk, readError = text.reader.ReadSlice('\n')
+			if errors.Is(readError, bufio.ErrBufferFull) {
+				readError = nil
+			}
+
+			chunk = append(make([]byte, 0, len(chunk)+1), chunk...)
+			chunk = text.completeLineEnding(chunk)
+		}
+
+		text.done = errors.Is(readError, io.EOF)
+		if readError != nil && !text.done {
+			return nil, readError //nolint:wrapcheck // wrapped by the caller
+		}
+
+		if len(text.strat.ignoreLines) > 0 && text.strat.isIgnored(strings.TrimRight(string(chunk), "\r\n")) {
+			if text.done {
+				return nil, nil
+			}
+
+			continue
+		}
+
+		return chunk, nil
+	}
+}
+
+// completeLineEnding adds the "\n" of a "\r\n" line ending split between two chunks to the chunk.
+func (text *textReader) completeLineEnding(chunk []byte) []byte {
+	if !bytes.HasSuffix(chunk, []byte("\r")) {
+		return chunk
+	}
+
+	if nextByte, peekError := text.reader.Peek(1); peekError == nil && nextByte[0] == '\n' {
+		_, _ = text.reader.ReadByte()
+
+		return append(chunk, '\n')
+	}
+
+	return chunk
+}
+
+func (text *textReader) normalize(chunk []byte) []byte {
+	switch text.strat.mode {
+	case Trimmed:
+		return text.trim(chunk)
+	case Whitespace:
+		return removeWhitespace(chunk)
+	case LineEndings:
+		return normalizeLineEndings(chunk)
+	default:
+		return chunk
+	}
+}
+
+// trim removes the whitespace at the beginning of the text and holds back whitespace until further content follows
+// it, so the whitespace at the end of the text is removed.
+func (text *textReader) trim(chunk []byte) []byte {
+	if !text.started {
+		chunk = bytes.TrimLeftFunc(chunk, unicode.IsSpace)
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		text.started = true
+	}
+
+	content := bytes.TrimRightFunc(chunk, unicode.IsSpace)
+	if len(content) == 0 {
+		text.trailingWhitespace = append(text.trailingWhitespace, chunk...)
+
+		return nil
+	}
+
+	trimmedChunk := make([]byte, 0, len(text.trailingWhitespace)+len(content))
+	trimmedChunk = append(append(trimmedChunk, text.trailingWhitespace...), content...)
+	text.trailingWhitespace = append(text.trailingWhitespace[:0], chunk[len(content):]...)
+
+	return trimmedChunk
+}
+
+// removeWhitespace removes all whitespace, including blank lines. Invalid characters are kept as they are.
+func removeWhitespace(chunk []byte) []byte {
+	content := make([]byte, 0, len(chunk))
+
+	for len(chunk) > 0 {
+		character, size := utf8.DecodeRune(chunk)
+		if !unicode.IsSpace(character) {
+			content = append(content, chunk[:size]...)
+		}
+
+		chunk = chunk[size:]
+	}
+
+	return content
+}
+
+func normalizeLineEndings(chunk []byte) []byte {
+	chunk = bytes.ReplaceAll(chunk, []byte("\r\n"), []byte("\n"))
+
+	return bytes.ReplaceAll(chunk, []byte("\r"), []byte("\n"))
+}
@@ -0,0 +1,79 @@
+package expectation
+
+import (
+	"chast.io/core/internal/internal_util/collection"
+	wildcardstring "chast.io/core/internal/internal_util/wildcard_string"
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	capturedpaths "chast.io/core/internal/tester/internal/captured_paths"
+)
+
+type Expectation struct {
+	Deleted   []*wildcardstring.WildcardString
+	Untouched []*wildcardstring.WildcardString
+	Ignored   []*wildcardstring.WildcardString
+}
+
+func New(test *recipemodel.Test) *Expectation {
+	if test.Expect == nil {
+		return &Expectation{
+			Deleted:   make([]*wildcardstring.WildcardString, 0),
+			Untouched: make([]*wildcardstring.WildcardString, 0),
+			Ignored:   make([]*wildcardstring.WildcardString, 0),
+		}
+	}
+
+	return &Expectation{
+		Deleted:   toPatterns(test.Expect.Deleted),
+		Untouched: toPatterns(test.Expect.Untouched),
+		Ignored:   toPatterns(test.Expect.Ignored),
+	}
+}
+
+func toPatterns(paths []string) []*wildcardstring.WildcardString {
+	return collection.Map(paths, func(path string) *wildcardstring.WildcardString {
+		return wildcardstring.NewWildcardString(capturedpaths.Normalize(path))
+	})
+}
+
+// IsIgnored checks if the path (relative to the input folder) must not be considered at all.
+func (e *Expectation) IsIgnored(path string) bool {
+	return matchesAny(e.Ignored, capturedpaths.WithoutDeletionMarker(path))
+}
+
+// IsUntouched checks if the path (relative to the input folder) is declared as not to be changed.
+func (e *Expectation) IsUntouched(path string) bool {
+	return matchesAny(e.Untouched, capturedpaths.WithoutDeletionMarker(path))
+}
+
+// IsDeclaredDeletion checks if the deletion marker path (relative to the input folder) is declared as deleted.
+func (e *Expectation) IsDeclaredDeletion(path string) bool {
+	if !capturedpaths.IsDeletionMarker(path) {
+		return false
+	}
+
+	return matchesAny(e.Deleted, capturedpaths.WithoutDeletionMarker(path))
+}
+
+// UnfulfilledDeletions returns all declared deletions without a matching deletion marker in the given paths.
+func (e *Expectation) UnfulfilledDeletions(paths []string) []string {
+	unfulfilled := make([]string, 0)
+
+	for _, deletion := range e.Deleted {
+		fulfilled := collection.Any(paths, func(path string) bool {
+			return capturedpaths.IsDeletionMarker(path) &&
+				deletion.MatchesPath(capturedpaths.WithoutDeletionMarker(path))
+		})
+
+		if !fulfilled {
+			unfulfilled = append(unfulfilled, deletion.Pattern)
+		}
+	}
+
+	return unfulfilled
+}
+
+func matchesAny(patterns []*wildcardstring.WildcardString, path string) bool {
+	return collection.Any(patterns, func(pattern *wildcardstring.WildcardString) bool {
+		return pattern.MatchesPath(path)
+	})
+}
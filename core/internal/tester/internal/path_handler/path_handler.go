@@ -2,6 +2,7 @@ package pathhandler
 
 import (
 	"path/filepath"
+	"sort"
 	"strings"
 
 	recipemodel "chast.io/core/internal/recipe/pkg/model"
@@ -9,69 +10,78 @@ import (
 	"github.com/joomcode/errorx"
 )
 
-func AbsolutizePathArgs(recipe *recipemodel.RefactoringRecipe, args []string, workingDir string) []string {
-	if len(args) == 0 {
-		return args
+// ResolveArgs converts the args of a test to run arguments.
+// Relative paths of path parameters are resolved against the "input" folder of the test.
+func ResolveArgs(
+	recipe *recipemodel.RefactoringRecipe,
+	args []recipemodel.TestValue,
+	workingDir string,
+) ([]string, error) {
+	parameters := make([]recipemodel.Parameter, 0, len(recipe.PositionalParameters)+1)
+	if recipe.PrimaryParameter != nil {
+		parameters = append(parameters, *recipe.PrimaryParameter)
 	}
 
-	convertedArgs := make([]string, len(args))
+	parameters = append(parameters, recipe.PositionalParameters...)
 
-	pathsRootFolder := filepath.Join(workingDir, "input")
-
-	primaryPath, absPrimPathErr := absolutizePath(args[0], recipe.PrimaryParameter.TypeExtension, pathsRootFolder)
-	if absPrimPathErr != nil {
-		panic(absPrimPathErr)
+	if len(args) > len(parameters) {
+		return nil, errorx.IllegalArgument.New(
+			"%d arguments passed, but the recipe only declares %d parameters", len(args), len(parameters),
+		)
 	}
 
-	convertedArgs[0] = primaryPath
-
-	index := 1
-	for index < len(args) {
-		arg := args[index]
-		param := recipe.PositionalParameters[index-1].TypeExtension
+	pathsRootFolder := filepath.Join(workingDir, "input")
+	convertedArgs := make([]string, len(args))
 
-		path, absPathErr := absolutizePath(arg, param, pathsRootFolder)
+	for index, arg := range args {
+		path, absPathErr := absolutizePath(string(arg), parameters[index].TypeExtension, pathsRootFolder)
 		if absPathErr != nil {
-			panic(absPathErr)
+			return nil, errorx.Decorate(absPathErr, "Invalid argument for parameter %s", parameters[index].ID)
 		}
 
 		convertedArgs[index] = path
-
-		index++
 	}
 
-	return convertedArgs
+	return convertedArgs, nil
 }
 
-func AbsolutizePathFlags(
+// ResolveFlags converts the flags of a test to run flags, sorted by their name.
+// Flags are looked up by their name or short name, relative paths of path flags are resolved against the "input"
+// folder of the test.
+func ResolveFlags(
 	recipe *recipemodel.RefactoringRecipe,
-	flags []refactoringservice.FlagParameter,
+	flags map[string]recipemodel.TestValue,
 	workingDir string,
-) []refactoringservice.FlagParameter {
-	if len(flags) == 0 {
-		return flags
+) ([]refactoringservice.FlagParameter, error) {
+	flagNames := make([]string, 0, len(flags))
+	for flagName := range flags {
+		flagNames = append(flagNames, flagName)
 	}
 
-	convertedFlags := make([]refactoringservice.FlagParameter, len(flags))
+	sort.Strings(flagNames)
 
 	pathsRootFolder := filepath.Join(workingDir, "input")
+	flagsMap := recipe.GetFlagsMap()
+	convertedFlags := make([]refactoringservice.FlagParameter, 0, len(flags))
 
-	index := 0
-	for index < len(flags) {
-		path, absPathErr := absolutizePath(flags[index].Value, recipe.Flags[index].TypeExtension, pathsRootFolder)
-		if absPathErr != nil {
-			panic(absPathErr)
+	for _, flagName := range flagNames {
+		flagDefinition := flagsMap[flagName]
+		if flagDefinition == nil {
+			return nil, errorx.IllegalArgument.New("Unknown flag %s", flagName)
 		}
 
-		convertedFlags[index] = refactoringservice.FlagParameter{
-			Name:  flags[index].Name,
-			Value: path,
+		path, absPathErr := absolutizePath(string(flags[flagName]), flagDefinition.TypeExtension, pathsRootFolder)
+		if absPathErr != nil {
+			return nil, errorx.Decorate(absPathErr, "Invalid value for flag %s", flagName)
 		}
 
-		index++
+		convertedFlags = append(convertedFlags, refactoringservice.FlagParameter{
+			Name:  flagName,
+			Value: path,
+		})
 	}
 
-	return convertedFlags
+	return convertedFlags, nil
 }
 
 func absolutizePath(path string, typeExtension recipemodel.TypeExtension, wordingDir string) (string, error) {
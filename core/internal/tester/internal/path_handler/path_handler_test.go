@@ -0,0 +1,84 @@
+package pathhandler_test
+
+import (
+	"reflect"
+	"testing"
+
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	refactoringservice "chast.io/core/internal/service/pkg/refactoring"
+	uut "chast.io/core/internal/tester/internal/path_handler"
+)
+
+func newTestRecipe() *recipemodel.RefactoringRecipe {
+	return &recipemodel.RefactoringRecipe{ //nolint:exhaustruct // only parameters are relevant
+		BaseRecipe: recipemodel.BaseRecipe{ //nolint:exhaustruct // only parameters are relevant
+			PositionalParameters: []recipemodel.Parameter{
+				{ID: "count", TypeExtension: recipemodel.TypeExtension{Type: "int"}},           //nolint:exhaustruct // type only
+				{ID: "configFile", TypeExtension: recipemodel.TypeExtension{Type: "filePath"}}, //nolint:exhaustruct // type only
+			},
+			Flags: []recipemodel.Flag{
+				{Name: "verbose", TypeExtension: recipemodel.TypeExtension{Type: "bool"}},                      //nolint:exhaustruct,lll // type only
+				{Name: "output", ShortName: "o", TypeExtension: recipemodel.TypeExtension{Type: "folderPath"}}, //nolint:exhaustruct,lll // type only
+			},
+		},
+		PrimaryParameter: &recipemodel.Parameter{ //nolint:exhaustruct // type only
+			ID:            "inputFile",
+			TypeExtension: recipemodel.TypeExtension{Type: "filePath"}, //nolint:exhaustruct // type only
+		},
+	}
+}
+
+func TestResolveArgs(t *testing.T) {
+	t.Parallel()
+
+	args, err := uut.ResolveArgs(
+		newTestRecipe(),
+		[]recipemodel.TestValue{"Main.java", "3", "/absolute/config.yaml"},
+		"/tests/java",
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	expected := []string{"/tests/java/input/Main.java", "3", "/absolute/config.yaml"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected args to be '%v', but was '%v'", expected, args)
+	}
+}
+
+func TestResolveArgs_TooManyArgs(t *testing.T) {
+	t.Parallel()
+
+	if _, err := uut.ResolveArgs(newTestRecipe(), []recipemodel.TestValue{"a", "1", "b", "c"}, "/tests/java"); err == nil {
+		t.Errorf("Expected an error, but was nil")
+	}
+}
+
+func TestResolveFlags(t *testing.T) {
+	t.Parallel()
+
+	flags, err := uut.ResolveFlags(
+		newTestRecipe(),
+		map[string]recipemodel.TestValue{"verbose": "true", "o": "out=dir"},
+		"/tests/java",
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	expected := []refactoringservice.FlagParameter{
+		{Name: "o", Value: "/tests/java/input/out=dir"},
+		{Name: "verbose", Value: "true"},
+	}
+	if !reflect.DeepEqual(flags, expected) {
+		t.Errorf("Expected flags to be '%v', but was '%v'", expected, flags)
+	}
+}
+
+func TestResolveFlags_UnknownFlag(t *testing.T) {
+	t.Parallel()
+
+	if _, err := uut.ResolveFlags(newTestRecipe(), map[string]recipemodel.TestValue{"unknown": "x"}, "/tests"); err == nil {
+		t.Errorf("Expected an error, but was nil")
+	}
+}
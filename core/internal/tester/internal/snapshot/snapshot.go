@@ -0,0 +1,285 @@
+package snapshot
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	chastlog "chast.io/core/internal/logger"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	capturedpaths "chast.io/core/internal/tester/internal/captured_paths"
+	"chast.io/core/internal/tester/internal/expectation"
+	"github.com/joomcode/errorx"
+)
+
+const defaultFolderPermission = 0o755
+const defaultFilePermission = 0o644
+
+type Summary struct {
+	TestID  string
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+func newSummary(testID string) *Summary {
+	return &Summary{
+		TestID:  testID,
+		Added:   make([]string, 0),
+		Updated: make([]string, 0),
+		Removed: make([]string, 0),
+	}
+}
+
+func (s *Summary) HasChanges() bool {
+	return len(s.Added) > 0 || len(s.Updated) > 0 || len(s.Removed) > 0
+}
+
+func (s *Summary) String() string {
+	var stringBuilder strings.Builder
+
+	stringBuilder.WriteString("Snapshot of test " + s.TestID)
+
+	if !s.HasChanges() {
+		stringBuilder.WriteString(" is up to date")
+
+		return stringBuilder.String()
+	}
+
+	stringBuilder.WriteString(" updated:")
+
+	for _, path := range s.Added {
+		stringBuilder.WriteString("\n  [+] " + path)
+	}
+
+	for _, path := range s.Updated {
+		stringBuilder.WriteString("\n  [~] " + path)
+	}
+
+	for _, path := range s.Removed {
+		stringBuilder.WriteString("\n  [-] " + path)
+	}
+
+	return stringBuilder.String()
+}
+
+// Update rewrites the expected folder of the test with the final change capture of the pipeline.
+func Update(test *recipemodel.Test, pipeline *refactoringpipelinemodel.Pipeline, workingDir string) (*Summary, error) {
+	expectedOutputFolderPath, _ := filepath.Abs(filepath.Join(workingDir, "tests", test.ID, "expected"))
+	inputFolderPath, _ := filepath.Abs(filepath.Join(workingDir, "tests", test.ID, "input"))
+
+	summary, err := UpdateFolder(
+		pipeline.GetFinalChangeCaptureLocation(),
+		expectedOutputFolderPath,
+		inputFolderPath,
+		expectation.New(test),
+	)
+	if err != nil {
+		return nil, errorx.InternalError.Wrap(err, "Failed to update snapshot of test %s", test.ID)
+	}
+
+	summary.TestID = test.ID
+
+	return summary, nil
+}
+
+// UpdateFolder synchronizes the expected output folder with the change capture folder.
+// Paths of the change capture folder are stored relative to the input folder path,
+// deletion markers are stored as empty files.
+// Ignored paths and declared deletions of the test expectation are not part of the snapshot.
+func UpdateFolder(
+	changeCaptureFolder string,
+	expectedOutputFolder string,
+	inputFolderPath string,
+	testExpectation *expectation.Expectation,
+) (*Summary, error) {
+	summary := newSummary("")
+
+	if err := os.MkdirAll(expectedOutputFolder, defaultFolderPermission); err != nil {
+		return nil, errorx.ExternalError.Wrap(err, "Failed to create expected output folder")
+	}
+
+	actualPaths, actualPathCollectionError := capturedpaths.Collect(changeCaptureFolder)
+	if actualPathCollectionError != nil {
+		return nil, actualPathCollectionError
+	}
+
+	expectedPaths, expectedPathCollectionError := capturedpaths.Collect(expectedOutputFolder)
+	if expectedPathCollectionError != nil {
+		return nil, expectedPathCollectionError
+	}
+
+	sort.Strings(actualPaths)
+	sort.Strings(expectedPaths)
+
+	snapshotPaths := make(map[string]string) // relative path -> collected path
+	relativeSnapshotPaths := make([]string, 0, len(actualPaths))
+
+	for _, actualPath := range actualPaths {
+		relativePath := capturedpaths.RelativeToInput(actualPath, inputFolderPath)
+
+		if testExpectation.IsIgnored(relativePath) || testExpectation.IsDeclaredDeletion(relativePath) {
+			continue
+		}
+
+		if testExpectation.IsUntouched(relativePath) {
+			chastlog.Log.Warnf("Path %s is declared as untouched, but was changed", relativePath)
+		}
+
+		snapshotPaths[relativePath] = actualPath
+		relativeSnapshotPaths = append(relativeSnapshotPaths, relativePath)
+	}
+
+	// outdated paths are removed first, so they can not interfere with the newly written ones
+	for _, expectedPath := range expectedPaths {
+		if _, isPartOfSnapshot := snapshotPaths[expectedPath]; isPartOfSnapshot || testExpectation.IsIgnored(expectedPath) {
+			continue
+		}
+
+		if err := removeSnapshotPath(filepath.Join(expectedOutputFolder, expectedPath), expectedOutputFolder); err != nil {
+			return nil, err
+		}
+
+		summary.Removed = append(summary.Removed, expectedPath)
+	}
+
+	for _, relativePath := range relativeSnapshotPaths {
+		status, err := writeSnapshotPath(
+			filepath.Join(changeCaptureFolder, snapshotPaths[relativePath]),
+			filepath.Join(expectedOutputFolder, relativePath),
+			relativePath,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case added:
+			summary.Added = append(summary.Added, relativePath)
+		case updated:
+			summary.Updated = append(summary.Updated, relativePath)
+		case unchanged:
+		}
+	}
+
+	return summary, nil
+}
+
+type pathStatus int8
+
+const (
+	unchanged pathStatus = iota
+	added
+	updated
+)
+
+func writeSnapshotPath(sourcePath string, targetPath string, relativePath string) (pathStatus, error) {
+	targetPath = strings.TrimSuffix(targetPath, "/")
+
+	existingInfo, statError := os.Lstat(targetPath)
+	targetExists := statError == nil
+
+	switch {
+	case capturedpaths.IsFolder(relativePath):
+		if targetExists && existingInfo.IsDir() {
+			return unchanged, nil
+		}
+
+		if err := replaceWithFolder(targetPath, targetExists); err != nil {
+			return unchanged, err
+		}
+	case capturedpaths.IsDeletionMarker(relativePath):
+		if targetExists && !existingInfo.IsDir() && existingInfo.Size() == 0 {
+			return unchanged, nil
+		}
+
+		if err := writeFile(targetPath, []byte{}, targetExists); err != nil {
+			return unchanged, err
+		}
+	default:
+		content, readError := os.ReadFile(sourcePath)
+		if readError != nil {
+			return unchanged, errorx.ExternalError.Wrap(readError, "Failed to read captured file %s", sourcePath)
+		}
+
+		if targetExists && !existingInfo.IsDir() {
+			existingContent, existingReadError := os.ReadFile(targetPath)
+			if existingReadError != nil {
+				return unchanged, errorx.ExternalError.Wrap(existingReadError, "Failed to read snapshot file %s", targetPath)
+			}
+
+			if bytes.Equal(content, existingContent) {
+				return unchanged, nil
+			}
+		}
+
+		if err := writeFile(targetPath, content, targetExists); err != nil {
+			return unchanged, err
+		}
+	}
+
+	if targetExists {
+		return updated, nil
+	}
+
+	return added, nil
+}
+
+func replaceWithFolder(targetPath string, targetExists bool) error {
+	if targetExists {
+		if err := os.RemoveAll(targetPath); err != nil {
+			return errorx.ExternalError.Wrap(err, "Failed to remove outdated snapshot path %s", targetPath)
+		}
+	}
+
+	if err := os.MkdirAll(targetPath, defaultFolderPermission); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to create snapshot folder %s", targetPath)
+	}
+
+	return nil
+}
+
+func writeFile(targetPath string, content []byte, targetExists bool) error {
+	if targetExists {
+		if err := os.RemoveAll(targetPath); err != nil {
+			return errorx.ExternalError.Wrap(err, "Failed to remove outdated snapshot path %s", targetPath)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), defaultFolderPermission); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to create snapshot folder %s", filepath.Dir(targetPath))
+	}
+
+	if err := os.WriteFile(targetPath, content, defaultFilePermission); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to write snapshot file %s", targetPath)
+	}
+
+	return nil
+}
+
+func removeSnapshotPath(path string, rootFolder string) error {
+	path = strings.TrimSuffix(path, "/")
+
+	if err := os.RemoveAll(path); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to remove snapshot path %s", path)
+	}
+
+	// remove parent folders which only existed because of the removed path
+	for parent := filepath.Dir(path); parent != rootFolder && strings.HasPrefix(parent, rootFolder); parent = filepath.Dir(parent) {
+		entries, readDirError := os.ReadDir(parent)
+		if readDirError != nil || len(entries) > 0 {
+			break
+		}
+
+		chastlog.Log.Tracef("Removing empty snapshot folder %s", parent)
+
+		if err := os.Remove(parent); err != nil {
+			return errorx.ExternalError.Wrap(err, "Failed to remove empty snapshot folder %s", parent)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package snapshot_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	recipemodel "chast.io/core/internal/recipe/pkg/model"
+	"chast.io/core/internal/tester/internal/expectation"
+	uut "chast.io/core/internal/tester/internal/snapshot"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Error creating folder: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+func TestUpdateFolder(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+	inputFolder := filepath.Join(baseDir, "tests", "java", "input")
+	expectedFolder := filepath.Join(baseDir, "tests", "java", "expected")
+	changeCaptureFolder := filepath.Join(baseDir, "capture")
+	capturedInputFolder := filepath.Join(changeCaptureFolder, inputFolder)
+
+	writeTestFile(t, filepath.Join(capturedInputFolder, "changed.java"), "new content")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "unchanged.java"), "same content")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "new", "added.java"), "added content")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "deleted.java_HIDDEN~"), "")
+
+	if err := os.MkdirAll(filepath.Join(capturedInputFolder, "deletedFolder_HIDDEN~"), 0o755); err != nil {
+		t.Fatalf("Error creating folder: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(expectedFolder, "changed.java"), "old content")
+	writeTestFile(t, filepath.Join(expectedFolder, "unchanged.java"), "same content")
+	writeTestFile(t, filepath.Join(expectedFolder, "outdated", "outdated.java"), "outdated content")
+
+	writeTestFile(t, filepath.Join(capturedInputFolder, "build.log"), "ignored content")
+	writeTestFile(t, filepath.Join(capturedInputFolder, "declared.java_HIDDEN~"), "")
+
+	testExpectation := expectation.New(&recipemodel.Test{ //nolint:exhaustruct // only expectations are relevant
+		Expect: &recipemodel.TestExpectation{
+			Deleted:   []string{"declared.java"},
+			Untouched: make([]string, 0),
+			Ignored:   []string{"*.log"},
+		},
+	})
+
+	summary, err := uut.UpdateFolder(changeCaptureFolder, expectedFolder, inputFolder, testExpectation)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	t.Run("should list added paths", func(t *testing.T) {
+		t.Parallel()
+
+		expected := []string{"/deleted.java_HIDDEN~", "/deletedFolder_HIDDEN~", "/new/added.java"}
+		if !reflect.DeepEqual(summary.Added, expected) {
+			t.Errorf("Expected added paths to be '%v', but was '%v'", expected, summary.Added)
+		}
+	})
+
+	t.Run("should list updated paths", func(t *testing.T) {
+		t.Parallel()
+
+		expected := []string{"/changed.java"}
+		if !reflect.DeepEqual(summary.Updated, expected) {
+			t.Errorf("Expected updated paths to be '%v', but was '%v'", expected, summary.Updated)
+		}
+	})
+
+	t.Run("should list removed paths", func(t *testing.T) {
+		t.Parallel()
+
+		expected := []string{"/outdated/outdated.java"}
+		if !reflect.DeepEqual(summary.Removed, expected) {
+			t.Errorf("Expected removed paths to be '%v', but was '%v'", expected, summary.Removed)
+		}
+	})
+
+	t.Run("should write captured content", func(t *testing.T) {
+		t.Parallel()
+
+		content, readError := os.ReadFile(filepath.Join(expectedFolder, "changed.java"))
+		if readError != nil {
+			t.Fatalf("Expected no error, but was '%v'", readError)
+		}
+
+		if string(content) != "new content" {
+			t.Errorf("Expected content to be 'new content', but was '%s'", content)
+		}
+	})
+
+	t.Run("should store deleted folders as marker files", func(t *testing.T) {
+		t.Parallel()
+
+		info, statError := os.Stat(filepath.Join(expectedFolder, "deletedFolder_HIDDEN~"))
+		if statError != nil {
+			t.Fatalf("Expected no error, but was '%v'", statError)
+		}
+
+		if info.IsDir() {
+			t.Errorf("Expected deletion marker to be a file, but was a folder")
+		}
+	})
+
+	t.Run("should remove emptied folders", func(t *testing.T) {
+		t.Parallel()
+
+		if _, statError := os.Stat(filepath.Join(expectedFolder, "outdated")); !os.IsNotExist(statError) {
+			t.Errorf("Expected outdated folder to be removed")
+		}
+	})
+}
+
+func TestUpdateFolder_UpToDate(t *testing.T) {
+	t.Parallel()
+
+	baseDir := t.TempDir()
+	inputFolder := filepath.Join(baseDir, "input")
+	expectedFolder := filepath.Join(baseDir, "expected")
+	changeCaptureFolder := filepath.Join(baseDir, "capture")
+
+	writeTestFile(t, filepath.Join(changeCaptureFolder, inputFolder, "file.java"), "content")
+	writeTestFile(t, filepath.Join(expectedFolder, "file.java"), "content")
+
+	summary, err := uut.UpdateFolder(
+		changeCaptureFolder,
+		expectedFolder,
+		inputFolder,
+		expectation.New(&recipemodel.Test{}), //nolint:exhaustruct // no expectations required
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	if summary.HasChanges() {
+		t.Errorf("Expected no changes, but was '%v'", summary)
+	}
+}
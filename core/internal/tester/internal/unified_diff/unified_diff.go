@@ -0,0 +1,151 @@
+package unifieddiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const contextLines = 3
+
+type lineOperation struct {
+	operation byte // ' ' equal, '-' delete, '+' insert
+	text      string
+	oldLine   int // number of old lines before this line
+	newLine   int // number of new lines before this line
+}
+
+// Diff builds a unified diff (with three lines of context) from the expected to the actual content.
+// An empty string is returned if both contents are equal.
+func Diff(expectedName string, actualName string, expected string, actual string) string {
+	lineOperations := buildLineOperations(expected, actual)
+
+	var stringBuilder strings.Builder
+
+	index := 0
+	for index < len(lineOperations) {
+		changeIndex := nextChange(lineOperations, index)
+		if changeIndex < 0 {
+			break
+		}
+
+		if stringBuilder.Len() == 0 {
+			stringBuilder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", expectedName, actualName))
+		}
+
+		hunkStart, hunkEnd := hunkBounds(lineOperations, changeIndex)
+		writeHunk(&stringBuilder, lineOperations[hunkStart:hunkEnd])
+
+		index = hunkEnd
+	}
+
+	return stringBuilder.String()
+}
+
+func buildLineOperations(expected string, actual string) []lineOperation {
+	dmp := diffmatchpatch.New()
+	expectedChars, actualChars, lines := dmp.DiffLinesToChars(expected, actual)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(expectedChars, actualChars, false), lines)
+
+	lineOperations := make([]lineOperation, 0)
+	oldLine, newLine := 0, 0
+
+	for _, diff := range diffs {
+		for _, line := range splitLines(diff.Text) {
+			entry := lineOperation{operation: ' ', text: line, oldLine: oldLine, newLine: newLine}
+
+			switch diff.Type {
+			case diffmatchpatch.DiffEqual:
+				oldLine++
+				newLine++
+			case diffmatchpatch.DiffDelete:
+				entry.operation = '-'
+				oldLine++
+			case diffmatchpatch.DiffInsert:
+				entry.operation = '+'
+				newLine++
+			}
+
+			lineOperations = append(lineOperations, entry)
+		}
+	}
+
+	return lineOperations
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func nextChange(lineOperations []lineOperation, start int) int {
+	for index := start; index < len(lineOperations); index++ {
+		if lineOperations[index].operation != ' ' {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// hunkBounds returns the range of a hunk starting at the given change, merging changes with overlapping context.
+func hunkBounds(lineOperations []lineOperation, changeIndex int) (int, int) {
+	lastChange := changeIndex
+
+	for index := changeIndex; index < len(lineOperations); index++ {
+		if lineOperations[index].operation != ' ' {
+			lastChange = index
+		} else if index-lastChange > 2*contextLines {
+			break
+		}
+	}
+
+	hunkStart := changeIndex - contextLines
+	if hunkStart < 0 {
+		hunkStart = 0
+	}
+
+	hunkEnd := lastChange + contextLines + 1
+	if hunkEnd > len(lineOperations) {
+		hunkEnd = len(lineOperations)
+	}
+
+	return hunkStart, hunkEnd
+}
+
+func writeHunk(stringBuilder *strings.Builder, hunk []lineOperation) {
+	oldCount, newCount := 0, 0
+
+	for _, line := range hunk {
+		if line.operation != '+' {
+			oldCount++
+		}
+
+		if line.operation != '-' {
+			newCount++
+		}
+	}
+
+	stringBuilder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+		hunkRange(hunk[0].oldLine, oldCount),
+		hunkRange(hunk[0].newLine, newCount),
+	))
+
+	for _, line := range hunk {
+		stringBuilder.WriteByte(line.operation)
+		stringBuilder.WriteString(line.text)
+		stringBuilder.WriteByte('\n')
+	}
+}
+
+func hunkRange(linesBefore int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", linesBefore)
+	}
+
+	return fmt.Sprintf("%d,%d", linesBefore+1, count)
+}
@@ -0,0 +1,58 @@
+package unifieddiff_test
+
+import (
+	"testing"
+
+	uut "chast.io/core/internal/tester/internal/unified_diff"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     string
+	}{
+		{
+			name:     "should return empty string for equal content",
+			expected: "a\nb\n",
+			actual:   "a\nb\n",
+			want:     "",
+		},
+		{
+			name:     "should show changed line with context",
+			expected: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			actual:   "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- expected\n+++ actual\n" +
+				"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:     "should split distant changes into separate hunks",
+			expected: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			actual:   "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- expected\n+++ actual\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name:     "should show added lines of empty expectation",
+			expected: "",
+			actual:   "a\n",
+			want:     "--- expected\n+++ actual\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := uut.Diff("expected", "actual", testCase.expected, testCase.actual); got != testCase.want {
+				t.Errorf("Diff() = \n%v\nwant\n%v", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package tester
+
+import refactoringservice "chast.io/core/internal/service/pkg/refactoring"
+
+type Options struct {
+	UpdateSnapshots bool
+	// Parallel is the maximum number of tests running at the same time.
+	Parallel int
+	// Run is a regular expression selecting the tests to run by their id. All tests run if it is empty.
+	Run string
+	// FailFast stops starting new tests after the first failed test.
+	FailFast bool
+	// RunOptions configures how the pipelines of the tests run.
+	RunOptions *refactoringservice.RunOptions
+}
+
+func NewOptions() *Options {
+	return &Options{
+		UpdateSnapshots: false,
+		Parallel:        1,
+		Run:             "",
+		FailFast:        false,
+		RunOptions:      newRunOptions(),
+	}
+}
+
+func newRunOptions() *refactoringservice.RunOptions {
+	runOptions := refactoringservice.NewRunOptions()
+	runOptions.UseCache = false // tests verify the tools, so they always run them
+
+	return runOptions
+}
@@ -1,66 +1,240 @@
 package tester
 
 import (
+	"context"
 	"path/filepath"
-	"strings"
+	"regexp"
+	"sync"
 
 	"chast.io/core/internal/internal_util/collection"
 	chastlog "chast.io/core/internal/logger"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
 	recipemodel "chast.io/core/internal/recipe/pkg/model"
 	"chast.io/core/internal/recipe/pkg/parser"
 	refactoringservice "chast.io/core/internal/service/pkg/refactoring"
 	"chast.io/core/internal/tester/internal/comparer"
 	pathhandler "chast.io/core/internal/tester/internal/path_handler"
+	"chast.io/core/internal/tester/internal/snapshot"
 	util "chast.io/core/pkg/util/fs/file"
 	"github.com/joomcode/errorx"
 )
 
-func Test(recipeFile *util.File) {
+type testStatus int8
+
+const (
+	passed testStatus = iota
+	failed
+	skipped
+)
+
+// testsFolder is the folder within the workspace locations the pipelines of the tests run in.
+const testsFolder = "tests"
+
+// outputLock groups the output of each test, so concurrently running tests do not interleave their results.
+var outputLock sync.Mutex //nolint:gochecknoglobals // shared by all concurrently running tests
+
+// Test runs the tests of the recipe. No further tests are started once the context is done.
+func Test(ctx context.Context, recipeFile *util.File, options *Options) error {
 	parsedRecipe, recipeParseError := parser.ParseRecipe(recipeFile)
 	if recipeParseError != nil {
-		panic(recipeParseError)
+		return errorx.Decorate(recipeParseError, "Invalid recipe %s", recipeFile.AbsolutePath)
 	}
 
 	switch concreteRecipe := (*parsedRecipe).(type) {
 	case *recipemodel.RefactoringRecipe:
-		workingDir := recipeFile.ParentDirectory
+		tests, testSelectionError := selectTests(concreteRecipe.Tests, options.Run)
+		if testSelectionError != nil {
+			return testSelectionError
+		}
 
-		if len(concreteRecipe.Tests) == 0 {
+		if len(tests) == 0 {
 			chastlog.Log.Infof("No tests found for recipe %s", recipeFile.AbsolutePath)
 
-			return
+			return nil
 		}
 
-		for index, test := range concreteRecipe.Tests {
-			testWorkingDir := filepath.Join(workingDir, "tests", test.ID)
-
-			args := pathhandler.AbsolutizePathArgs(concreteRecipe, test.Args, testWorkingDir)
-			flags := pathhandler.AbsolutizePathFlags(concreteRecipe, convertFlags(test.Flags), testWorkingDir)
+		statuses := runTests(ctx, recipeFile, concreteRecipe, tests, options)
 
-			pipeline, recipeRunError := refactoringservice.Run(
-				recipeFile,
-				args,
-				flags,
-			)
+		passedCount := collection.Count(statuses, func(status testStatus) bool { return status == passed })
+		failedCount := collection.Count(statuses, func(status testStatus) bool { return status == failed })
+		skippedCount := collection.Count(statuses, func(status testStatus) bool { return status == skipped })
 
-			if recipeRunError != nil {
-				panic(recipeRunError)
-			}
+		chastlog.Log.Infof("%d passed, %d failed, %d skipped", passedCount, failedCount, skippedCount)
 
-			comparer.CompareResults(&concreteRecipe.Tests[index], pipeline, workingDir)
+		if failedCount > 0 {
+			return errorx.InternalError.New("%d of %d tests failed", failedCount, len(tests))
 		}
+
+		return nil
 	default:
-		panic(errorx.UnsupportedOperation.New("No run model builder for recipe of type %T", concreteRecipe.GetRecipeType()))
+		return errorx.UnsupportedOperation.New("No tester for recipe of type %T", concreteRecipe.GetRecipeType())
 	}
 }
 
-func convertFlags(flags []string) []refactoringservice.FlagParameter {
-	return collection.Map(flags, func(flag string) refactoringservice.FlagParameter {
-		split := strings.Split(flag, "=")
+func selectTests(tests []recipemodel.Test, runPattern string) ([]*recipemodel.Test, error) {
+	allTests := make([]*recipemodel.Test, 0, len(tests))
+	for index := range tests {
+		allTests = append(allTests, &tests[index])
+	}
+
+	if runPattern == "" {
+		return allTests, nil
+	}
+
+	testIDPattern, compileError := regexp.Compile(runPattern)
+	if compileError != nil {
+		return nil, errorx.IllegalArgument.Wrap(compileError, "Invalid test selection pattern \"%s\"", runPattern)
+	}
+
+	return collection.Filter(allTests, func(test *recipemodel.Test) bool {
+		return testIDPattern.MatchString(test.ID)
+	}), nil
+}
 
-		return refactoringservice.FlagParameter{
-			Name:  split[0],
-			Value: split[1],
+// runTests runs the tests with at most options.Parallel tests at the same time.
+// Each test runs its own pipeline, so they do not share any pipeline directories.
+func runTests(
+	ctx context.Context,
+	recipeFile *util.File,
+	recipe *recipemodel.RefactoringRecipe,
+	tests []*recipemodel.Test,
+	options *Options,
+) []testStatus {
+	parallel := options.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	statuses := make([]testStatus, len(tests))
+	slots := make(chan struct{}, parallel)
+
+	var waitGroup sync.WaitGroup
+
+	var stopLock sync.Mutex
+
+	stopped := false
+
+	for index, test := range tests {
+		slots <- struct{}{}
+
+		stopLock.Lock()
+		if stopped || ctx.Err() != nil {
+			stopLock.Unlock()
+			<-slots
+
+			statuses[index] = skipped
+
+			continue
 		}
-	})
+		stopLock.Unlock()
+
+		waitGroup.Add(1)
+
+		go func(index int, test *recipemodel.Test) {
+			defer waitGroup.Done()
+			defer func() { <-slots }()
+
+			statuses[index] = runTest(ctx, recipeFile, recipe, test, options)
+
+			if statuses[index] == failed && options.FailFast {
+				stopLock.Lock()
+				stopped = true
+				stopLock.Unlock()
+			}
+		}(index, test)
+	}
+
+	waitGroup.Wait()
+
+	return statuses
+}
+
+func runTest(
+	ctx context.Context,
+	recipeFile *util.File,
+	recipe *recipemodel.RefactoringRecipe,
+	test *recipemodel.Test,
+	options *Options,
+) testStatus {
+	workingDir := recipeFile.ParentDirectory
+	testWorkingDir := filepath.Join(workingDir, "tests", test.ID)
+
+	args, argsResolveError := pathhandler.ResolveArgs(recipe, test.Args, testWorkingDir)
+	if argsResolveError != nil {
+		logTestOutput(func() { chastlog.Log.Errorf("Test %s failed: %v", test.ID, argsResolveError) })
+
+		return failed
+	}
+
+	flags, flagsResolveError := pathhandler.ResolveFlags(recipe, test.Flags, testWorkingDir)
+	if flagsResolveError != nil {
+		logTestOutput(func() { chastlog.Log.Errorf("Test %s failed: %v", test.ID, flagsResolveError) })
+
+		return failed
+	}
+
+	pipeline, recipeRunError := refactoringservice.Run(
+		ctx,
+		recipeFile,
+		args,
+		flags,
+		testRunOptions(options.RunOptions, test),
+	)
+	if recipeRunError != nil {
+		logTestOutput(func() { chastlog.Log.Errorf("Test %s failed: %v", test.ID, recipeRunError) })
+
+		return failed
+	}
+
+	if options.UpdateSnapshots {
+		return updateSnapshot(test, pipeline, workingDir)
+	}
+
+	result, comparisonError := comparer.CompareResults(test, pipeline, workingDir)
+	if comparisonError != nil {
+		logTestOutput(func() { chastlog.Log.Errorf("Test %s failed: %v", test.ID, comparisonError) })
+
+		return failed
+	}
+
+	logTestOutput(result.Log)
+
+	if !result.Passed() {
+		return failed
+	}
+
+	return passed
+}
+
+// testRunOptions returns the run options for the pipeline of the test. Its operation and change capture folders are
+// within folders of the test, so concurrently running tests never share them.
+func testRunOptions(options *refactoringservice.RunOptions, test *recipemodel.Test) *refactoringservice.RunOptions {
+	locations := *options.Locations
+	locations.OperationLocation = filepath.Join(locations.OperationLocation, testsFolder, test.ID)
+	locations.ChangeCaptureLocation = filepath.Join(locations.ChangeCaptureLocation, testsFolder, test.ID)
+
+	testOptions := *options
+	testOptions.Locations = &locations
+
+	return &testOptions
+}
+
+func updateSnapshot(test *recipemodel.Test, pipeline *refactoringpipelinemodel.Pipeline, workingDir string) testStatus {
+	summary, snapshotUpdateError := snapshot.Update(test, pipeline, workingDir)
+	if snapshotUpdateError != nil {
+		logTestOutput(func() { chastlog.Log.Errorf("%v", snapshotUpdateError) })
+
+		return failed
+	}
+
+	logTestOutput(func() { chastlog.Log.Infof("%s", summary) })
+
+	return passed
+}
+
+func logTestOutput(log func()) {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	log()
 }
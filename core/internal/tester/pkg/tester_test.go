@@ -0,0 +1,101 @@
+package tester_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chast.io/core/internal/pipeline/pkg/workspace"
+	uut "chast.io/core/internal/tester/pkg"
+	util "chast.io/core/pkg/util/fs/file"
+)
+
+const slowRecipe = `version: 1
+type: refactoring
+name: Slow
+maintainer: chast
+
+primaryParameter:
+  id: inputFile
+  type: filePath
+  description: The file to be refactored.
+
+run:
+  - id: rewrite
+    script:
+      - sleep 0.5
+      - sed -i s/before/after/ $inputFile
+    includeChangeLocations:
+      - $inputFile
+
+tests:
+  - id: first
+    args:
+      - Input.java
+  - id: second
+    args:
+      - Input.java
+`
+
+// writeRecipe writes the recipe together with the input and expected output of its tests and returns the recipe file.
+func writeRecipe(t *testing.T, testIDs ...string) *util.File {
+	t.Helper()
+
+	recipeFolder := t.TempDir()
+	files := map[string]string{"slow.chast.yml": slowRecipe}
+
+	for _, testID := range testIDs {
+		files[filepath.Join("tests", testID, "input", "Input.java")] = "before"
+		files[filepath.Join("tests", testID, "expected", "Input.java")] = "after"
+	}
+
+	for path, content := range files {
+		fullPath := filepath.Join(recipeFolder, path)
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("Error creating folder: %v", err)
+		}
+
+		if err := os.WriteFile(fullPath, []byte(content), 0o600); err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+
+	// the commands run in the run folder of the recipe
+	if err := os.Mkdir(filepath.Join(recipeFolder, "run"), 0o755); err != nil {
+		t.Fatalf("Error creating run folder: %v", err)
+	}
+
+	recipeFile, recipeFileError := util.NewFile(filepath.Join(recipeFolder, "slow.chast.yml"))
+	if recipeFileError != nil {
+		t.Fatalf("Error loading recipe: %v", recipeFileError)
+	}
+
+	return recipeFile
+}
+
+func TestTest_Parallel(t *testing.T) {
+	t.Parallel()
+
+	recipeFile := writeRecipe(t, "first", "second")
+
+	options := uut.NewOptions()
+	options.Parallel = 2
+	options.RunOptions.Locations = workspace.NewLocations(t.TempDir())
+	options.RunOptions.Locations.MinimumFreeSpace = 0
+	options.RunOptions.Isolated = false
+
+	if err := uut.Test(context.Background(), recipeFile, options); err != nil {
+		t.Fatalf("Expected no error, but was '%v'", err)
+	}
+
+	// each test runs its pipeline in folders of its own
+	for _, testID := range []string{"first", "second"} {
+		testLocation := filepath.Join(options.RunOptions.Locations.ChangeCaptureLocation, "tests", testID)
+
+		if entries, err := os.ReadDir(testLocation); err != nil || len(entries) != 1 {
+			t.Errorf("Expected a single pipeline in %s, but was '%v' (%v)", testLocation, entries, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package refactoring
+
+import (
+	"math"
+	"time"
+
+	chastlog "chast.io/core/internal/logger"
+	refactoringService "chast.io/core/internal/service/pkg/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+// CacheStats prints the number and size of the steps in the cache.
+func CacheStats(workspace WorkspaceOptions) {
+	stats, statsError := refactoringService.CacheStats(workspace.toLocations())
+	if statsError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(statsError))
+	}
+
+	chastlog.Log.Printf("Location: %s", stats.Location)
+	chastlog.Log.Printf("Steps:    %d", stats.Entries)
+	chastlog.Log.Printf("Size:     %.1f MiB", float64(stats.Size)/bytesPerMiB)
+}
+
+// PruneCache removes the least recently used steps until the cache is at most maxSize MiB large, and all steps not
+// used for longer than maxAge if it is positive. A maxSize of 0 only limits the size if maxAge is not set either,
+// then the whole cache is removed.
+func PruneCache(workspace WorkspaceOptions, maxSize uint64, maxAge time.Duration) {
+	maxSizeInBytes := int64(maxSize * bytesPerMiB)
+	if maxSize == 0 && maxAge > 0 {
+		maxSizeInBytes = math.MaxInt64
+	}
+
+	removedEntries, pruneError := refactoringService.PruneCache(workspace.toLocations(), maxSizeInBytes, maxAge)
+	if pruneError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(pruneError))
+	}
+
+	chastlog.Log.Infof("Removed %d cached steps", removedEntries)
+}
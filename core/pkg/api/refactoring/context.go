@@ -0,0 +1,14 @@
+package refactoring
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// interruptibleContext is cancelled by SIGINT and SIGTERM, so the running step is stopped and cleaned up instead of
+// leaving its mounts behind. The signals are handled as usual again once it is stopped.
+func interruptibleContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
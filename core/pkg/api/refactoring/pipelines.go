@@ -0,0 +1,129 @@
+package refactoring
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	chastlog "chast.io/core/internal/logger"
+	refactoringService "chast.io/core/internal/service/pkg/refactoring"
+	"github.com/joomcode/errorx"
+)
+
+const columnPadding = 2
+
+// Resume reruns the failed and remaining steps of the pipeline and applies its changes if confirmed or if
+// assumeYes is set.
+func Resume(pipelineUUID string, assumeYes bool, options RunOptions) {
+	ctx, stop := interruptibleContext()
+	pipeline, resumeError := refactoringService.Resume(ctx, pipelineUUID, options.toRunOptions())
+
+	stop()
+
+	if resumeError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(resumeError))
+	}
+
+	reviewAndApply(pipeline, assumeYes, options.Timings)
+}
+
+// ListPipelines prints the pipelines left in the workspace.
+func ListPipelines(workspace WorkspaceOptions) {
+	pipelines, listError := refactoringService.ListPipelines(workspace.toLocations())
+	if listError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(listError))
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, columnPadding, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "PIPELINE\tSTATUS\tSTEPS\tCREATED\tIN USE")
+
+	for _, pipeline := range pipelines {
+		manifest := pipeline.Manifest
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%d/%d\t%s\t%t\n",
+			manifest.PipelineUUID,
+			manifest.Status,
+			manifest.CompletedSteps(),
+			len(manifest.Steps),
+			manifest.CreatedAt.Format(time.RFC3339),
+			pipeline.InUse,
+		)
+	}
+
+	_ = writer.Flush()
+}
+
+// RemovePipelines removes the pipelines with the uuids, or all pipelines not in use if all is set.
+func RemovePipelines(pipelineUUIDs []string, all bool, workspace WorkspaceOptions) {
+	locations := workspace.toLocations()
+
+	if all {
+		pipelines, listError := refactoringService.ListPipelines(locations)
+		if listError != nil {
+			chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(listError))
+		}
+
+		pipelineUUIDs = make([]string, 0, len(pipelines))
+
+		for _, pipeline := range pipelines {
+			if !pipeline.InUse {
+				pipelineUUIDs = append(pipelineUUIDs, pipeline.Manifest.PipelineUUID)
+			}
+		}
+	}
+
+	failedCount := 0
+
+	for _, pipelineUUID := range pipelineUUIDs {
+		if err := refactoringService.RemovePipeline(pipelineUUID, locations); err != nil {
+			chastlog.Log.Errorf("%v", err)
+
+			failedCount++
+
+			continue
+		}
+
+		chastlog.Log.Infof("Removed pipeline %s", pipelineUUID)
+	}
+
+	if failedCount > 0 {
+		chastlog.Log.Fatalf("Failed to remove %d of %d pipelines", failedCount, len(pipelineUUIDs))
+	}
+}
+
+// Logs prints the logged output of the steps of the pipeline, only of the steps with the uuid or run id if a step is
+// given.
+func Logs(pipelineUUID string, step string, workspace WorkspaceOptions) {
+	steps, logsError := refactoringService.StepLogs(pipelineUUID, step, workspace.toLocations())
+	if logsError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(logsError))
+	}
+
+	for i, stepState := range steps {
+		if i > 0 {
+			_, _ = fmt.Fprintln(os.Stdout)
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "==> %s (%s) <==\n", stepState.RunID, stepState.UUID)
+
+		if err := printFile(stepState.LogFile); err != nil {
+			chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(err))
+		}
+	}
+}
+
+func printFile(location string) error {
+	file, openError := os.Open(location)
+	if openError != nil {
+		return errorx.ExternalError.Wrap(openError, "Failed to open %s", location)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	if _, err := io.Copy(os.Stdout, file); err != nil {
+		return errorx.ExternalError.Wrap(err, "Failed to print %s", location)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package refactoring
+
+import (
+	"os"
+	"path/filepath"
+
+	chastlog "chast.io/core/internal/logger"
+	refactoringplan "chast.io/core/internal/plan/pkg/refactoring"
+	refactoringService "chast.io/core/internal/service/pkg/refactoring"
+	util "chast.io/core/pkg/util/fs/file"
+	"github.com/joomcode/errorx"
+)
+
+const planFilePermission = 0o644
+
+// Plan resolves the recipe for the arguments and writes the plan of the selected runs to the output file, or to stdout
+// if it is empty. Only plans written to an output file can be applied in another checkout, the location of a plan
+// written to stdout is not known.
+func Plan(recipe *util.File, output string, selection RunSelection, args ...string) {
+	plan, planError := refactoringService.Plan(recipe, args, nil, selection.toSelection())
+	if planError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(planError))
+	}
+
+	if output == "" {
+		if err := refactoringplan.Write(plan, os.Stdout, ""); err != nil {
+			chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(err))
+		}
+
+		return
+	}
+
+	outputPath, outputPathError := filepath.Abs(output)
+	if outputPathError != nil {
+		chastlog.Log.Fatalf("Failed to resolve plan file \"%s\": %v", output, outputPathError)
+	}
+
+	outputFile, createError := os.OpenFile(outputPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, planFilePermission)
+	if createError != nil {
+		chastlog.Log.Fatalf("Failed to create plan file \"%s\": %v", output, createError)
+	}
+
+	writeError := refactoringplan.Write(plan, outputFile, filepath.Dir(outputPath))
+	_ = outputFile.Close()
+
+	if writeError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(writeError))
+	}
+}
+
+// Apply runs the plan and applies its changes if confirmed or if assumeYes is set.
+func Apply(planFile *util.File, assumeYes bool, options RunOptions) {
+	inputFile, openError := os.Open(planFile.AbsolutePath)
+	if openError != nil {
+		chastlog.Log.Fatalf("Failed to open plan file \"%s\": %v", planFile.AbsolutePath, openError)
+	}
+
+	plan, readError := refactoringplan.Read(inputFile, filepath.Dir(planFile.AbsolutePath))
+	_ = inputFile.Close()
+
+	if readError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(readError))
+	}
+
+	ctx, stop := interruptibleContext()
+	pipeline, runError := refactoringService.RunPlan(ctx, plan, options.toRunOptions())
+
+	stop()
+
+	if runError != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(runError))
+	}
+
+	reviewAndApply(pipeline, assumeYes, options.Timings)
+}
@@ -7,28 +7,42 @@ import (
 	"strings"
 
 	chastlog "chast.io/core/internal/logger"
+	refactoringpipelinemodel "chast.io/core/internal/pipeline/pkg/model/refactoring"
 	refactoringService "chast.io/core/internal/service/pkg/refactoring"
 	util "chast.io/core/pkg/util/fs/file"
 	"github.com/joomcode/errorx"
 )
 
-func Run(recipe *util.File, args ...string) {
-	pipeline, runError := refactoringService.Run(recipe, args, nil)
+func Run(recipe *util.File, options RunOptions, args ...string) {
+	ctx, stop := interruptibleContext()
+	pipeline, runError := refactoringService.Run(ctx, recipe, args, nil, options.toRunOptions())
+
+	stop()
+
 	if runError != nil {
 		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(runError))
 	}
 
-	if err := refactoringService.ShowReport(pipeline); err != nil {
+	reviewAndApply(pipeline, false, options.Timings)
+}
+
+// reviewAndApply shows the changes of the pipeline and applies them if confirmed or if assumeYes is set.
+func reviewAndApply(pipeline *refactoringpipelinemodel.Pipeline, assumeYes bool, showTimings bool) {
+	if err := refactoringService.ShowReport(pipeline, showTimings); err != nil {
 		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(err))
 	}
 
-	result := StringPrompt("Do you want to apply the refactoring? (y/N)")
+	if !assumeYes {
+		result := StringPrompt("Do you want to apply the refactoring? (y/N)")
 
-	if result == "y" || result == "Y" {
-		if err := refactoringService.ApplyChanges(pipeline); err != nil {
-			chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(err))
+		if result != "y" && result != "Y" {
+			return
 		}
 	}
+
+	if err := refactoringService.ApplyChanges(pipeline); err != nil {
+		chastlog.Log.Fatalf("%+v", errorx.EnsureStackTrace(err))
+	}
 }
 
 func StringPrompt(label string) string {
@@ -0,0 +1,51 @@
+package refactoring
+
+import (
+	"os"
+
+	"chast.io/core/internal/pipeline/pkg/event"
+	refactoringService "chast.io/core/internal/service/pkg/refactoring"
+)
+
+// RunOptions configures how the pipeline of a refactoring runs.
+type RunOptions struct {
+	Workspace WorkspaceOptions
+	Selection RunSelection
+	// NoCache runs every step, even if its changes are cached from a previous run with the same inputs.
+	NoCache bool
+	// EventFileDescriptor is an open file descriptor the events of the pipeline are written to as newline delimited
+	// JSON. Zero disables the event stream.
+	EventFileDescriptor uintptr
+	// Progress renders the progress of the steps on stderr.
+	Progress bool
+	// Timings prints a summary of the time spent in each phase and the resources used by each step.
+	Timings bool
+	// Jobs is the maximum number of independent steps running at the same time, zero runs them one after another.
+	Jobs uint
+	// NoIsolation runs the commands in a scratch copy of the project instead of an isolated environment, for systems
+	// without user namespaces or unionfs-fuse. The commands must not change files outside of the project.
+	NoIsolation bool
+}
+
+func (options RunOptions) toRunOptions() *refactoringService.RunOptions {
+	runOptions := refactoringService.NewRunOptions()
+	runOptions.Locations = options.Workspace.toLocations()
+	runOptions.UseCache = !options.NoCache
+	runOptions.Selection = options.Selection.toSelection()
+	runOptions.Isolated = !options.NoIsolation
+
+	if options.Jobs > 0 {
+		runOptions.Jobs = int(options.Jobs)
+	}
+
+	if options.EventFileDescriptor != 0 {
+		runOptions.Observers = append(runOptions.Observers,
+			event.NewJSONLinesObserver(os.NewFile(options.EventFileDescriptor, "events")))
+	}
+
+	if options.Progress {
+		runOptions.Observers = append(runOptions.Observers, event.NewProgressObserver(os.Stderr))
+	}
+
+	return runOptions
+}
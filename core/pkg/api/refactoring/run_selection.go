@@ -0,0 +1,30 @@
+package refactoring
+
+import runselection "chast.io/core/internal/pipeline/pkg/run_selection"
+
+// RunSelection prunes the runs of a refactoring to debug some of them. Runs are referred to by their id.
+// Dependencies of selected runs are selected automatically and the changes of all steps are kept for inspection.
+type RunSelection struct {
+	// Only selects these runs.
+	Only []string
+	// Until selects this run and all runs before it.
+	Until string
+	// Skip removes these runs.
+	Skip []string
+}
+
+func (selection RunSelection) toSelection() *runselection.Selection {
+	runSelection := runselection.NewSelection()
+
+	if selection.Only != nil {
+		runSelection.Only = selection.Only
+	}
+
+	runSelection.Until = selection.Until
+
+	if selection.Skip != nil {
+		runSelection.Skip = selection.Skip
+	}
+
+	return runSelection
+}
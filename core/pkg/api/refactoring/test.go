@@ -1,10 +1,42 @@
 package refactoring
 
 import (
+	chastlog "chast.io/core/internal/logger"
 	tester "chast.io/core/internal/tester/pkg"
 	util "chast.io/core/pkg/util/fs/file"
+	"github.com/joomcode/errorx"
 )
 
-func Test(recipe *util.File, args ...string) {
-	tester.Test(recipe)
+type TestOptions struct {
+	// UpdateSnapshots rewrites the expected output of each test instead of comparing against it.
+	UpdateSnapshots bool
+	// Parallel is the maximum number of tests running at the same time.
+	Parallel int
+	// Run is a regular expression selecting the tests to run by their id.
+	Run string
+	// FailFast stops starting new tests after the first failed test.
+	FailFast bool
+	// Workspace configures where the pipelines of the tests operate.
+	Workspace WorkspaceOptions
+}
+
+func Test(recipe *util.File, options TestOptions) {
+	testerOptions := tester.NewOptions()
+	testerOptions.UpdateSnapshots = options.UpdateSnapshots
+	testerOptions.Run = options.Run
+	testerOptions.FailFast = options.FailFast
+	testerOptions.RunOptions.Locations = options.Workspace.toLocations()
+
+	if options.Parallel > 0 {
+		testerOptions.Parallel = options.Parallel
+	}
+
+	ctx, stop := interruptibleContext()
+	testError := tester.Test(ctx, recipe, testerOptions)
+
+	stop()
+
+	if testError != nil {
+		chastlog.Log.Fatalf("%v", errorx.EnsureStackTrace(testError))
+	}
 }
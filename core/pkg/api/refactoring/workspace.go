@@ -0,0 +1,44 @@
+package refactoring
+
+import "chast.io/core/internal/pipeline/pkg/workspace"
+
+// WorkspaceOptions configures where pipelines operate and capture their changes.
+// Empty locations default to folders in Workspace.
+type WorkspaceOptions struct {
+	// Workspace is the base folder of the locations, defaults to chast in $XDG_CACHE_HOME or ~/.cache.
+	Workspace              string
+	OperationLocation      string
+	ChangeCaptureLocation  string
+	RootFileSystemLocation string
+	CacheLocation          string
+	// MinimumFreeSpace is the number of MiB required to be free before a pipeline starts, 0 uses the default.
+	MinimumFreeSpace uint64
+}
+
+func (options WorkspaceOptions) toLocations() *workspace.Locations {
+	locations := workspace.NewLocations(options.Workspace)
+
+	if options.OperationLocation != "" {
+		locations.OperationLocation = options.OperationLocation
+	}
+
+	if options.ChangeCaptureLocation != "" {
+		locations.ChangeCaptureLocation = options.ChangeCaptureLocation
+	}
+
+	if options.RootFileSystemLocation != "" {
+		locations.RootFileSystemLocation = options.RootFileSystemLocation
+	}
+
+	if options.CacheLocation != "" {
+		locations.CacheLocation = options.CacheLocation
+	}
+
+	if options.MinimumFreeSpace > 0 {
+		locations.MinimumFreeSpace = options.MinimumFreeSpace * bytesPerMiB
+	}
+
+	return locations
+}
+
+const bytesPerMiB = 1024 * 1024
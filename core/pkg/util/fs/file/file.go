@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/joomcode/errorx"
 )
@@ -14,6 +15,8 @@ type File struct {
 	AbsolutePath    string
 	path            string
 	data            *[]byte
+	// dataLock guards the cached data, the file may be read by concurrent runs.
+	dataLock sync.Mutex
 }
 
 func NewFile(path string) (*File, error) {
@@ -39,6 +42,9 @@ func (file *File) Exists() bool {
 }
 
 func (file *File) Read() *[]byte {
+	file.dataLock.Lock()
+	defer file.dataLock.Unlock()
+
 	if file.data == nil {
 		fileContent, err := os.ReadFile(file.path)
 		if err != nil {